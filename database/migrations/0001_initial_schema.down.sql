@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS transaction_promotions;
+DROP TABLE IF EXISTS promotions;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS refund_details;
+DROP TABLE IF EXISTS refunds;
+DROP TABLE IF EXISTS transaction_details;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS products;
+DROP TABLE IF EXISTS product_categories;
@@ -0,0 +1,166 @@
+-- Skema awal. Semua statement idempotent supaya aman dijalankan di database
+-- yang sebelumnya dibuat manual dari komentar skema lama.
+
+CREATE TABLE IF NOT EXISTS product_categories (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(100) NOT NULL,
+	tax_rate NUMERIC(5,2)
+);
+
+CREATE TABLE IF NOT EXISTS products (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(200) NOT NULL,
+	price INT NOT NULL,
+	stock INT NOT NULL DEFAULT 0,
+	category_id INT REFERENCES product_categories(id) ON DELETE SET NULL,
+	tax_rate NUMERIC(5,2),
+	tax_exempt BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+	id SERIAL PRIMARY KEY,
+	subtotal_amount INT NOT NULL DEFAULT 0,
+	discount_amount INT NOT NULL DEFAULT 0,
+	price_includes_tax BOOLEAN NOT NULL DEFAULT FALSE,
+	tax_base INT NOT NULL DEFAULT 0,
+	tax_amount INT NOT NULL DEFAULT 0,
+	service_charge INT NOT NULL DEFAULT 0,
+	total_amount INT NOT NULL,
+	paid_amount INT NOT NULL DEFAULT 0,
+	change_amount INT NOT NULL DEFAULT 0,
+	status VARCHAR(20) NOT NULL DEFAULT 'completed',
+	void_reason TEXT,
+	voided_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS transaction_details (
+	id SERIAL PRIMARY KEY,
+	transaction_id INT REFERENCES transactions(id) ON DELETE CASCADE,
+	product_id INT REFERENCES products(id),
+	product_name VARCHAR(200) NOT NULL,
+	product_price INT NOT NULL,
+	quantity INT NOT NULL,
+	subtotal INT NOT NULL,
+	discount_amount INT NOT NULL DEFAULT 0,
+	tax_rate NUMERIC(5,2) NOT NULL DEFAULT 0,
+	tax_base INT NOT NULL DEFAULT 0,
+	tax_amount INT NOT NULL DEFAULT 0,
+	service_charge INT NOT NULL DEFAULT 0,
+	total_amount INT NOT NULL DEFAULT 0
+);
+
+-- database lama: kolom yang ditambahkan setelah tabel pertama kali dibuat
+ALTER TABLE product_categories ADD COLUMN IF NOT EXISTS tax_rate NUMERIC(5,2);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_rate NUMERIC(5,2);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_exempt BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS subtotal_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS discount_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS price_includes_tax BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_base INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS service_charge INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS paid_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS change_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'completed';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS void_reason TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS voided_at TIMESTAMP;
+
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS product_name VARCHAR(200);
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS product_price INT;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS discount_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_rate NUMERIC(5,2) NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_base INT NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS service_charge INT NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS total_amount INT NOT NULL DEFAULT 0;
+
+-- isi snapshot dan total untuk transaksi lama
+UPDATE transaction_details td SET product_name = p.name, product_price = p.price
+	FROM products p
+	WHERE p.id = td.product_id AND (td.product_name IS NULL OR td.product_price IS NULL);
+ALTER TABLE transaction_details ALTER COLUMN product_name SET NOT NULL;
+ALTER TABLE transaction_details ALTER COLUMN product_price SET NOT NULL;
+
+UPDATE transaction_details
+	SET tax_base = subtotal - discount_amount, total_amount = subtotal - discount_amount
+	WHERE total_amount = 0 AND subtotal > 0;
+
+UPDATE transactions
+	SET subtotal_amount = total_amount, tax_base = total_amount, paid_amount = total_amount
+	WHERE subtotal_amount = 0 AND total_amount > 0;
+
+CREATE TABLE IF NOT EXISTS refunds (
+	id SERIAL PRIMARY KEY,
+	transaction_id INT NOT NULL REFERENCES transactions(id),
+	reason TEXT NOT NULL,
+	total_amount INT NOT NULL,
+	tax_amount INT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS refund_details (
+	id SERIAL PRIMARY KEY,
+	refund_id INT NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+	transaction_detail_id INT NOT NULL REFERENCES transaction_details(id),
+	product_id INT REFERENCES products(id),
+	quantity INT NOT NULL,
+	amount INT NOT NULL,
+	tax_amount INT NOT NULL DEFAULT 0
+);
+
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE refund_details ADD COLUMN IF NOT EXISTS tax_amount INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS payments (
+	id SERIAL PRIMARY KEY,
+	transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+	method VARCHAR(20) NOT NULL,
+	amount INT NOT NULL,
+	tendered_amount INT NOT NULL,
+	reference VARCHAR(100)
+);
+
+CREATE TABLE IF NOT EXISTS promotions (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(200) NOT NULL,
+	type VARCHAR(30) NOT NULL,
+	value INT NOT NULL DEFAULT 0,
+	min_spend INT NOT NULL DEFAULT 0,
+	max_discount INT NOT NULL DEFAULT 0,
+	product_ids INT[] NOT NULL DEFAULT '{}',
+	category_id INT REFERENCES product_categories(id) ON DELETE SET NULL,
+	buy_quantity INT NOT NULL DEFAULT 0,
+	get_quantity INT NOT NULL DEFAULT 0,
+	bundle_items JSONB NOT NULL DEFAULT '[]',
+	bundle_price INT NOT NULL DEFAULT 0,
+	priority INT NOT NULL DEFAULT 0,
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	starts_at TIMESTAMP,
+	ends_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS transaction_promotions (
+	id SERIAL PRIMARY KEY,
+	transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+	promotion_id INT REFERENCES promotions(id) ON DELETE SET NULL,
+	promotion_name VARCHAR(200) NOT NULL,
+	product_id INT REFERENCES products(id),
+	discount_amount INT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	key VARCHAR(255) PRIMARY KEY,
+	request_hash CHAR(64) NOT NULL,
+	transaction_id INT REFERENCES transactions(id),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions (created_at);
+CREATE INDEX IF NOT EXISTS idx_transaction_details_transaction_id ON transaction_details (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_transaction_id ON refunds (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_refund_details_transaction_detail_id ON refund_details (transaction_detail_id);
+CREATE INDEX IF NOT EXISTS idx_payments_transaction_id ON payments (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_promotions_transaction_id ON transaction_promotions (transaction_id);
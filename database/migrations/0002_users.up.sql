@@ -0,0 +1,27 @@
+-- Akun kasir / supervisor / owner dan sesi login (token disimpan dalam bentuk hash)
+
+CREATE TABLE users (
+	id SERIAL PRIMARY KEY,
+	username VARCHAR(50) NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	role VARCHAR(20) NOT NULL,
+	password_hash TEXT NOT NULL DEFAULT '',
+	pin_hash TEXT NOT NULL DEFAULT '',
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX users_username_key ON users (LOWER(username));
+
+CREATE TABLE user_sessions (
+	id SERIAL PRIMARY KEY,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	token_hash CHAR(64) NOT NULL UNIQUE,
+	refresh_token_hash CHAR(64) NOT NULL UNIQUE,
+	expires_at TIMESTAMP NOT NULL,
+	refresh_expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	revoked_at TIMESTAMP
+);
+
+CREATE INDEX user_sessions_user_id_idx ON user_sessions (user_id);
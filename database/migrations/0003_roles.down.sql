@@ -0,0 +1,3 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+ALTER TABLE users DROP COLUMN IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,27 @@
+-- Role dan izin (RBAC). Role bawaan sama dengan models.DefaultRoles.
+
+CREATE TABLE roles (
+	name VARCHAR(20) PRIMARY KEY,
+	description VARCHAR(200) NOT NULL DEFAULT '',
+	permissions TEXT[] NOT NULL DEFAULT '{}',
+	built_in BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO roles (name, description, permissions, built_in) VALUES
+	('cashier', 'Kasir', ARRAY[
+		'products:read', 'categories:read', 'promotions:read', 'checkout', 'transactions:read'
+	], TRUE),
+	('supervisor', 'Supervisor toko', ARRAY[
+		'products:read', 'categories:read', 'promotions:read', 'checkout', 'transactions:read',
+		'products:write', 'categories:write', 'promotions:write', 'transactions:void', 'transactions:refund',
+		'reports:read'
+	], TRUE),
+	('owner', 'Pemilik, semua akses', ARRAY[
+		'products:read', 'products:write', 'products:delete', 'categories:read', 'categories:write',
+		'checkout', 'transactions:read', 'transactions:void', 'transactions:refund',
+		'promotions:read', 'promotions:write', 'reports:read', 'users:manage', 'roles:manage'
+	], TRUE);
+
+ALTER TABLE users ADD COLUMN permissions TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE users ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles(name);
@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS terminal_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS cashier_id;
+DROP TABLE IF EXISTS terminals;
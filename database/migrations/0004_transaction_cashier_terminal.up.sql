@@ -0,0 +1,16 @@
+-- Terminal (mesin kasir) dan kasir pada setiap transaksi.
+-- Transaksi lama tetap NULL karena dibuat sebelum ada login.
+
+CREATE TABLE terminals (
+	id SERIAL PRIMARY KEY,
+	code VARCHAR(30) NOT NULL UNIQUE,
+	name VARCHAR(100) NOT NULL,
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE transactions ADD COLUMN cashier_id INT REFERENCES users(id);
+ALTER TABLE transactions ADD COLUMN terminal_id INT REFERENCES terminals(id);
+
+CREATE INDEX idx_transactions_cashier_id ON transactions (cashier_id);
+CREATE INDEX idx_transactions_terminal_id ON transactions (terminal_id);
@@ -0,0 +1,7 @@
+UPDATE roles SET permissions = array_remove(permissions, 'shifts:operate');
+
+ALTER TABLE refunds DROP COLUMN IF EXISTS shift_id;
+ALTER TABLE refunds DROP COLUMN IF EXISTS cash_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS shift_id;
+DROP TABLE IF EXISTS cash_movements;
+DROP TABLE IF EXISTS shifts;
@@ -0,0 +1,42 @@
+-- Shift kasir dan laci kas. Hanya boleh ada satu shift terbuka per terminal,
+-- checkout dan refund tunai dicatat ke shift yang sedang terbuka.
+
+CREATE TABLE shifts (
+	id SERIAL PRIMARY KEY,
+	terminal_id INT NOT NULL REFERENCES terminals(id),
+	cashier_id INT NOT NULL REFERENCES users(id),
+	status VARCHAR(10) NOT NULL DEFAULT 'open',
+	opening_float INT NOT NULL,
+	expected_cash INT, -- diisi saat shift ditutup
+	counted_cash INT,
+	closing_note TEXT NOT NULL DEFAULT '',
+	closed_by INT REFERENCES users(id),
+	opened_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	closed_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_shifts_open_terminal ON shifts (terminal_id) WHERE status = 'open';
+CREATE INDEX idx_shifts_cashier_id ON shifts (cashier_id);
+
+CREATE TABLE cash_movements (
+	id SERIAL PRIMARY KEY,
+	shift_id INT NOT NULL REFERENCES shifts(id),
+	type VARCHAR(10) NOT NULL,
+	amount INT NOT NULL,
+	reason TEXT NOT NULL,
+	created_by INT NOT NULL REFERENCES users(id),
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cash_movements_shift_id ON cash_movements (shift_id);
+
+ALTER TABLE transactions ADD COLUMN shift_id INT REFERENCES shifts(id);
+CREATE INDEX idx_transactions_shift_id ON transactions (shift_id);
+
+-- refund lama dianggap non-tunai karena tidak tercatat di laci mana pun
+ALTER TABLE refunds ADD COLUMN cash_amount INT NOT NULL DEFAULT 0;
+ALTER TABLE refunds ADD COLUMN shift_id INT REFERENCES shifts(id);
+CREATE INDEX idx_refunds_shift_id ON refunds (shift_id);
+
+UPDATE roles SET permissions = array_append(permissions, 'shifts:operate')
+	WHERE name IN ('cashier', 'supervisor') AND NOT ('shifts:operate' = ANY(permissions));
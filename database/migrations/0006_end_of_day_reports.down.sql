@@ -0,0 +1,5 @@
+UPDATE roles SET permissions = array_remove(permissions, 'reports:close');
+
+DROP TRIGGER IF EXISTS trg_end_of_day_reports_immutable ON end_of_day_reports;
+DROP FUNCTION IF EXISTS end_of_day_reports_immutable();
+DROP TABLE IF EXISTS end_of_day_reports;
@@ -0,0 +1,27 @@
+CREATE TABLE end_of_day_reports (
+	id SERIAL PRIMARY KEY,
+	type VARCHAR(1) NOT NULL CHECK (type IN ('X', 'Z')),
+	business_date DATE NOT NULL,
+	sequence INT NOT NULL,
+	data JSONB NOT NULL,
+	created_by INT NOT NULL REFERENCES users(id),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (business_date, type, sequence)
+);
+
+-- Satu Z report per hari, setelah itu hari dianggap tutup
+CREATE UNIQUE INDEX idx_end_of_day_reports_z ON end_of_day_reports (business_date) WHERE type = 'Z';
+
+-- Laporan X/Z tidak boleh diubah atau dihapus
+CREATE FUNCTION end_of_day_reports_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'end_of_day_reports tidak boleh diubah';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_end_of_day_reports_immutable
+	BEFORE UPDATE OR DELETE ON end_of_day_reports
+	FOR EACH ROW EXECUTE FUNCTION end_of_day_reports_immutable();
+
+UPDATE roles SET permissions = array_append(permissions, 'reports:close')
+	WHERE name = 'supervisor' AND NOT ('reports:close' = ANY(permissions));
@@ -0,0 +1,2 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS receipt_number;
+DROP TABLE IF EXISTS receipt_sequences;
@@ -0,0 +1,11 @@
+-- Nomor terakhir yang dipakai per outlet per tanggal bisnis. Baris di-lock oleh checkout
+-- sampai commit, sehingga nomor yang rollback tidak meninggalkan lubang.
+CREATE TABLE receipt_sequences (
+	outlet_code VARCHAR(50) NOT NULL,
+	business_date DATE NOT NULL,
+	last_number INT NOT NULL,
+	PRIMARY KEY (outlet_code, business_date)
+);
+
+-- NULL untuk transaksi sebelum ada nomor struk
+ALTER TABLE transactions ADD COLUMN receipt_number VARCHAR(100) UNIQUE;
@@ -0,0 +1,2 @@
+ALTER TABLE terminals DROP COLUMN IF EXISTS printer_width;
+ALTER TABLE terminals DROP COLUMN IF EXISTS printer_address;
@@ -0,0 +1,3 @@
+-- Printer thermal ESC/POS jaringan per terminal, kosong = tanpa cetak otomatis
+ALTER TABLE terminals ADD COLUMN printer_address VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE terminals ADD COLUMN printer_width INT NOT NULL DEFAULT 80 CHECK (printer_width IN (58, 80));
@@ -0,0 +1,11 @@
+-- SKU opsional tapi unik, NULL untuk produk lama yang belum diberi SKU
+ALTER TABLE products ADD COLUMN sku VARCHAR(50) UNIQUE;
+
+-- satu produk bisa punya beberapa barcode (kemasan berbeda, barcode pabrik + barcode toko).
+-- Primary key barcode sekaligus index untuk scan di kasir.
+CREATE TABLE product_barcodes (
+	barcode VARCHAR(50) PRIMARY KEY,
+	product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_barcodes_product_id ON product_barcodes (product_id);
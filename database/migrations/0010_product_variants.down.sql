@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS products_variant_options_key;
+DROP INDEX IF EXISTS idx_products_parent_id;
+ALTER TABLE products DROP COLUMN IF EXISTS option_values;
+ALTER TABLE products DROP COLUMN IF EXISTS options;
+ALTER TABLE products DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,10 @@
+-- Varian produk: induk menyimpan dimensi (options), varian menunjuk induk lewat parent_id.
+-- Induk tidak bisa dihapus selama masih punya varian.
+ALTER TABLE products ADD COLUMN parent_id INT REFERENCES products(id);
+ALTER TABLE products ADD COLUMN options JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE products ADD COLUMN option_values TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_products_parent_id ON products (parent_id);
+
+-- kombinasi nilai option tidak boleh dobel dalam satu induk
+CREATE UNIQUE INDEX products_variant_options_key ON products (parent_id, option_values) WHERE parent_id IS NOT NULL;
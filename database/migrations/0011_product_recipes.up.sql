@@ -0,0 +1,23 @@
+-- Resep (bill of materials): menjual satu produk komposit mengurangi stok komponennya,
+-- bukan stok produk itu sendiri. quantity dalam satuan stok komponen (gram, ml, pcs).
+CREATE TABLE product_components (
+	product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+	component_id INT NOT NULL REFERENCES products(id),
+	quantity INT NOT NULL CHECK (quantity > 0),
+	PRIMARY KEY (product_id, component_id),
+	CHECK (product_id <> component_id)
+);
+
+CREATE INDEX idx_product_components_component_id ON product_components (component_id);
+
+-- snapshot resep per baris transaksi (per 1 item terjual) supaya void / refund mengembalikan
+-- komponen yang benar-benar terpakai walaupun resepnya sudah diubah
+CREATE TABLE transaction_detail_components (
+	transaction_detail_id INT NOT NULL REFERENCES transaction_details(id) ON DELETE CASCADE,
+	component_id INT NOT NULL REFERENCES products(id),
+	component_name VARCHAR(200) NOT NULL,
+	quantity INT NOT NULL,
+	PRIMARY KEY (transaction_detail_id, component_id)
+);
+
+CREATE INDEX idx_transaction_detail_components_component_id ON transaction_detail_components (component_id);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_stock_movements_immutable ON stock_movements;
+DROP FUNCTION IF EXISTS stock_movements_immutable();
+DROP TABLE IF EXISTS stock_movements;
@@ -0,0 +1,36 @@
+-- Ledger stok: setiap perubahan products.stock dicatat di sini, baris tidak pernah diubah.
+-- SUM(quantity) per produk harus sama dengan products.stock.
+CREATE TABLE stock_movements (
+	id SERIAL PRIMARY KEY,
+	product_id INT NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+	type VARCHAR(20) NOT NULL,
+	quantity INT NOT NULL,
+	balance INT NOT NULL,
+	reference_type VARCHAR(20) NOT NULL DEFAULT '',
+	reference_id INT,
+	reference VARCHAR(100) NOT NULL DEFAULT '',
+	note TEXT NOT NULL DEFAULT '',
+	created_by INT REFERENCES users(id) ON DELETE RESTRICT,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_stock_movements_product_id ON stock_movements (product_id, id);
+
+-- append-only: baris tidak bisa diubah atau dihapus. Karena itu FK memakai ON DELETE RESTRICT,
+-- bukan CASCADE/SET NULL yang akan mengubah baris: produk dan user yang punya riwayat stok
+-- tidak bisa dihapus permanen, keduanya dinonaktifkan (produk lewat products.active, 0014)
+CREATE FUNCTION stock_movements_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'stock_movements tidak boleh diubah';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_stock_movements_immutable
+	BEFORE UPDATE OR DELETE ON stock_movements
+	FOR EACH ROW EXECUTE FUNCTION stock_movements_immutable();
+
+-- saldo awal untuk stok yang sudah ada sebelum ledger dipakai
+INSERT INTO stock_movements (product_id, type, quantity, balance, note)
+SELECT id, 'initial', stock, stock, 'saldo awal saat ledger stok mulai dipakai'
+FROM products
+WHERE stock <> 0;
@@ -0,0 +1,10 @@
+-- Snapshot kategori produk saat transaksi, supaya total per kategori di X/Z report
+-- tidak berubah ketika produk dipindah kategori atau kategorinya di-rename/dihapus.
+ALTER TABLE transaction_details ADD COLUMN category_id INT;
+ALTER TABLE transaction_details ADD COLUMN category_name VARCHAR(100) NOT NULL DEFAULT '';
+
+-- transaksi lama: pakai kategori produk saat migrasi, data terbaik yang tersedia
+UPDATE transaction_details td SET category_id = c.id, category_name = c.name
+	FROM products p
+	JOIN product_categories c ON c.id = p.category_id
+	WHERE p.id = td.product_id;
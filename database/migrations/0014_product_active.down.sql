@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS products_variant_options_key;
+CREATE UNIQUE INDEX products_variant_options_key ON products (parent_id, option_values) WHERE parent_id IS NOT NULL;
+ALTER TABLE products DROP COLUMN IF EXISTS active;
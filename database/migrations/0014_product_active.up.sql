@@ -0,0 +1,7 @@
+-- Soft delete produk: produk yang punya riwayat stok atau transaksi tidak bisa dihapus permanen
+-- (stock_movements ON DELETE RESTRICT), jadi DELETE hanya menonaktifkan produk.
+ALTER TABLE products ADD COLUMN active BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- varian yang sudah dihapus tidak menghalangi varian baru dengan kombinasi option yang sama
+DROP INDEX products_variant_options_key;
+CREATE UNIQUE INDEX products_variant_options_key ON products (parent_id, option_values) WHERE parent_id IS NOT NULL AND active;
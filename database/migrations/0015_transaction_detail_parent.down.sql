@@ -0,0 +1,2 @@
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS parent_name;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS parent_id;
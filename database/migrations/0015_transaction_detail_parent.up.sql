@@ -0,0 +1,10 @@
+-- Snapshot induk varian saat transaksi, supaya laporan per produk dan produk terlaris tidak
+-- berubah ketika produk atau induknya di-rename.
+ALTER TABLE transaction_details ADD COLUMN parent_id INT;
+ALTER TABLE transaction_details ADD COLUMN parent_name VARCHAR(200) NOT NULL DEFAULT '';
+
+-- transaksi lama: pakai induk produk saat migrasi, data terbaik yang tersedia
+UPDATE transaction_details td SET parent_id = pp.id, parent_name = pp.name
+	FROM products p
+	JOIN products pp ON pp.id = p.parent_id
+	WHERE p.id = td.product_id;
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/services"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type authContextKey struct{}
+
+// authInfo - user dan sesi yang login, disimpan di context request oleh RequireAuth
+type authInfo struct {
+	user    *models.User
+	session *models.Session
+}
+
+type AuthHandler struct {
+	service *services.AuthService
+}
+
+func NewAuthHandler(service *services.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// CurrentUser - user yang login, nil jika handler tidak dibungkus RequireAuth
+func CurrentUser(r *http.Request) *models.User {
+	info, ok := r.Context().Value(authContextKey{}).(*authInfo)
+	if !ok {
+		return nil
+	}
+	return info.user
+}
+
+func currentSession(r *http.Request) *models.Session {
+	info, ok := r.Context().Value(authContextKey{}).(*authInfo)
+	if !ok {
+		return nil
+	}
+	return info.session
+}
+
+// RequireAuth - tolak request tanpa header "Authorization: Bearer <access_token>" yang valid
+func (h *AuthHandler) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			writeError(w, r, http.StatusUnauthorized, ErrorCodeUnauthorized, "Missing bearer token", nil)
+			return
+		}
+
+		user, session, err := h.service.Authenticate(strings.TrimSpace(token))
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), authContextKey{}, &authInfo{user: user, session: session})
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// PermissionRule - izin yang dibutuhkan sebuah request. String kosong berarti cukup login,
+// misalnya method yang tidak didukung supaya handler tetap membalas 405.
+type PermissionRule func(r *http.Request) string
+
+// Allow - satu izin untuk semua method
+func Allow(permission string) PermissionRule {
+	return func(r *http.Request) string { return permission }
+}
+
+// ByMethod - izin berbeda per HTTP method
+func ByMethod(permissions map[string]string) PermissionRule {
+	return func(r *http.Request) string { return permissions[r.Method] }
+}
+
+// RequirePermission - seperti RequireAuth, lalu tolak dengan 403 jika user tidak punya izin dari rule
+func (h *AuthHandler) RequirePermission(next http.HandlerFunc, rule PermissionRule) http.HandlerFunc {
+	return h.RequireAuth(func(w http.ResponseWriter, r *http.Request) {
+		if permission := rule(r); permission != "" && !CurrentUser(r).Can(permission) {
+			writeForbidden(w, r, permission)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// HandleLogin - POST /api/auth/login
+func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	var req models.LoginRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	tokens, err := h.service.Login(req, clientIP(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// HandleRefresh - POST /api/auth/refresh
+func (h *AuthHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	var req models.RefreshRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	tokens, err := h.service.Refresh(req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// HandleLogout - POST /api/auth/logout, harus dibungkus RequireAuth
+func (h *AuthHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	err := h.service.Logout(currentSession(r))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleMe - GET /api/auth/me, harus dibungkus RequireAuth
+func (h *AuthHandler) HandleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CurrentUser(r))
+}
+
+// clientIP - alamat IP dari koneksi. X-Forwarded-For sengaja tidak dipakai karena bisa dipalsukan client.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"aplikasi-kasir/services"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequireAuth(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	userRepo := repositories.NewMemoryUserRepository(store)
+	sessionRepo := repositories.NewMemorySessionRepository(store)
+	userService := services.NewUserService(userRepo, sessionRepo)
+	for _, input := range []models.UserInput{
+		{Username: "kasir1", Name: "Kasir", Role: models.RoleCashier, PIN: "1234"},
+		{Username: "owner", Name: "Pemilik", Role: models.RoleOwner, Password: "rahasia123"},
+	} {
+		if _, err := userService.Create(input); err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+
+	roleRepo := repositories.NewMemoryRoleRepository(store)
+	handler := NewAuthHandler(services.NewAuthService(userRepo, sessionRepo, roleRepo, models.AuthConfig{
+		AccessTokenTTL:   time.Hour,
+		RefreshTokenTTL:  time.Hour,
+		MaxLoginAttempts: 5,
+		LoginWindow:      time.Minute,
+	}))
+
+	login := func(body string) string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.HandleLogin(rec, httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("login status = %d, body %s", rec.Code, rec.Body)
+		}
+		var tokens models.AuthTokens
+		if err := json.NewDecoder(rec.Body).Decode(&tokens); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return tokens.AccessToken
+	}
+	cashierToken := login(`{"username":"kasir1","pin":"1234"}`)
+	ownerToken := login(`{"username":"owner","password":"rahasia123"}`)
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(CurrentUser(r).Username))
+	}
+	products := handler.RequirePermission(ok, ByMethod(map[string]string{
+		http.MethodGet:    models.PermissionProductRead,
+		http.MethodDelete: models.PermissionProductDelete,
+	}))
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		next          http.HandlerFunc
+		status        int
+		code          string // kode error, kosong jika sukses
+	}{
+		{name: "tanpa token", method: http.MethodGet, next: handler.RequireAuth(ok), status: http.StatusUnauthorized, code: ErrorCodeUnauthorized},
+		{name: "token salah", method: http.MethodGet, authorization: "Bearer abc", next: handler.RequireAuth(ok), status: http.StatusUnauthorized, code: ErrorCodeUnauthorized},
+		{name: "kasir lihat produk", method: http.MethodGet, authorization: "Bearer " + cashierToken, next: products, status: http.StatusOK},
+		{name: "kasir hapus produk", method: http.MethodDelete, authorization: "Bearer " + cashierToken, next: products, status: http.StatusForbidden, code: ErrorCodeForbidden},
+		{name: "owner hapus produk", method: http.MethodDelete, authorization: "Bearer " + ownerToken, next: products, status: http.StatusOK},
+		{name: "method tanpa izin cukup login", method: http.MethodPatch, authorization: "Bearer " + cashierToken, next: products, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/products/1", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			tt.next(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.status, rec.Body)
+			}
+			if tt.code == "" {
+				return
+			}
+
+			var body ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body.Code != tt.code {
+				t.Errorf("code = %q, want %q", body.Code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"aplikasi-kasir/middleware"
+	"aplikasi-kasir/models"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// Kode error yang dikirim di field "code". Frontend bercabang berdasarkan kode ini,
+// bukan berdasarkan message (message bisa berubah dan berbahasa Indonesia).
+const (
+	ErrorCodeInvalidRequest     = "invalid_request"     // 400 body / parameter tidak bisa dibaca
+	ErrorCodeValidation         = "validation_failed"   // 422 details: []FieldError
+	ErrorCodeNotFound           = "not_found"           // 404
+	ErrorCodeConflict           = "conflict"            // 409
+	ErrorCodeInsufficientStock  = "insufficient_stock"  // 409 details: []StockShortage
+	ErrorCodeMethodNotAllowed   = "method_not_allowed"  // 405
+	ErrorCodeUnauthorized       = "unauthorized"        // 401 token tidak ada / tidak valid / login gagal
+	ErrorCodeForbidden          = "forbidden"           // 403 details: {"permission": izin yang dibutuhkan}
+	ErrorCodeTooManyRequests    = "too_many_requests"   // 429 header Retry-After dalam detik
+	ErrorCodePrinterUnavailable = "printer_unavailable" // 502 printer jaringan terminal tidak bisa dihubungi
+	ErrorCodeInternal           = "internal_error"      // 500
+)
+
+// ErrorResponse - format body untuk semua response error
+type ErrorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details"`
+	RequestID string `json:"request_id"`
+}
+
+// writeError - tulis ErrorResponse dengan status HTTP yang diberikan
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string, details any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: middleware.GetRequestID(r.Context()),
+	})
+}
+
+func writeBadRequest(w http.ResponseWriter, r *http.Request, message string) {
+	writeError(w, r, http.StatusBadRequest, ErrorCodeInvalidRequest, message, nil)
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed, "Method not allowed", nil)
+}
+
+func writeNotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, ErrorCodeNotFound, "Not found", nil)
+}
+
+// writeForbidden - details berisi izin yang dibutuhkan, supaya frontend bisa menampilkan pesan yang jelas
+func writeForbidden(w http.ResponseWriter, r *http.Request, permission string) {
+	writeError(w, r, http.StatusForbidden, ErrorCodeForbidden, "Forbidden", map[string]string{"permission": permission})
+}
+
+// writeServiceError - petakan error dari service/repository ke status HTTP dan kode error.
+// Error yang tidak dikenal dianggap 500; isinya hanya ditulis ke log, tidak ke client.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErr *models.ValidationError
+	var stockErr *models.InsufficientStockError
+	var rateErr *models.TooManyRequestsError
+
+	switch {
+	case errors.As(err, &validationErr):
+		writeError(w, r, http.StatusUnprocessableEntity, ErrorCodeValidation, "validasi gagal", validationErr.Errors)
+	case errors.As(err, &stockErr):
+		writeError(w, r, http.StatusConflict, ErrorCodeInsufficientStock, "stok tidak mencukupi", stockErr.Items)
+	case errors.As(err, &rateErr):
+		w.Header().Set("Retry-After", strconv.Itoa(int(rateErr.RetryAfter.Seconds())+1))
+		writeError(w, r, http.StatusTooManyRequests, ErrorCodeTooManyRequests, rateErr.Message, nil)
+	case errors.Is(err, models.ErrUnauthorized):
+		writeError(w, r, http.StatusUnauthorized, ErrorCodeUnauthorized, err.Error(), nil)
+	case errors.Is(err, models.ErrValidation):
+		writeError(w, r, http.StatusUnprocessableEntity, ErrorCodeValidation, err.Error(), nil)
+	case errors.Is(err, models.ErrInsufficientStock):
+		writeError(w, r, http.StatusConflict, ErrorCodeInsufficientStock, err.Error(), nil)
+	case errors.Is(err, models.ErrNotFound):
+		writeError(w, r, http.StatusNotFound, ErrorCodeNotFound, err.Error(), nil)
+	case errors.Is(err, models.ErrConflict):
+		writeError(w, r, http.StatusConflict, ErrorCodeConflict, err.Error(), nil)
+	case errors.Is(err, models.ErrPrinterUnavailable):
+		log.Printf("request %s %s %s: %v", middleware.GetRequestID(r.Context()), r.Method, r.URL.Path, err)
+		writeError(w, r, http.StatusBadGateway, ErrorCodePrinterUnavailable, models.ErrPrinterUnavailable.Error(), nil)
+	default:
+		log.Printf("request %s %s %s: %v", middleware.GetRequestID(r.Context()), r.Method, r.URL.Path, err)
+		writeError(w, r, http.StatusInternalServerError, ErrorCodeInternal, "terjadi kesalahan pada server", nil)
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"aplikasi-kasir/middleware"
+	"aplikasi-kasir/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteServiceError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		code       string
+		message    string
+		hasDetails bool
+	}{
+		{
+			name:       "validasi",
+			err:        models.NewValidationError("name", "wajib diisi"),
+			status:     http.StatusUnprocessableEntity,
+			code:       ErrorCodeValidation,
+			message:    "validasi gagal",
+			hasDetails: true,
+		},
+		{
+			name:       "stok kurang",
+			err:        &models.InsufficientStockError{Items: []models.StockShortage{{ProductID: 1}}},
+			status:     http.StatusConflict,
+			code:       ErrorCodeInsufficientStock,
+			message:    "stok tidak mencukupi",
+			hasDetails: true,
+		},
+		{
+			name:    "not found bertipe",
+			err:     &models.NotFoundError{Message: "produk tidak ditemukan"},
+			status:  http.StatusNotFound,
+			code:    ErrorCodeNotFound,
+			message: "produk tidak ditemukan",
+		},
+		{
+			name:    "sentinel yang dibungkus",
+			err:     fmt.Errorf("promo 3: %w", models.ErrNotFound),
+			status:  http.StatusNotFound,
+			code:    ErrorCodeNotFound,
+			message: "promo 3: data tidak ditemukan",
+		},
+		{
+			name:    "konflik",
+			err:     &models.ConflictError{Message: "transaksi sudah di-void"},
+			status:  http.StatusConflict,
+			code:    ErrorCodeConflict,
+			message: "transaksi sudah di-void",
+		},
+		{
+			name:    "belum login",
+			err:     &models.UnauthorizedError{Message: "token kedaluwarsa"},
+			status:  http.StatusUnauthorized,
+			code:    ErrorCodeUnauthorized,
+			message: "token kedaluwarsa",
+		},
+		{
+			name:    "login diblokir",
+			err:     &models.TooManyRequestsError{Message: "coba lagi nanti", RetryAfter: 90 * time.Second},
+			status:  http.StatusTooManyRequests,
+			code:    ErrorCodeTooManyRequests,
+			message: "coba lagi nanti",
+		},
+		{
+			name:    "printer mati",
+			err:     &models.PrinterError{Address: "192.168.1.50:9100", Err: errors.New("connection refused")},
+			status:  http.StatusBadGateway,
+			code:    ErrorCodePrinterUnavailable,
+			message: "printer tidak bisa dihubungi",
+		},
+		{
+			name:    "error lain tidak dibocorkan",
+			err:     errors.New("pq: connection refused"),
+			status:  http.StatusInternalServerError,
+			code:    ErrorCodeInternal,
+			message: "terjadi kesalahan pada server",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/products/1", nil)
+			req.Header.Set(middleware.RequestIDHeader, "req-123")
+
+			middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeServiceError(w, r, tt.err)
+			})).ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+
+			var body ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body.Code != tt.code || body.Message != tt.message {
+				t.Errorf("body = %+v, want code %q message %q", body, tt.code, tt.message)
+			}
+			if (body.Details != nil) != tt.hasDetails {
+				t.Errorf("details = %v, want present = %v", body.Details, tt.hasDetails)
+			}
+			if body.RequestID != "req-123" {
+				t.Errorf("request_id = %q, want req-123", body.RequestID)
+			}
+		})
+	}
+}
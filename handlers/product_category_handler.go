@@ -24,14 +24,14 @@ func (h *ProductCategoryHandler) HandleProductCategories(w http.ResponseWriter,
 	case http.MethodPost:
 		h.Create(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, r)
 	}
 }
 
 func (h *ProductCategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	products, err := h.service.GetAll()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -43,13 +43,13 @@ func (h *ProductCategoryHandler) Create(w http.ResponseWriter, r *http.Request)
 	var productCategory models.ProductCategory
 	err := json.NewDecoder(r.Body).Decode(&productCategory)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
 	err = h.service.Create(&productCategory)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -67,7 +67,7 @@ func (h *ProductCategoryHandler) HandleProductCategoryByID(w http.ResponseWriter
 	case http.MethodDelete:
 		h.Delete(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, r)
 	}
 }
 
@@ -75,13 +75,13 @@ func (h *ProductCategoryHandler) GetByID(w http.ResponseWriter, r *http.Request)
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/product-categories/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid category ID")
 		return
 	}
 
 	product, err := h.service.GetByID(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -93,21 +93,21 @@ func (h *ProductCategoryHandler) Update(w http.ResponseWriter, r *http.Request)
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/product-categories/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid category ID")
 		return
 	}
 
 	var productCategory models.ProductCategory
 	err = json.NewDecoder(r.Body).Decode(&productCategory)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
 	productCategory.ID = id
 	err = h.service.Update(&productCategory)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -119,13 +119,13 @@ func (h *ProductCategoryHandler) Delete(w http.ResponseWriter, r *http.Request)
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/product-categories/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid category ID")
 		return
 	}
 
 	err = h.service.Delete(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
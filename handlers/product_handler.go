@@ -11,10 +11,11 @@ import (
 
 type ProductHandler struct {
 	service *services.ProductService
+	stock   *services.StockService
 }
 
-func NewProductHandler(service *services.ProductService) *ProductHandler {
-	return &ProductHandler{service: service}
+func NewProductHandler(service *services.ProductService, stock *services.StockService) *ProductHandler {
+	return &ProductHandler{service: service, stock: stock}
 }
 
 func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request) {
@@ -24,7 +25,7 @@ func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request)
 	case http.MethodPost:
 		h.Create(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, r)
 	}
 }
 
@@ -32,7 +33,7 @@ func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	name := r.URL.Query().Get("name")
 	products, err := h.service.GetAll(name)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -44,13 +45,17 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var product models.Product
 	err := json.NewDecoder(r.Body).Decode(&product)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
+	if user := CurrentUser(r); user != nil {
+		product.ChangedBy = user.ID
+	}
+
 	err = h.service.Create(&product)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -59,56 +64,132 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(product)
 }
 
-func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetByID(w, r)
-	case http.MethodPut:
-		h.Update(w, r)
-	case http.MethodDelete:
-		h.Delete(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// HandleLookup - GET /api/products/lookup?barcode=... atau ?sku=..., dipakai scanner di kasir
+func (h *ProductHandler) HandleLookup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, r)
+		return
 	}
-}
 
-func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/products/")
-	id, err := strconv.Atoi(idStr)
+	query := r.URL.Query()
+	product, err := h.service.Lookup(query.Get("barcode"), query.Get("sku"))
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 
-	product, err := h.service.GetByID(id)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+// HandleGenerateBarcodes - POST /api/products/barcodes, EAN-13 internal untuk produk tanpa barcode
+func (h *ProductHandler) HandleGenerateBarcodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	var req models.GenerateBarcodesRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, r, "Invalid request body")
+			return
+		}
+	}
+
+	products, err := h.service.GenerateBarcodes(req.ProductIDs)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeServiceError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(product)
+	json.NewEncoder(w).Encode(products)
+}
+
+// HandleLabelSheet - GET /api/products/labels?ids=1,2,2, PDF A4 berisi label produk
+func (h *ProductHandler) HandleLabelSheet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	var ids []int
+	if value := r.URL.Query().Get("ids"); value != "" {
+		for idStr := range strings.SplitSeq(value, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(idStr))
+			if err != nil {
+				writeBadRequest(w, r, "Invalid ids")
+				return
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	content, err := h.service.LabelSheet(ids)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="label-produk.pdf"`)
+	w.Write(content)
 }
 
-func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/products/")
+// HandleProductByID - /api/products/{id}, /api/products/{id}/label?format=svg|png dan
+// /api/products/{id}/stock-movements
+func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/products/"), "/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid product ID")
 		return
 	}
 
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "" && r.Method == http.MethodDelete:
+		h.Delete(w, r, id)
+	case action == "label" && r.Method == http.MethodGet:
+		h.Label(w, r, id)
+	case action == "stock-movements" && r.Method == http.MethodGet:
+		h.StockMovements(w, r, id)
+	case action == "stock-movements" && r.Method == http.MethodPost:
+		h.RecordStockMovement(w, r, id)
+	case action == "" || action == "label" || action == "stock-movements":
+		writeMethodNotAllowed(w, r)
+	default:
+		writeNotFound(w, r)
+	}
+}
+
+func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	product, err := h.service.GetByID(id)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
 	var product models.Product
-	err = json.NewDecoder(r.Body).Decode(&product)
+	err := json.NewDecoder(r.Body).Decode(&product)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
 	product.ID = id
 	err = h.service.Update(&product)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -116,22 +197,104 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(product)
 }
 
-func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/products/")
-	id, err := strconv.Atoi(idStr)
+func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request, id int) {
+	err := h.service.Delete(id)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeServiceError(w, r, err)
 		return
 	}
 
-	err = h.service.Delete(id)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Product deleted successfully",
+	})
+}
+
+func (h *ProductHandler) Label(w http.ResponseWriter, r *http.Request, id int) {
+	content, contentType, err := h.service.Label(id, r.URL.Query().Get("format"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(content)
+}
+
+// StockMovements - GET /api/products/{id}/stock-movements?start_date=&end_date=&type=&page=&limit=
+func (h *ProductHandler) StockMovements(w http.ResponseWriter, r *http.Request, id int) {
+	q := r.URL.Query()
+	filter := models.StockMovementFilter{Type: q.Get("type")}
+
+	var ok bool
+	if filter.StartDate, filter.EndDate, ok = parseOptionalDateRange(w, r); !ok {
+		return
+	}
+	if value := q.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil {
+			writeBadRequest(w, r, "Invalid page")
+			return
+		}
+		filter.Page = page
+	}
+	if value := q.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			writeBadRequest(w, r, "Invalid limit")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	list, err := h.stock.Movements(id, filter)
+	if err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Product deleted successfully",
-	})
+	json.NewEncoder(w).Encode(list)
+}
+
+// RecordStockMovement - POST /api/products/{id}/stock-movements, penerimaan barang, transfer,
+// opname atau adjustment manual
+func (h *ProductHandler) RecordStockMovement(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.StockMovementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	req.ProductID = id
+	if user := CurrentUser(r); user != nil {
+		req.CreatedBy = user.ID
+	}
+
+	movement, err := h.stock.RecordMovement(req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
+
+// HandleStockCheck - GET /api/products/stock-check, hitung ulang stok semua produk dari ledger
+func (h *ProductHandler) HandleStockCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	check, err := h.stock.Check()
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(check)
 }
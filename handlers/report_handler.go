@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"aplikasi-kasir/models"
 	"aplikasi-kasir/services"
 	"encoding/json"
 	"net/http"
@@ -17,13 +18,13 @@ func NewReportHandler(reportService *services.ReportService) *ReportHandler {
 
 func (h *ReportHandler) HandleDailyReport(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, r)
 		return
 	}
 
 	report, err := h.reportService.GetDailyReport()
 	if err != nil {
-		http.Error(w, "Failed to get daily report: "+err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -33,7 +34,7 @@ func (h *ReportHandler) HandleDailyReport(w http.ResponseWriter, r *http.Request
 
 func (h *ReportHandler) HandleReport(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, r)
 		return
 	}
 
@@ -45,19 +46,19 @@ func (h *ReportHandler) HandleReport(w http.ResponseWriter, r *http.Request) {
 		// Parse dates
 		startDate, err := time.Parse("2006-01-02", startDateStr)
 		if err != nil {
-			http.Error(w, "Invalid start_date format. Use YYYY-MM-DD", http.StatusBadRequest)
+			writeBadRequest(w, r, "Invalid start_date format. Use YYYY-MM-DD")
 			return
 		}
 
 		endDate, err := time.Parse("2006-01-02", endDateStr)
 		if err != nil {
-			http.Error(w, "Invalid end_date format. Use YYYY-MM-DD", http.StatusBadRequest)
+			writeBadRequest(w, r, "Invalid end_date format. Use YYYY-MM-DD")
 			return
 		}
 
 		report, err := h.reportService.GetReportByDateRange(startDate, endDate)
 		if err != nil {
-			http.Error(w, "Failed to get report: "+err.Error(), http.StatusInternalServerError)
+			writeServiceError(w, r, err)
 			return
 		}
 
@@ -69,10 +70,145 @@ func (h *ReportHandler) HandleReport(w http.ResponseWriter, r *http.Request) {
 	// If no date range, return today's report
 	report, err := h.reportService.GetDailyReport()
 	if err != nil {
-		http.Error(w, "Failed to get daily report: "+err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(report)
 }
+
+// HandleCashierReport - GET /api/report/kasir?start_date=&end_date=, tanpa tanggal = hari ini
+func (h *ReportHandler) HandleCashierReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	startDate, endDate, ok := parseOptionalDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.GetCashierReport(startDate, endDate)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleProductReport - GET /api/report/produk?start_date=&end_date=, varian digabung ke induknya
+func (h *ReportHandler) HandleProductReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	startDate, endDate, ok := parseOptionalDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.GetProductSalesReport(startDate, endDate)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleComponentReport - GET /api/report/komponen?start_date=&end_date=, pemakaian bahan resep
+func (h *ReportHandler) HandleComponentReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, r)
+		return
+	}
+
+	startDate, endDate, ok := parseOptionalDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.GetComponentConsumption(startDate, endDate)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// parseOptionalDateRange - start_date / end_date opsional, response 400 sudah ditulis jika ok false
+func parseOptionalDateRange(w http.ResponseWriter, r *http.Request) (startDate, endDate *time.Time, ok bool) {
+	if value := r.URL.Query().Get("start_date"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			writeBadRequest(w, r, "Invalid start_date format. Use YYYY-MM-DD")
+			return nil, nil, false
+		}
+		startDate = &date
+	}
+	if value := r.URL.Query().Get("end_date"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			writeBadRequest(w, r, "Invalid end_date format. Use YYYY-MM-DD")
+			return nil, nil, false
+		}
+		endDate = &date
+	}
+	return startDate, endDate, true
+}
+
+// HandleXReport - GET /api/report/x?date= untuk riwayat, POST untuk cetak X report (tidak menutup hari)
+func (h *ReportHandler) HandleXReport(w http.ResponseWriter, r *http.Request) {
+	h.handleEndOfDay(w, r, models.EndOfDayReportX)
+}
+
+// HandleZReport - GET /api/report/z?date= untuk riwayat, POST untuk tutup hari
+func (h *ReportHandler) HandleZReport(w http.ResponseWriter, r *http.Request) {
+	h.handleEndOfDay(w, r, models.EndOfDayReportZ)
+}
+
+func (h *ReportHandler) handleEndOfDay(w http.ResponseWriter, r *http.Request, reportType string) {
+	switch r.Method {
+	case http.MethodGet:
+		reports, err := h.reportService.GetEndOfDayReports(reportType, r.URL.Query().Get("date"))
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(reports)
+	case http.MethodPost:
+		var req models.EndOfDayRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeBadRequest(w, r, "Invalid request body")
+				return
+			}
+		}
+
+		if user := CurrentUser(r); user != nil {
+			req.CreatedBy = user.ID
+		}
+
+		report, err := h.reportService.CreateEndOfDayReport(reportType, req)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(report)
+	default:
+		writeMethodNotAllowed(w, r)
+	}
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type ShiftHandler struct {
+	service *services.ShiftService
+}
+
+func NewShiftHandler(service *services.ShiftService) *ShiftHandler {
+	return &ShiftHandler{service: service}
+}
+
+// GET /api/shifts?terminal_id=&cashier_id=&status=
+func (h *ShiftHandler) HandleShifts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	default:
+		writeMethodNotAllowed(w, r)
+	}
+}
+
+func (h *ShiftHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseShiftFilter(r)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+
+	shifts, err := h.service.GetAll(filter)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shifts)
+}
+
+func parseShiftFilter(r *http.Request) (models.ShiftFilter, error) {
+	q := r.URL.Query()
+	filter := models.ShiftFilter{Status: q.Get("status")}
+
+	parseInt := func(key string) (*int, error) {
+		value := q.Get(key)
+		if value == "" {
+			return nil, nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New("Invalid " + key)
+		}
+		return &n, nil
+	}
+
+	var err error
+	if filter.TerminalID, err = parseInt("terminal_id"); err != nil {
+		return filter, err
+	}
+	if filter.CashierID, err = parseInt("cashier_id"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// /api/shifts/open, /api/shifts/current?terminal_id=, /api/shifts/{id},
+// /api/shifts/{id}/close, /api/shifts/{id}/cash-movements
+func (h *ShiftHandler) HandleShiftByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/shifts/"), "/")
+
+	switch idStr {
+	case "open":
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, r)
+			return
+		}
+		h.Open(w, r)
+		return
+	case "current":
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, r)
+			return
+		}
+		h.GetCurrent(w, r)
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid shift ID")
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "close" && r.Method == http.MethodPost:
+		h.Close(w, r, id)
+	case action == "cash-movements" && r.Method == http.MethodPost:
+		h.AddCashMovement(w, r, id)
+	case action == "" || action == "close" || action == "cash-movements":
+		writeMethodNotAllowed(w, r)
+	default:
+		writeNotFound(w, r)
+	}
+}
+
+// ShiftByIDPermission - PermissionRule untuk /api/shifts/...: melihat shift lain butuh izin laporan,
+// sisanya untuk kasir yang sedang bertugas
+func ShiftByIDPermission(r *http.Request) string {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/shifts/"), "/")
+	if idStr != "open" && idStr != "current" && action == "" {
+		return models.PermissionReportRead
+	}
+	return models.PermissionShiftOperate
+}
+
+func (h *ShiftHandler) Open(w http.ResponseWriter, r *http.Request) {
+	var req models.OpenShiftRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	if user := CurrentUser(r); user != nil {
+		req.CashierID = user.ID
+	}
+
+	shift, err := h.service.Open(req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shift)
+}
+
+func (h *ShiftHandler) GetCurrent(w http.ResponseWriter, r *http.Request) {
+	terminalID, err := strconv.Atoi(r.URL.Query().Get("terminal_id"))
+	if err != nil {
+		writeBadRequest(w, r, "Invalid terminal_id")
+		return
+	}
+
+	shift, err := h.service.GetOpenByTerminal(terminalID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+}
+
+func (h *ShiftHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	shift, err := h.service.GetByID(id)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+}
+
+func (h *ShiftHandler) Close(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.CloseShiftRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	if user := CurrentUser(r); user != nil {
+		req.ClosedBy = user.ID
+	}
+
+	shift, err := h.service.Close(id, req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+}
+
+func (h *ShiftHandler) AddCashMovement(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.CashMovementRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	if user := CurrentUser(r); user != nil {
+		req.CreatedBy = user.ID
+	}
+
+	movement, err := h.service.AddCashMovement(id, req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/services"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type TerminalHandler struct {
+	service *services.TerminalService
+}
+
+func NewTerminalHandler(service *services.TerminalService) *TerminalHandler {
+	return &TerminalHandler{service: service}
+}
+
+func (h *TerminalHandler) HandleTerminals(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		writeMethodNotAllowed(w, r)
+	}
+}
+
+func (h *TerminalHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	terminals, err := h.service.GetAll()
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(terminals)
+}
+
+func (h *TerminalHandler) Create(w http.ResponseWriter, r *http.Request) {
+	terminal := models.Terminal{Active: true}
+	err := json.NewDecoder(r.Body).Decode(&terminal)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	err = h.service.Create(&terminal)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(terminal)
+}
+
+func (h *TerminalHandler) HandleTerminalByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	case http.MethodPut:
+		h.Update(w, r)
+	default:
+		writeMethodNotAllowed(w, r)
+	}
+}
+
+func (h *TerminalHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/terminals/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid terminal ID")
+		return
+	}
+
+	terminal, err := h.service.GetByID(id)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(terminal)
+}
+
+func (h *TerminalHandler) Update(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/terminals/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid terminal ID")
+		return
+	}
+
+	var terminal models.Terminal
+	err = json.NewDecoder(r.Body).Decode(&terminal)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	terminal.ID = id
+	err = h.service.Update(&terminal)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(terminal)
+}
@@ -2,18 +2,27 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"aplikasi-kasir/middleware"
 	"aplikasi-kasir/models"
 	"aplikasi-kasir/services"
 )
 
 type TransactionHandler struct {
-	service *services.TransactionService
+	service  *services.TransactionService
+	receipts *services.ReceiptService
+	useLock  bool // models.CheckoutLockPessimistic
 }
 
-func NewTransactionHandler(service *services.TransactionService) *TransactionHandler {
-	return &TransactionHandler{service: service}
+func NewTransactionHandler(service *services.TransactionService, receipts *services.ReceiptService, lockMode string) *TransactionHandler {
+	return &TransactionHandler{service: service, receipts: receipts, useLock: lockMode != models.CheckoutLockOptimistic}
 }
 
 // multiple item apa aja, quantity nya
@@ -22,7 +31,7 @@ func (h *TransactionHandler) HandleCheckout(w http.ResponseWriter, r *http.Reque
 	case http.MethodPost:
 		h.Checkout(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w, r)
 	}
 }
 
@@ -30,16 +39,293 @@ func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 	var req models.CheckoutRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, r, "Invalid request body")
 		return
 	}
 
-	transaction, err := h.service.Checkout(req.Items, true)
+	req.IdempotencyKey = r.Header.Get("Idempotency-Key")
+	if user := CurrentUser(r); user != nil {
+		req.CashierID = user.ID
+	}
+
+	transaction, replayed, err := h.service.Checkout(req, h.useLock)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	} else {
+		// cetak di background supaya printer yang lambat / mati tidak menahan kasir,
+		// struk bisa dicetak ulang lewat POST /api/transactions/{id}/print
+		requestID := middleware.GetRequestID(r.Context())
+		go func() {
+			if err := h.receipts.AutoPrint(transaction); err != nil {
+				log.Printf("request %s: cetak struk transaksi %d: %v", requestID, transaction.ID, err)
+			}
+		}()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
+
+// GET /api/transactions?start_date=&end_date=&min_amount=&max_amount=&product_id=&cashier_id=&terminal_id=&shift_id=&receipt_number=&status=&sort=-created_at&page=&limit=
+func (h *TransactionHandler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	default:
+		writeMethodNotAllowed(w, r)
+	}
+}
+
+func (h *TransactionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTransactionFilter(r)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+
+	transactions, err := h.service.GetAll(filter)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transactions)
+}
+
+// /api/transactions/{id}, /api/transactions/{id}/void, /api/transactions/{id}/refund,
+// /api/transactions/{id}/receipt?format=txt|html|pdf|escpos&width=58|80, /api/transactions/{id}/print?open_drawer=true
+func (h *TransactionHandler) HandleTransactionByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/transactions/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid transaction ID")
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "void" && r.Method == http.MethodPost:
+		h.Void(w, r, id)
+	case action == "refund" && r.Method == http.MethodPost:
+		h.Refund(w, r, id)
+	case action == "receipt" && r.Method == http.MethodGet:
+		h.Receipt(w, r, id)
+	case action == "print" && r.Method == http.MethodPost:
+		h.Print(w, r, id)
+	case action == "" || action == "void" || action == "refund" || action == "receipt" || action == "print":
+		writeMethodNotAllowed(w, r)
+	default:
+		writeNotFound(w, r)
+	}
+}
+
+// TransactionByIDPermission - PermissionRule untuk /api/transactions/{id}, {id}/void dan {id}/refund
+func TransactionByIDPermission(r *http.Request) string {
+	_, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/transactions/"), "/")
+	switch action {
+	case "void":
+		return models.PermissionTransactionVoid
+	case "refund":
+		return models.PermissionTransactionRefund
+	case "print":
+		return models.PermissionCheckout // bisa membuka laci kas
+	default:
+		return models.PermissionTransactionRead
+	}
+}
+
+func (h *TransactionHandler) Receipt(w http.ResponseWriter, r *http.Request, id int) {
+	format := r.URL.Query().Get("format")
+
+	var width int
+	if value := r.URL.Query().Get("width"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			writeBadRequest(w, r, "Invalid width")
+			return
+		}
+		width = n
+	}
+
+	content, contentType, err := h.receipts.Render(id, format, width)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if format == models.ReceiptFormatPDF {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="struk-%d.pdf"`, id))
+	}
+	w.Write(content)
+}
+
+func (h *TransactionHandler) Print(w http.ResponseWriter, r *http.Request, id int) {
+	openDrawer := false
+	if value := r.URL.Query().Get("open_drawer"); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			writeBadRequest(w, r, "Invalid open_drawer")
+			return
+		}
+		openDrawer = b
+	}
+
+	if err := h.receipts.Print(id, openDrawer); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TransactionHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	transaction, err := h.service.GetByID(id)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
+
+func (h *TransactionHandler) Void(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.VoidRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	if user := CurrentUser(r); user != nil {
+		req.VoidedBy = user.ID
+	}
+
+	transaction, err := h.service.Void(id, req)
+	if err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(transaction)
 }
+
+func (h *TransactionHandler) Refund(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.RefundRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeBadRequest(w, r, "Invalid request body")
+		return
+	}
+
+	if user := CurrentUser(r); user != nil {
+		req.CreatedBy = user.ID
+	}
+
+	refund, err := h.service.Refund(id, req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(refund)
+}
+
+func parseTransactionFilter(r *http.Request) (models.TransactionFilter, error) {
+	q := r.URL.Query()
+	var filter models.TransactionFilter
+
+	parseDate := func(key string) (*time.Time, error) {
+		value := q.Get(key)
+		if value == "" {
+			return nil, nil
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, errors.New("Invalid " + key + " format. Use YYYY-MM-DD")
+		}
+		return &date, nil
+	}
+
+	parseInt := func(key string) (*int, error) {
+		value := q.Get(key)
+		if value == "" {
+			return nil, nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New("Invalid " + key)
+		}
+		return &n, nil
+	}
+
+	var err error
+	if filter.StartDate, err = parseDate("start_date"); err != nil {
+		return filter, err
+	}
+	if filter.EndDate, err = parseDate("end_date"); err != nil {
+		return filter, err
+	}
+	if filter.MinAmount, err = parseInt("min_amount"); err != nil {
+		return filter, err
+	}
+	if filter.MaxAmount, err = parseInt("max_amount"); err != nil {
+		return filter, err
+	}
+	if filter.ProductID, err = parseInt("product_id"); err != nil {
+		return filter, err
+	}
+	if filter.CashierID, err = parseInt("cashier_id"); err != nil {
+		return filter, err
+	}
+	if filter.TerminalID, err = parseInt("terminal_id"); err != nil {
+		return filter, err
+	}
+	if filter.ShiftID, err = parseInt("shift_id"); err != nil {
+		return filter, err
+	}
+
+	page, err := parseInt("page")
+	if err != nil {
+		return filter, err
+	}
+	if page != nil {
+		filter.Page = *page
+	}
+
+	limit, err := parseInt("limit")
+	if err != nil {
+		return filter, err
+	}
+	if limit != nil {
+		filter.Limit = *limit
+	}
+
+	filter.Status = q.Get("status")
+	filter.ReceiptNumber = strings.TrimSpace(q.Get("receipt_number"))
+
+	// sort=total_amount (ascending) atau sort=-total_amount (descending), default terbaru dulu
+	sort := q.Get("sort")
+	if sort == "" {
+		sort = "-created_at"
+	}
+	filter.SortDesc = strings.HasPrefix(sort, "-")
+	filter.SortBy = strings.TrimPrefix(sort, "-")
+	switch filter.SortBy {
+	case "id", "created_at", "total_amount":
+	default:
+		return filter, errors.New("Invalid sort. Use id, created_at or total_amount (prefix - for descending)")
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"aplikasi-kasir/services"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckoutHandler(t *testing.T) {
+	for _, lockMode := range []string{models.CheckoutLockPessimistic, models.CheckoutLockOptimistic} {
+		t.Run(lockMode, func(t *testing.T) { testCheckoutHandler(t, lockMode) })
+	}
+}
+
+func testCheckoutHandler(t *testing.T, lockMode string) {
+	store := repositories.NewMemoryStore()
+	productRepo := repositories.NewMemoryProductRepository(store)
+	if err := productRepo.Create(&models.Product{Name: "Es Teh", Price: 5000, Stock: 2}); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	cashier := &models.User{Username: "kasir1", Name: "Kasir Satu", Role: models.RoleCashier, Active: true}
+	if err := repositories.NewMemoryUserRepository(store).Create(cashier); err != nil {
+		t.Fatalf("create cashier: %v", err)
+	}
+	if err := repositories.NewMemoryTerminalRepository(store).Create(&models.Terminal{Code: "KASIR-01", Name: "Kasir depan", Active: true}); err != nil {
+		t.Fatalf("create terminal: %v", err)
+	}
+	if _, err := repositories.NewMemoryShiftRepository(store).Open(models.OpenShiftRequest{TerminalID: 1, CashierID: cashier.ID}); err != nil {
+		t.Fatalf("open shift: %v", err)
+	}
+
+	transactionRepo := repositories.NewMemoryTransactionRepository(store, models.TaxConfig{Mode: models.TaxModeInclusive}, models.ReceiptNumberConfig{Prefix: "INV", OutletCode: "OUTLET1", Digits: 4})
+	receiptService := services.NewReceiptService(transactionRepo, repositories.NewMemoryUserRepository(store), repositories.NewMemoryTerminalRepository(store), models.ReceiptStoreConfig{
+		StoreName:      "Toko Maju",
+		HeaderTemplate: models.DefaultReceiptHeaderTemplate,
+		FooterTemplate: models.DefaultReceiptFooterTemplate,
+	}, services.NetworkPrinter{Timeout: time.Second})
+	handler := NewTransactionHandler(services.NewTransactionService(transactionRepo, productRepo), receiptService, lockMode)
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		idempotencyKey string
+		status         int
+		code           string // kode error, kosong jika sukses
+		replayed       bool
+	}{
+		{name: "method salah", method: http.MethodGet, status: http.StatusMethodNotAllowed, code: ErrorCodeMethodNotAllowed},
+		{name: "body bukan JSON", method: http.MethodPost, body: "{", status: http.StatusBadRequest, code: ErrorCodeInvalidRequest},
+		{
+			name:   "quantity tidak valid",
+			method: http.MethodPost,
+			body:   `{"terminal_id":1,"items":[{"product_id":1,"quantity":0}],"payments":[{"method":"cash","amount":5000}]}`,
+			status: http.StatusUnprocessableEntity,
+			code:   ErrorCodeValidation,
+		},
+		{
+			name:   "stok kurang",
+			method: http.MethodPost,
+			body:   `{"terminal_id":1,"items":[{"product_id":1,"quantity":3}],"payments":[{"method":"cash","amount":15000}]}`,
+			status: http.StatusConflict,
+			code:   ErrorCodeInsufficientStock,
+		},
+		{
+			name:   "produk tidak ada",
+			method: http.MethodPost,
+			body:   `{"terminal_id":1,"items":[{"product_id":9,"quantity":1}],"payments":[{"method":"cash","amount":5000}]}`,
+			status: http.StatusNotFound,
+			code:   ErrorCodeNotFound,
+		},
+		{
+			name:   "barcode tidak dikenal",
+			method: http.MethodPost,
+			body:   `{"terminal_id":1,"items":[{"barcode":"4006381333931","quantity":1}],"payments":[{"method":"cash","amount":5000}]}`,
+			status: http.StatusUnprocessableEntity,
+			code:   ErrorCodeValidation,
+		},
+		{
+			name:           "berhasil",
+			method:         http.MethodPost,
+			body:           `{"terminal_id":1,"items":[{"product_id":1,"quantity":1}],"payments":[{"method":"cash","amount":5000}]}`,
+			idempotencyKey: "abc",
+			status:         http.StatusOK,
+		},
+		{
+			name:           "replay",
+			method:         http.MethodPost,
+			body:           `{"terminal_id":1,"items":[{"product_id":1,"quantity":1}],"payments":[{"method":"cash","amount":5000}]}`,
+			idempotencyKey: "abc",
+			status:         http.StatusOK,
+			replayed:       true,
+		},
+		{
+			name:           "key sama payload beda",
+			method:         http.MethodPost,
+			body:           `{"terminal_id":1,"items":[{"product_id":1,"quantity":2}],"payments":[{"method":"cash","amount":10000}]}`,
+			idempotencyKey: "abc",
+			status:         http.StatusConflict,
+			code:           ErrorCodeConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/checkout", strings.NewReader(tt.body))
+			if tt.idempotencyKey != "" {
+				req.Header.Set("Idempotency-Key", tt.idempotencyKey)
+			}
+			// kasir yang login, biasanya diisi RequireAuth
+			req = req.WithContext(context.WithValue(req.Context(), authContextKey{}, &authInfo{user: cashier}))
+			rec := httptest.NewRecorder()
+
+			handler.HandleCheckout(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.status, rec.Body.String())
+			}
+			if got := rec.Header().Get("Idempotent-Replayed") == "true"; got != tt.replayed {
+				t.Errorf("Idempotent-Replayed = %v, want %v", got, tt.replayed)
+			}
+
+			if tt.code != "" {
+				var body ErrorResponse
+				if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+					t.Fatalf("decode error body: %v", err)
+				}
+				if body.Code != tt.code {
+					t.Errorf("code = %q, want %q", body.Code, tt.code)
+				}
+			}
+		})
+	}
+
+	product, _ := productRepo.GetByID(1)
+	if product.Stock != 1 {
+		t.Errorf("stock = %d, want 1", product.Stock)
+	}
+
+	receiptTests := []struct {
+		name        string
+		path        string
+		method      string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{name: "struk teks", path: "/api/transactions/1/receipt?width=58", status: http.StatusOK, contentType: "text/plain; charset=utf-8", contains: "INV/OUTLET1/"},
+		{name: "struk html", path: "/api/transactions/1/receipt?format=html", status: http.StatusOK, contentType: "text/html; charset=utf-8", contains: "Toko Maju"},
+		{name: "struk pdf", path: "/api/transactions/1/receipt?format=pdf", status: http.StatusOK, contentType: "application/pdf", contains: "%PDF-1.4"},
+		{name: "format tidak dikenal", path: "/api/transactions/1/receipt?format=doc", status: http.StatusUnprocessableEntity},
+		{name: "width bukan angka", path: "/api/transactions/1/receipt?width=lebar", status: http.StatusBadRequest},
+		{name: "transaksi tidak ada", path: "/api/transactions/9/receipt", status: http.StatusNotFound},
+		{name: "method salah", path: "/api/transactions/1/receipt", method: http.MethodPost, status: http.StatusMethodNotAllowed},
+		{name: "struk escpos", path: "/api/transactions/1/receipt?format=escpos", status: http.StatusOK, contentType: "application/octet-stream", contains: "INV/OUTLET1/"},
+		{name: "cetak tanpa printer", path: "/api/transactions/1/print", method: http.MethodPost, status: http.StatusConflict},
+		{name: "cetak open_drawer salah", path: "/api/transactions/1/print?open_drawer=ya", method: http.MethodPost, status: http.StatusBadRequest},
+		{name: "cetak method salah", path: "/api/transactions/1/print", status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range receiptTests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rec := httptest.NewRecorder()
+
+			handler.HandleTransactionByID(rec, httptest.NewRequest(method, tt.path, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.contentType != "" && rec.Header().Get("Content-Type") != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), tt.contentType)
+			}
+			if !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Errorf("body tidak mengandung %q:\n%s", tt.contains, rec.Body.String())
+			}
+		})
+	}
+}
@@ -3,34 +3,141 @@ package main
 import (
 	"aplikasi-kasir/database"
 	"aplikasi-kasir/handlers"
+	"aplikasi-kasir/middleware"
+	"aplikasi-kasir/models"
 	"aplikasi-kasir/repositories"
 	"aplikasi-kasir/services"
+	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Port   string `mapstructure:"PORT"`
-	DBConn string `mapstructure:"DB_CONN"`
+	Port        string `mapstructure:"PORT"`
+	DBConn      string `mapstructure:"DB_CONN"`
+	AutoMigrate bool   `mapstructure:"AUTO_MIGRATE"` // jalankan migrasi saat server start
+
+	TaxMode           string  `mapstructure:"TAX_MODE"`            // inclusive | exclusive
+	TaxRate           float64 `mapstructure:"TAX_RATE"`            // tarif PPN default (persen)
+	ServiceChargeRate float64 `mapstructure:"SERVICE_CHARGE_RATE"` // persen, 0 = nonaktif
+
+	CheckoutLockMode string `mapstructure:"CHECKOUT_LOCK_MODE"` // pessimistic | optimistic
+
+	// nomor struk {RECEIPT_PREFIX}/{OUTLET_CODE}/{YYYYMMDD}/{urutan RECEIPT_DIGITS digit}
+	ReceiptPrefix string `mapstructure:"RECEIPT_PREFIX"`
+	OutletCode    string `mapstructure:"OUTLET_CODE"`
+	ReceiptDigits int    `mapstructure:"RECEIPT_DIGITS"`
+
+	// identitas toko dan template header / footer struk (text/template)
+	StoreName     string `mapstructure:"STORE_NAME"`
+	StoreAddress  string `mapstructure:"STORE_ADDRESS"`
+	StorePhone    string `mapstructure:"STORE_PHONE"`
+	StoreNPWP     string `mapstructure:"STORE_NPWP"`
+	ReceiptHeader string `mapstructure:"RECEIPT_HEADER"`
+	ReceiptFooter string `mapstructure:"RECEIPT_FOOTER"`
+
+	PrinterTimeout time.Duration `mapstructure:"PRINTER_TIMEOUT"` // connect + kirim ke printer jaringan terminal
+
+	AccessTokenTTL   time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL  time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
+	MaxLoginAttempts int           `mapstructure:"MAX_LOGIN_ATTEMPTS"` // per username dalam LOGIN_WINDOW
+	LoginWindow      time.Duration `mapstructure:"LOGIN_WINDOW"`
+
+	// akun owner pertama, hanya dibuat jika tabel users masih kosong
+	BootstrapOwnerUsername string `mapstructure:"BOOTSTRAP_OWNER_USERNAME"`
+	BootstrapOwnerPassword string `mapstructure:"BOOTSTRAP_OWNER_PASSWORD"`
 }
 
 func main() {
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
+	viper.SetDefault("AUTO_MIGRATE", true)
+	viper.SetDefault("TAX_MODE", models.TaxModeInclusive)
+	viper.SetDefault("TAX_RATE", 11)
+	viper.SetDefault("SERVICE_CHARGE_RATE", 0)
+	viper.SetDefault("CHECKOUT_LOCK_MODE", models.CheckoutLockPessimistic)
+	viper.SetDefault("RECEIPT_PREFIX", "INV")
+	viper.SetDefault("OUTLET_CODE", "OUTLET1")
+	viper.SetDefault("RECEIPT_DIGITS", 4)
+	viper.SetDefault("STORE_NAME", "Aplikasi Kasir")
+	viper.SetDefault("RECEIPT_HEADER", models.DefaultReceiptHeaderTemplate)
+	viper.SetDefault("RECEIPT_FOOTER", models.DefaultReceiptFooterTemplate)
+	viper.SetDefault("PRINTER_TIMEOUT", "5s")
+	viper.SetDefault("ACCESS_TOKEN_TTL", "12h")
+	viper.SetDefault("REFRESH_TOKEN_TTL", "168h")
+	viper.SetDefault("MAX_LOGIN_ATTEMPTS", 5)
+	viper.SetDefault("LOGIN_WINDOW", "15m")
+	viper.SetDefault("BOOTSTRAP_OWNER_USERNAME", "owner")
+
 	if _, err := os.Stat(".env"); err == nil {
 		viper.SetConfigFile(".env")
 		_ = viper.ReadInConfig()
 	}
 
 	config := Config{
-		Port:   viper.GetString("PORT"),
-		DBConn: viper.GetString("DB_CONN"),
+		Port:        viper.GetString("PORT"),
+		DBConn:      viper.GetString("DB_CONN"),
+		AutoMigrate: viper.GetBool("AUTO_MIGRATE"),
+
+		TaxMode:           viper.GetString("TAX_MODE"),
+		TaxRate:           viper.GetFloat64("TAX_RATE"),
+		ServiceChargeRate: viper.GetFloat64("SERVICE_CHARGE_RATE"),
+
+		CheckoutLockMode: viper.GetString("CHECKOUT_LOCK_MODE"),
+
+		ReceiptPrefix: viper.GetString("RECEIPT_PREFIX"),
+		OutletCode:    viper.GetString("OUTLET_CODE"),
+		ReceiptDigits: viper.GetInt("RECEIPT_DIGITS"),
+
+		StoreName:     viper.GetString("STORE_NAME"),
+		StoreAddress:  viper.GetString("STORE_ADDRESS"),
+		StorePhone:    viper.GetString("STORE_PHONE"),
+		StoreNPWP:     viper.GetString("STORE_NPWP"),
+		ReceiptHeader: viper.GetString("RECEIPT_HEADER"),
+		ReceiptFooter: viper.GetString("RECEIPT_FOOTER"),
+
+		PrinterTimeout: viper.GetDuration("PRINTER_TIMEOUT"),
+
+		AccessTokenTTL:   viper.GetDuration("ACCESS_TOKEN_TTL"),
+		RefreshTokenTTL:  viper.GetDuration("REFRESH_TOKEN_TTL"),
+		MaxLoginAttempts: viper.GetInt("MAX_LOGIN_ATTEMPTS"),
+		LoginWindow:      viper.GetDuration("LOGIN_WINDOW"),
+
+		BootstrapOwnerUsername: viper.GetString("BOOTSTRAP_OWNER_USERNAME"),
+		BootstrapOwnerPassword: viper.GetString("BOOTSTRAP_OWNER_PASSWORD"),
+	}
+
+	if config.TaxMode != models.TaxModeInclusive && config.TaxMode != models.TaxModeExclusive {
+		log.Fatal("TAX_MODE must be inclusive or exclusive, got ", config.TaxMode)
+	}
+	if config.CheckoutLockMode != models.CheckoutLockPessimistic && config.CheckoutLockMode != models.CheckoutLockOptimistic {
+		log.Fatal("CHECKOUT_LOCK_MODE must be pessimistic or optimistic, got ", config.CheckoutLockMode)
+	}
+	if config.OutletCode == "" || strings.Contains(config.OutletCode, "/") || strings.Contains(config.ReceiptPrefix, "/") {
+		log.Fatal("OUTLET_CODE must be set and OUTLET_CODE / RECEIPT_PREFIX must not contain '/'")
+	}
+	if config.ReceiptDigits < 1 || config.ReceiptDigits > 10 {
+		log.Fatal("RECEIPT_DIGITS must be between 1 and 10, got ", config.ReceiptDigits)
+	}
+
+	receiptStoreConfig := models.ReceiptStoreConfig{
+		StoreName:      config.StoreName,
+		StoreAddress:   config.StoreAddress,
+		StorePhone:     config.StorePhone,
+		StoreNPWP:      config.StoreNPWP,
+		HeaderTemplate: config.ReceiptHeader,
+		FooterTemplate: config.ReceiptFooter,
+	}
+	if err := services.ValidateReceiptTemplates(receiptStoreConfig); err != nil {
+		log.Fatal("Invalid RECEIPT_HEADER / RECEIPT_FOOTER template: ", err)
 	}
 
 	// setup database
@@ -41,40 +148,208 @@ func main() {
 
 	defer db.Close()
 
+	// go run . migrate [up|down [n]|status]
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(db, os.Args[2:]); err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+		return
+	}
+
+	if config.AutoMigrate {
+		if err := database.Migrate(db); err != nil {
+			log.Fatal("Failed to migrate database: ", err)
+		}
+	}
+
+	// Auth & User
+	userRepo := repositories.NewUserRepository(db)
+	sessionRepo := repositories.NewSessionRepository(db)
+	roleRepo := repositories.NewRoleRepository(db)
+	userService := services.NewUserService(userRepo, sessionRepo)
+	roleService := services.NewRoleService(roleRepo)
+	authService := services.NewAuthService(userRepo, sessionRepo, roleRepo, models.AuthConfig{
+		AccessTokenTTL:   config.AccessTokenTTL,
+		RefreshTokenTTL:  config.RefreshTokenTTL,
+		MaxLoginAttempts: config.MaxLoginAttempts,
+		LoginWindow:      config.LoginWindow,
+	})
+	authHandler := handlers.NewAuthHandler(authService)
+	userHandler := handlers.NewUserHandler(userService)
+	roleHandler := handlers.NewRoleHandler(roleService)
+
+	if config.BootstrapOwnerPassword != "" {
+		created, err := userService.EnsureOwner(config.BootstrapOwnerUsername, config.BootstrapOwnerPassword)
+		if err != nil {
+			log.Fatal("Failed to create owner account: ", err)
+		}
+		if created {
+			fmt.Println("Created owner account", config.BootstrapOwnerUsername)
+		}
+	}
+
+	// semua endpoint selain login & refresh wajib login, sebagian besar juga butuh izin tertentu
+	auth := authHandler.RequireAuth
+	require := authHandler.RequirePermission
+
+	http.HandleFunc("/api/auth/login", authHandler.HandleLogin)     // POST
+	http.HandleFunc("/api/auth/refresh", authHandler.HandleRefresh) // POST
+	http.HandleFunc("/api/auth/logout", auth(authHandler.HandleLogout))
+	http.HandleFunc("/api/auth/me", auth(authHandler.HandleMe))
+
+	http.HandleFunc("/api/users", require(userHandler.HandleUsers, handlers.Allow(models.PermissionUserManage)))
+	http.HandleFunc("/api/users/", require(userHandler.HandleUserByID, handlers.Allow(models.PermissionUserManage)))
+
+	http.HandleFunc("/api/permissions", require(roleHandler.HandlePermissions, handlers.Allow(models.PermissionRoleManage)))
+	http.HandleFunc("/api/roles", require(roleHandler.HandleRoles, handlers.Allow(models.PermissionRoleManage)))
+	http.HandleFunc("/api/roles/", require(roleHandler.HandleRoleByName, handlers.Allow(models.PermissionRoleManage)))
+
 	// Product
 	productRepo := repositories.NewProductRepository(db)
 	productService := services.NewProductService(productRepo)
-	productHandler := handlers.NewProductHandler(productService)
+	stockService := services.NewStockService(repositories.NewStockMovementRepository(db), productRepo)
+	productHandler := handlers.NewProductHandler(productService, stockService)
 
-	http.HandleFunc("/api/products", productHandler.HandleProducts)
-	http.HandleFunc("/api/products/", productHandler.HandleProductByID)
+	http.HandleFunc("/api/products", require(productHandler.HandleProducts, handlers.ByMethod(map[string]string{
+		http.MethodGet:  models.PermissionProductRead,
+		http.MethodPost: models.PermissionProductWrite,
+	})))
+	http.HandleFunc("/api/products/lookup", require(productHandler.HandleLookup, handlers.Allow(models.PermissionProductRead)))              // GET ?barcode= atau ?sku=
+	http.HandleFunc("/api/products/labels", require(productHandler.HandleLabelSheet, handlers.Allow(models.PermissionProductRead)))          // GET ?ids=1,2,2 (PDF A4)
+	http.HandleFunc("/api/products/barcodes", require(productHandler.HandleGenerateBarcodes, handlers.Allow(models.PermissionProductWrite))) // POST
+	http.HandleFunc("/api/products/stock-check", require(productHandler.HandleStockCheck, handlers.Allow(models.PermissionProductRead)))     // GET
+	http.HandleFunc("/api/products/", require(productHandler.HandleProductByID, handlers.ByMethod(map[string]string{
+		http.MethodGet:    models.PermissionProductRead,
+		http.MethodPost:   models.PermissionProductWrite,
+		http.MethodPut:    models.PermissionProductWrite,
+		http.MethodDelete: models.PermissionProductDelete,
+	}))) // GET, PUT, DELETE, GET {id}/label, GET / POST {id}/stock-movements
 
 	// Product Category
 	productCategoryRepo := repositories.NewProductCategoryRepository(db)
 	productCategoryService := services.NewProductCategoryService(productCategoryRepo)
 	productCategoryHandler := handlers.NewProductCategoryHandler(productCategoryService)
 
-	http.HandleFunc("/api/product-categories", productCategoryHandler.HandleProductCategories)
-	http.HandleFunc("/api/product-categories/", productCategoryHandler.HandleProductCategoryByID)
+	http.HandleFunc("/api/product-categories", require(productCategoryHandler.HandleProductCategories, handlers.ByMethod(map[string]string{
+		http.MethodGet:  models.PermissionCategoryRead,
+		http.MethodPost: models.PermissionCategoryWrite,
+	})))
+	http.HandleFunc("/api/product-categories/", require(productCategoryHandler.HandleProductCategoryByID, handlers.ByMethod(map[string]string{
+		http.MethodGet:    models.PermissionCategoryRead,
+		http.MethodPut:    models.PermissionCategoryWrite,
+		http.MethodDelete: models.PermissionCategoryWrite,
+	})))
+
+	// Terminal
+	terminalRepo := repositories.NewTerminalRepository(db)
+	terminalService := services.NewTerminalService(terminalRepo)
+	terminalHandler := handlers.NewTerminalHandler(terminalService)
+
+	// daftar terminal boleh dibaca semua user yang login (untuk memilih terminal di aplikasi kasir)
+	http.HandleFunc("/api/terminals", require(terminalHandler.HandleTerminals, handlers.ByMethod(map[string]string{
+		http.MethodPost: models.PermissionTerminalManage,
+	})))
+	http.HandleFunc("/api/terminals/", require(terminalHandler.HandleTerminalByID, handlers.ByMethod(map[string]string{
+		http.MethodPut: models.PermissionTerminalManage,
+	})))
+
+	// Shift & laci kas
+	shiftRepo := repositories.NewShiftRepository(db)
+	shiftService := services.NewShiftService(shiftRepo)
+	shiftHandler := handlers.NewShiftHandler(shiftService)
+
+	http.HandleFunc("/api/shifts", require(shiftHandler.HandleShifts, handlers.Allow(models.PermissionReportRead)))
+	http.HandleFunc("/api/shifts/", require(shiftHandler.HandleShiftByID, handlers.ShiftByIDPermission)) // POST open, GET current, GET {id}, POST {id}/close, POST {id}/cash-movements
 
 	// Transaction
-	transactionRepo := repositories.NewTransactionRepository(db)
-	transactionService := services.NewTransactionService(transactionRepo)
-	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	transactionRepo := repositories.NewTransactionRepository(db, models.TaxConfig{
+		Mode:              config.TaxMode,
+		DefaultRate:       config.TaxRate,
+		ServiceChargeRate: config.ServiceChargeRate,
+	}, models.ReceiptNumberConfig{
+		Prefix:     config.ReceiptPrefix,
+		OutletCode: config.OutletCode,
+		Digits:     config.ReceiptDigits,
+	})
+	transactionService := services.NewTransactionService(transactionRepo, productRepo)
+	receiptService := services.NewReceiptService(transactionRepo, userRepo, terminalRepo, receiptStoreConfig, services.NetworkPrinter{Timeout: config.PrinterTimeout})
+	transactionHandler := handlers.NewTransactionHandler(transactionService, receiptService, config.CheckoutLockMode)
+
+	http.HandleFunc("/api/checkout", require(transactionHandler.HandleCheckout, handlers.Allow(models.PermissionCheckout))) // POST
+	http.HandleFunc("/api/transactions", require(transactionHandler.HandleTransactions, handlers.Allow(models.PermissionTransactionRead)))
+	http.HandleFunc("/api/transactions/", require(transactionHandler.HandleTransactionByID, handlers.TransactionByIDPermission)) // GET, POST {id}/void, POST {id}/refund, GET {id}/receipt, POST {id}/print
+
+	// Promotion
+	promotionRepo := repositories.NewPromotionRepository(db)
+	promotionService := services.NewPromotionService(promotionRepo)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
 
-	http.HandleFunc("/api/checkout", transactionHandler.HandleCheckout) // POST
+	http.HandleFunc("/api/promotions", require(promotionHandler.HandlePromotions, handlers.ByMethod(map[string]string{
+		http.MethodGet:  models.PermissionPromotionRead,
+		http.MethodPost: models.PermissionPromotionWrite,
+	})))
+	http.HandleFunc("/api/promotions/", require(promotionHandler.HandlePromotionByID, handlers.ByMethod(map[string]string{
+		http.MethodGet:    models.PermissionPromotionRead,
+		http.MethodPut:    models.PermissionPromotionWrite,
+		http.MethodDelete: models.PermissionPromotionWrite,
+	})))
 
 	// Report
 	reportRepo := repositories.NewReportRepository(db)
 	reportService := services.NewReportService(reportRepo)
 	reportHandler := handlers.NewReportHandler(reportService)
 
-	http.HandleFunc("/api/report/hari-ini", reportHandler.HandleDailyReport)
-	http.HandleFunc("/api/report", reportHandler.HandleReport)
+	http.HandleFunc("/api/report/hari-ini", require(reportHandler.HandleDailyReport, handlers.Allow(models.PermissionReportRead)))
+	http.HandleFunc("/api/report/kasir", require(reportHandler.HandleCashierReport, handlers.Allow(models.PermissionReportRead)))
+	http.HandleFunc("/api/report/produk", require(reportHandler.HandleProductReport, handlers.Allow(models.PermissionReportRead)))
+	http.HandleFunc("/api/report/komponen", require(reportHandler.HandleComponentReport, handlers.Allow(models.PermissionReportRead)))
+	http.HandleFunc("/api/report/x", require(reportHandler.HandleXReport, handlers.Allow(models.PermissionReportRead)))
+	http.HandleFunc("/api/report/z", require(reportHandler.HandleZReport, handlers.ByMethod(map[string]string{
+		http.MethodGet:  models.PermissionReportRead,
+		http.MethodPost: models.PermissionReportClose,
+	})))
+	http.HandleFunc("/api/report", require(reportHandler.HandleReport, handlers.Allow(models.PermissionReportRead)))
 
 	addr := "0.0.0.0:" + config.Port
 	fmt.Println("Server running on port", addr)
-	if err := http.ListenAndServe(addr, nil); err != nil {
+	if err := http.ListenAndServe(addr, middleware.RequestID(http.DefaultServeMux)); err != nil {
 		fmt.Println("Error starting server:", err)
 	}
 }
+
+func runMigrateCommand(db *sql.DB, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		return database.Migrate(db)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		return database.MigrateDown(db, steps)
+	case "status":
+		status, err := database.GetMigrationStatus(db)
+		if err != nil {
+			return err
+		}
+		for _, m := range status {
+			applied := "pending"
+			if m.AppliedAt != nil {
+				applied = "applied " + m.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %s\n", m.Version, m.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q, use up, down [n] or status", command)
+	}
+}
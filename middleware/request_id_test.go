@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "dibuat jika kosong", incoming: ""},
+		{name: "dipakai dari client", incoming: "pos-01_abc-123", keep: true},
+		{name: "karakter tidak aman diganti", incoming: "abc\nFAKE LOG LINE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fromContext string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fromContext = GetRequestID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			header := rec.Header().Get(RequestIDHeader)
+			if header == "" || header != fromContext {
+				t.Fatalf("header %q, context %q", header, fromContext)
+			}
+			if (header == tt.incoming) != tt.keep {
+				t.Errorf("request id = %q, incoming %q, keep %v", header, tt.incoming, tt.keep)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sentinel error untuk dicek dengan errors.Is. Error bertipe di bawah (NotFoundError, dst.)
+// cocok dengan sentinel-nya masing-masing, sehingga pemanggil yang tidak butuh detail cukup
+// memakai errors.Is(err, models.ErrNotFound), dan repository boleh membungkus sentinel
+// langsung, contoh fmt.Errorf("promo %d: %w", id, models.ErrNotFound).
+var (
+	ErrNotFound           = errors.New("data tidak ditemukan")
+	ErrConflict           = errors.New("konflik dengan data saat ini")
+	ErrValidation         = errors.New("validasi gagal")
+	ErrInsufficientStock  = errors.New("stok tidak mencukupi")
+	ErrUnauthorized       = errors.New("belum login")
+	ErrTooManyRequests    = errors.New("terlalu banyak percobaan")
+	ErrPrinterUnavailable = errors.New("printer tidak bisa dihubungi")
+)
+
+// StockShortage - detail produk yang stoknya tidak mencukupi saat checkout
+type StockShortage struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Requested   int    `json:"requested"`
+	Available   int    `json:"available"`
+}
+
+// InsufficientStockError dikembalikan ketika satu atau lebih item di keranjang
+// melebihi stok yang tersedia. Seluruh keranjang ditolak.
+type InsufficientStockError struct {
+	Items []StockShortage `json:"items"`
+}
+
+func (e *InsufficientStockError) Error() string {
+	parts := make([]string, 0, len(e.Items))
+	for _, item := range e.Items {
+		parts = append(parts, fmt.Sprintf("%s (tersedia %d, diminta %d)", item.ProductName, item.Available, item.Requested))
+	}
+	return "stok tidak mencukupi: " + strings.Join(parts, ", ")
+}
+
+func (e *InsufficientStockError) Is(target error) bool {
+	return target == ErrInsufficientStock
+}
+
+// FieldError - satu kesalahan validasi pada field tertentu, contoh field: "items[2].quantity"
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError dikembalikan service ketika request tidak valid, sebelum menyentuh database.
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		parts = append(parts, fe.Field+": "+fe.Message)
+	}
+	return "validasi gagal: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// NewValidationError - validation error untuk satu field
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Errors: []FieldError{{Field: field, Message: message}}}
+}
+
+// NotFoundError - data yang diminta tidak ada
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ConflictError - operasi ditolak karena status data saat ini (misal transaksi sudah di-void)
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// UnauthorizedError - token tidak ada / tidak valid / kedaluwarsa, atau kredensial login salah
+type UnauthorizedError struct {
+	Message string
+}
+
+func (e *UnauthorizedError) Error() string {
+	return e.Message
+}
+
+func (e *UnauthorizedError) Is(target error) bool {
+	return target == ErrUnauthorized
+}
+
+// TooManyRequestsError - login diblokir sementara karena terlalu banyak percobaan gagal
+type TooManyRequestsError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *TooManyRequestsError) Error() string {
+	return e.Message
+}
+
+func (e *TooManyRequestsError) Is(target error) bool {
+	return target == ErrTooManyRequests
+}
+
+// PrinterError - printer jaringan tidak bisa dihubungi atau gagal menerima data
+type PrinterError struct {
+	Address string
+	Err     error
+}
+
+func (e *PrinterError) Error() string {
+	return fmt.Sprintf("printer %s tidak bisa dihubungi: %v", e.Address, e.Err)
+}
+
+func (e *PrinterError) Is(target error) bool {
+	return target == ErrPrinterUnavailable
+}
+
+func (e *PrinterError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,51 @@
+package models
+
+const (
+	PaymentMethodCash         = "cash"
+	PaymentMethodDebitCard    = "debit_card"
+	PaymentMethodQRIS         = "qris"
+	PaymentMethodBankTransfer = "bank_transfer"
+	PaymentMethodEWallet      = "e_wallet"
+)
+
+// PaymentMethods - metode pembayaran yang diterima kasir
+var PaymentMethods = []string{
+	PaymentMethodCash,
+	PaymentMethodDebitCard,
+	PaymentMethodQRIS,
+	PaymentMethodBankTransfer,
+	PaymentMethodEWallet,
+}
+
+func IsValidPaymentMethod(method string) bool {
+	for _, m := range PaymentMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Payment - satu tender pada transaksi.
+// TenderedAmount adalah uang yang diserahkan pelanggan, Amount adalah bagian yang dipakai
+// untuk membayar (untuk cash = tendered - kembalian).
+type Payment struct {
+	ID             int    `json:"id"`
+	TransactionID  int    `json:"transaction_id"`
+	Method         string `json:"method"`
+	Amount         int    `json:"amount"`
+	TenderedAmount int    `json:"tendered_amount"`
+	Reference      string `json:"reference,omitempty"`
+}
+
+type PaymentInput struct {
+	Method    string `json:"method"`
+	Amount    int    `json:"amount"`
+	Reference string `json:"reference,omitempty"` // no. approval EDC / QRIS / transfer
+}
+
+type PaymentMethodTotal struct {
+	Method         string `json:"method"`
+	TotalTransaksi int    `json:"total_transaksi"`
+	Amount         int    `json:"amount"`
+}
@@ -1,6 +1,7 @@
 package models
 
 type ProductCategory struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	TaxRate *float64 `json:"tax_rate"` // persen, null = tarif default
 }
@@ -3,8 +3,51 @@ package models
 type Product struct {
 	ID         int              `json:"id"`
 	Name       string           `json:"name"`
+	SKU        string           `json:"sku"`      // kode internal toko, unik, kosong = belum diberi SKU
+	Barcodes   []string         `json:"barcodes"` // EAN-13 / UPC / kode lain yang dibaca scanner, unik antar produk
 	Price      int              `json:"price"`
 	Stock      int              `json:"stock"`
 	CategoryID *int             `json:"category_id"` // FK (nullable)
 	Category   *ProductCategory `json:"category,omitempty"`
+	TaxRate    *float64         `json:"tax_rate"` // persen, null = ikut kategori / tarif default
+	TaxExempt  bool             `json:"tax_exempt"`
+	Active     bool             `json:"active"` // false = sudah dihapus (soft delete), riwayat stok dan transaksi tetap ada
+
+	// Varian (ukuran, warna, rasa): produk induk punya Options, setiap varian adalah produk
+	// biasa dengan SKU, harga dan stok sendiri yang menunjuk induknya lewat ParentID.
+	// Induk yang punya varian tidak bisa dijual langsung.
+	ParentID     *int            `json:"parent_id"`
+	Options      []ProductOption `json:"options"`            // induk: dimensi varian, mis. Ukuran: S, M, L
+	OptionValues []string        `json:"option_values"`      // varian: satu nilai per dimensi, urutan sama dengan Options induk
+	Variants     []Product       `json:"variants,omitempty"` // diisi GetByID untuk produk induk
+
+	// Resep produk komposit (mis. Es Kopi Susu = kopi 18 g + susu 150 ml + 1 cup). Checkout
+	// mengurangi stok komponen, stok produk komposit sendiri tidak dipakai.
+	Recipe []RecipeItem `json:"recipe"`
+
+	ChangedBy int `json:"-"` // user yang login, dicatat di ledger sebagai pembuat stok awal
+}
+
+// RecipeItem - kebutuhan satu komponen untuk 1 produk komposit, quantity dalam satuan stok
+// komponen. ComponentName hanya diisi saat dibaca.
+type RecipeItem struct {
+	ComponentID   int    `json:"component_id"`
+	ComponentName string `json:"component_name"`
+	Quantity      int    `json:"quantity"`
+}
+
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// Format label produk, PDF selalu berupa lembar A4 berisi banyak label
+const (
+	LabelFormatSVG = "svg"
+	LabelFormatPNG = "png"
+)
+
+// GenerateBarcodesRequest - product_ids kosong = semua produk yang belum punya barcode
+type GenerateBarcodesRequest struct {
+	ProductIDs []int `json:"product_ids"`
 }
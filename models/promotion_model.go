@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+const (
+	PromotionTypePercentage     = "percentage"      // diskon persen per item
+	PromotionTypeFixed          = "fixed"           // potongan nominal per unit
+	PromotionTypeCartPercentage = "cart_percentage" // diskon persen total belanja, dengan minimal belanja
+	PromotionTypeCartFixed      = "cart_fixed"      // potongan nominal total belanja, dengan minimal belanja
+	PromotionTypeBuyXGetY       = "buy_x_get_y"     // beli X gratis Y (produk yang sama)
+	PromotionTypeBundle         = "bundle"          // paket beberapa produk dengan harga khusus
+)
+
+var PromotionTypes = []string{
+	PromotionTypePercentage,
+	PromotionTypeFixed,
+	PromotionTypeCartPercentage,
+	PromotionTypeCartFixed,
+	PromotionTypeBuyXGetY,
+	PromotionTypeBundle,
+}
+
+// Promotion - aturan promo yang otomatis dievaluasi saat checkout.
+// Promo dievaluasi urut Priority terbesar dulu, lalu ID terkecil.
+type Promotion struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	// percentage / cart_percentage: persen (1-100), fixed / cart_fixed: nominal rupiah
+	Value int `json:"value"`
+	// cart_*: minimal total belanja setelah diskon item
+	MinSpend int `json:"min_spend"`
+	// cart_percentage: batas maksimal potongan, 0 = tanpa batas
+	MaxDiscount int `json:"max_discount"`
+
+	// percentage / fixed / buy_x_get_y: produk yang berlaku (kosong = semua produk / sesuai kategori)
+	ProductIDs []int `json:"product_ids"`
+	CategoryID *int  `json:"category_id"`
+
+	BuyQuantity int `json:"buy_quantity"`
+	GetQuantity int `json:"get_quantity"`
+
+	BundleItems []PromotionBundleItem `json:"bundle_items"`
+	BundlePrice int                   `json:"bundle_price"`
+
+	Priority  int        `json:"priority"`
+	Active    bool       `json:"active"`
+	StartsAt  *time.Time `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type PromotionBundleItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// AppliedPromotion - promo yang dipakai pada transaksi. ProductID kosong untuk promo total belanja.
+type AppliedPromotion struct {
+	PromotionID    *int   `json:"promotion_id"` // null jika promo sudah dihapus
+	PromotionName  string `json:"promotion_name"`
+	ProductID      *int   `json:"product_id,omitempty"`
+	DiscountAmount int    `json:"discount_amount"`
+}
+
+type PromotionTotal struct {
+	PromotionID    *int   `json:"promotion_id"`
+	PromotionName  string `json:"promotion_name"`
+	TotalTransaksi int    `json:"total_transaksi"`
+	DiscountAmount int    `json:"discount_amount"`
+}
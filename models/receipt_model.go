@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// ReceiptNumberConfig - format nomor struk {Prefix}/{OutletCode}/{YYYYMMDD}/{urutan},
+// contoh INV/OUTLET1/20261018/0001. Urutan mulai dari 1 setiap hari per outlet dan tidak boleh bolong.
+type ReceiptNumberConfig struct {
+	Prefix     string
+	OutletCode string
+	Digits     int // lebar urutan dengan nol di depan, contoh 4 = 0001
+}
+
+const (
+	ReceiptFormatText = "txt"
+	ReceiptFormatHTML = "html"
+	ReceiptFormatPDF  = "pdf"
+	// ReceiptFormatESCPOS - byte stream mentah untuk printer thermal
+	ReceiptFormatESCPOS = "escpos"
+)
+
+// Lebar kertas struk (mm) yang didukung layout teks
+const (
+	ReceiptWidth58 = 58
+	ReceiptWidth80 = 80
+)
+
+// Template bawaan header / footer struk (text/template, data = Receipt). Baris yang kosong
+// setelah dirender tidak dicetak.
+const (
+	DefaultReceiptHeaderTemplate = "{{.StoreName}}\n{{.StoreAddress}}\n{{if .StorePhone}}Telp {{.StorePhone}}{{end}}\n{{if .StoreNPWP}}NPWP {{.StoreNPWP}}{{end}}"
+	DefaultReceiptFooterTemplate = "Terima kasih atas kunjungan Anda"
+)
+
+// ReceiptStoreConfig - identitas toko dan template header / footer struk
+type ReceiptStoreConfig struct {
+	StoreName      string
+	StoreAddress   string
+	StorePhone     string
+	StoreNPWP      string
+	HeaderTemplate string
+	FooterTemplate string
+}
+
+// Receipt - isi struk satu transaksi, siap dirender ke txt / html / pdf
+type Receipt struct {
+	StoreName    string
+	StoreAddress string
+	StorePhone   string
+	StoreNPWP    string
+	Header       []string // hasil HeaderTemplate
+	Footer       []string // hasil FooterTemplate
+
+	TransactionID int
+	ReceiptNumber string
+	CreatedAt     time.Time
+	CashierName   string
+	TerminalName  string
+	Status        string
+
+	Items            []ReceiptItem
+	Discounts        []ReceiptDiscount // promo level keranjang (tanpa produk)
+	Subtotal         int
+	DiscountAmount   int
+	PriceIncludesTax bool
+	TaxBase          int
+	TaxAmount        int
+	ServiceCharge    int
+	TotalAmount      int
+	Payments         []ReceiptPayment
+	ChangeAmount     int
+	RefundedAmount   int
+}
+
+type ReceiptItem struct {
+	Name      string
+	Quantity  int
+	Price     int
+	Subtotal  int
+	Discounts []ReceiptDiscount
+}
+
+type ReceiptDiscount struct {
+	Name   string
+	Amount int
+}
+
+type ReceiptPayment struct {
+	Method    string // label untuk dicetak, contoh "Tunai"
+	Amount    int    // untuk tunai = uang yang diserahkan
+	Reference string
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+type Refund struct {
+	ID            int          `json:"id"`
+	TransactionID int          `json:"transaction_id"`
+	Reason        string       `json:"reason"`
+	TotalAmount   int          `json:"total_amount"`
+	TaxAmount     int          `json:"tax_amount"`  // bagian PPN dari total_amount
+	CashAmount    int          `json:"cash_amount"` // bagian total_amount yang dikembalikan tunai dari laci
+	ShiftID       *int         `json:"shift_id"`    // shift yang laci kasnya dipakai, null jika tanpa uang tunai
+	CreatedAt     time.Time    `json:"created_at"`
+	Items         []RefundItem `json:"items"`
+}
+
+type RefundItem struct {
+	ID                  int    `json:"id"`
+	RefundID            int    `json:"refund_id"`
+	TransactionDetailID int    `json:"transaction_detail_id"`
+	ProductID           int    `json:"product_id"`
+	ProductName         string `json:"product_name"`
+	Quantity            int    `json:"quantity"`
+	Amount              int    `json:"amount"`
+	TaxAmount           int    `json:"tax_amount"`
+}
+
+type VoidRequest struct {
+	Reason string `json:"reason"`
+
+	VoidedBy int `json:"-"` // dari user yang login
+}
+
+type RefundItemRequest struct {
+	TransactionDetailID int `json:"transaction_detail_id"`
+	Quantity            int `json:"quantity"`
+}
+
+// RefundRequest - items kosong berarti refund penuh untuk semua sisa item.
+// TerminalID - terminal tempat uang tunai dikembalikan, default terminal transaksi.
+type RefundRequest struct {
+	Reason     string              `json:"reason"`
+	Items      []RefundItemRequest `json:"items"`
+	TerminalID int                 `json:"terminal_id,omitempty"`
+
+	CreatedBy int `json:"-"` // dari user yang login
+}
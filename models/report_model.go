@@ -1,18 +1,138 @@
 package models
 
+import "time"
+
 type BestSellingProduct struct {
-	Nama      string `json:"nama"`
-	QtyTerjual int   `json:"qty_terjual"`
+	Nama       string `json:"nama"`
+	QtyTerjual int    `json:"qty_terjual"`
 }
 
+// GrossSales    - total penjualan (harga x quantity) sebelum diskon dari transaksi yang tidak di-void
+// TotalDiskon   - total potongan promo
+// DPP           - dasar pengenaan pajak
+// TotalPajak    - PPN keluaran dari penjualan
+// ServiceCharge - total service charge
+// TotalRefund   - total refund yang dibuat pada periode laporan (PajakRefund = bagian PPN-nya)
+// NetRevenue    - total yang dibayar pelanggan dikurangi TotalRefund
 type DailyReport struct {
-	TotalRevenue      int                  `json:"total_revenue"`
-	TotalTransaksi    int                  `json:"total_transaksi"`
-	ProdukTerlaris    BestSellingProduct   `json:"produk_terlaris"`
+	GrossSales     int                  `json:"gross_sales"`
+	TotalDiskon    int                  `json:"total_diskon"`
+	DPP            int                  `json:"dpp"`
+	TotalPajak     int                  `json:"total_pajak"`
+	ServiceCharge  int                  `json:"service_charge"`
+	TotalRefund    int                  `json:"total_refund"`
+	PajakRefund    int                  `json:"pajak_refund"`
+	NetRevenue     int                  `json:"net_revenue"`
+	TotalTransaksi int                  `json:"total_transaksi"`
+	ProdukTerlaris BestSellingProduct   `json:"produk_terlaris"`
+	Pembayaran     []PaymentMethodTotal `json:"pembayaran"`
+	Promosi        []PromotionTotal     `json:"promosi"`
+	Pajak          []TaxRateTotal       `json:"pajak"`
 }
 
 type DateRangeReport struct {
-	TotalRevenue      int                  `json:"total_revenue"`
-	TotalTransaksi    int                  `json:"total_transaksi"`
-	ProdukTerlaris    BestSellingProduct   `json:"produk_terlaris"`
+	GrossSales     int                  `json:"gross_sales"`
+	TotalDiskon    int                  `json:"total_diskon"`
+	DPP            int                  `json:"dpp"`
+	TotalPajak     int                  `json:"total_pajak"`
+	ServiceCharge  int                  `json:"service_charge"`
+	TotalRefund    int                  `json:"total_refund"`
+	PajakRefund    int                  `json:"pajak_refund"`
+	NetRevenue     int                  `json:"net_revenue"`
+	TotalTransaksi int                  `json:"total_transaksi"`
+	ProdukTerlaris BestSellingProduct   `json:"produk_terlaris"`
+	Pembayaran     []PaymentMethodTotal `json:"pembayaran"`
+	Promosi        []PromotionTotal     `json:"promosi"`
+	Pajak          []TaxRateTotal       `json:"pajak"`
+}
+
+// CashierSalesReport - penjualan per kasir per terminal, untuk menelusuri selisih kas.
+// Transaksi dihitung berdasarkan tanggal dibuat, refund berdasarkan tanggal refund.
+// CashierID / TerminalID null untuk transaksi lama sebelum fitur login.
+//
+// NetSales   - total dibayar pelanggan dari transaksi yang tidak di-void
+// TotalVoid  - jumlah transaksi yang di-void (VoidAmount = nilainya)
+// CashAmount - pembayaran tunai dari transaksi yang tidak di-void, seharusnya ada di laci
+type CashierSalesReport struct {
+	CashierID      *int   `json:"cashier_id"`
+	CashierName    string `json:"cashier_name"`
+	TerminalID     *int   `json:"terminal_id"`
+	TerminalName   string `json:"terminal_name"`
+	TotalTransaksi int    `json:"total_transaksi"`
+	GrossSales     int    `json:"gross_sales"`
+	TotalDiskon    int    `json:"total_diskon"`
+	NetSales       int    `json:"net_sales"`
+	TotalRefund    int    `json:"total_refund"`
+	TotalVoid      int    `json:"total_void"`
+	VoidAmount     int    `json:"void_amount"`
+	CashAmount     int    `json:"cash_amount"`
+}
+
+const (
+	EndOfDayReportX = "X" // laporan tengah hari, tidak menutup hari
+	EndOfDayReportZ = "Z" // laporan tutup hari, setelahnya tanggal tersebut dikunci
+)
+
+// EndOfDayReport - snapshot laporan satu tanggal bisnis yang disimpan dan tidak bisa diubah.
+// Angka penjualan sama dengan DateRangeReport untuk tanggal tersebut, ditambah void,
+// total per kategori dan nomor transaksi / struk pertama dan terakhir (termasuk yang di-void).
+type EndOfDayReport struct {
+	ID           int       `json:"id"`
+	Type         string    `json:"type"` // X | Z
+	BusinessDate time.Time `json:"business_date"`
+	Sequence     int       `json:"sequence"` // nomor urut per tanggal dan tipe
+
+	DateRangeReport
+	NetSales           int                  `json:"net_sales"` // total dibayar pelanggan sebelum refund
+	TotalVoid          int                  `json:"total_void"`
+	VoidAmount         int                  `json:"void_amount"`
+	FirstTransactionID *int                 `json:"first_transaction_id"`
+	LastTransactionID  *int                 `json:"last_transaction_id"`
+	FirstReceiptNumber string               `json:"first_receipt_number"`
+	LastReceiptNumber  string               `json:"last_receipt_number"`
+	Kategori           []CategorySalesTotal `json:"kategori"`
+
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CategorySalesTotal - penjualan per kategori produk (snapshot kategori saat transaksi).
+// CategoryID null untuk produk yang tidak punya kategori saat dijual.
+type CategorySalesTotal struct {
+	CategoryID     *int   `json:"category_id"`
+	CategoryName   string `json:"category_name"`
+	Quantity       int    `json:"quantity"`
+	GrossSales     int    `json:"gross_sales"`
+	DiscountAmount int    `json:"discount_amount"`
+	NetSales       int    `json:"net_sales"` // termasuk pajak dan service charge
+}
+
+// ProductSalesTotal - penjualan per produk dari transaksi yang tidak di-void. Varian dijumlahkan
+// ke produk induknya, rinciannya di Variants. Nama mengikuti nama produk saat laporan dibuat.
+type ProductSalesTotal struct {
+	ProductID      int                 `json:"product_id"`
+	ProductName    string              `json:"product_name"`
+	Quantity       int                 `json:"quantity"`
+	GrossSales     int                 `json:"gross_sales"`
+	DiscountAmount int                 `json:"discount_amount"`
+	NetSales       int                 `json:"net_sales"` // termasuk pajak dan service charge
+	Variants       []ProductSalesTotal `json:"variants,omitempty"`
+}
+
+// ComponentConsumption - pemakaian komponen resep pada periode laporan. Quantity dari transaksi
+// yang tidak di-void (tanggal transaksi), Returned dari refund (tanggal refund), dalam satuan stok komponen.
+type ComponentConsumption struct {
+	ComponentID   int    `json:"component_id"`
+	ComponentName string `json:"component_name"`
+	Quantity      int    `json:"quantity"`
+	Returned      int    `json:"returned"`
+	NetQuantity   int    `json:"net_quantity"` // quantity - returned
+	Stock         int    `json:"stock"`        // stok saat laporan dibuat, 0 jika komponen sudah dihapus
+}
+
+// EndOfDayRequest - BusinessDate kosong berarti hari ini
+type EndOfDayRequest struct {
+	BusinessDate string `json:"business_date"` // YYYY-MM-DD
+
+	CreatedBy int `json:"-"` // dari user yang login
 }
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Permission - izin untuk satu kelompok aksi API. Dicek middleware di setiap route.
+const (
+	PermissionProductRead       = "products:read"
+	PermissionProductWrite      = "products:write" // tambah produk, ubah harga / stok
+	PermissionProductDelete     = "products:delete"
+	PermissionCategoryRead      = "categories:read"
+	PermissionCategoryWrite     = "categories:write"
+	PermissionCheckout          = "checkout"
+	PermissionTransactionRead   = "transactions:read"
+	PermissionTransactionVoid   = "transactions:void"
+	PermissionTransactionRefund = "transactions:refund"
+	PermissionPromotionRead     = "promotions:read"
+	PermissionPromotionWrite    = "promotions:write"
+	PermissionReportRead        = "reports:read"
+	PermissionReportClose       = "reports:close" // Z report, mengunci transaksi hari tersebut
+	PermissionUserManage        = "users:manage"
+	PermissionRoleManage        = "roles:manage"
+	PermissionTerminalManage    = "terminals:manage"
+	PermissionShiftOperate      = "shifts:operate" // buka / tutup shift, paid-in / paid-out
+)
+
+var Permissions = []string{
+	PermissionProductRead,
+	PermissionProductWrite,
+	PermissionProductDelete,
+	PermissionCategoryRead,
+	PermissionCategoryWrite,
+	PermissionCheckout,
+	PermissionTransactionRead,
+	PermissionTransactionVoid,
+	PermissionTransactionRefund,
+	PermissionPromotionRead,
+	PermissionPromotionWrite,
+	PermissionReportRead,
+	PermissionReportClose,
+	PermissionUserManage,
+	PermissionRoleManage,
+	PermissionTerminalManage,
+	PermissionShiftOperate,
+}
+
+func IsValidPermission(permission string) bool {
+	return slices.Contains(Permissions, permission)
+}
+
+// Role - kumpulan izin. Role bawaan (cashier, supervisor, owner) tidak bisa dihapus,
+// dan owner selalu punya semua izin supaya tidak ada yang terkunci dari pengaturan role.
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	BuiltIn     bool      `json:"built_in"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DefaultRoles - role bawaan, sama dengan seed di migrasi 0003_roles (+ izin yang ditambahkan migrasi setelahnya)
+func DefaultRoles() []Role {
+	cashier := []string{
+		PermissionProductRead,
+		PermissionCategoryRead,
+		PermissionPromotionRead,
+		PermissionCheckout,
+		PermissionTransactionRead,
+		PermissionShiftOperate,
+	}
+	supervisor := append(slices.Clone(cashier),
+		PermissionProductWrite,
+		PermissionCategoryWrite,
+		PermissionPromotionWrite,
+		PermissionTransactionVoid,
+		PermissionTransactionRefund,
+		PermissionReportRead,
+		PermissionReportClose,
+	)
+
+	return []Role{
+		{Name: RoleCashier, Description: "Kasir", Permissions: cashier, BuiltIn: true},
+		{Name: RoleSupervisor, Description: "Supervisor toko", Permissions: supervisor, BuiltIn: true},
+		{Name: RoleOwner, Description: "Pemilik, semua akses", Permissions: slices.Clone(Permissions), BuiltIn: true},
+	}
+}
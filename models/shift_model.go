@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+const (
+	ShiftStatusOpen   = "open"
+	ShiftStatusClosed = "closed"
+
+	CashMovementPaidIn  = "paid_in"  // uang masuk laci di luar penjualan, misal tambahan uang kembalian
+	CashMovementPaidOut = "paid_out" // uang keluar laci di luar refund, misal bayar kurir
+)
+
+// Shift - sesi laci kas satu kasir di satu terminal. Selama shift terbuka semua transaksi
+// di terminal tersebut masuk ke shift ini. Saat ditutup kasir menghitung isi laci
+// (counted_cash) dan selisihnya dengan expected_cash disimpan sebagai variance.
+type Shift struct {
+	ID           int    `json:"id"`
+	TerminalID   int    `json:"terminal_id"`
+	CashierID    int    `json:"cashier_id"`
+	Status       string `json:"status"`
+	OpeningFloat int    `json:"opening_float"` // modal awal di laci
+
+	TotalTransaksi int `json:"total_transaksi"`
+	CashSales      int `json:"cash_sales"` // pembayaran cash setelah kembalian, transaksi void tidak dihitung
+	CashRefunds    int `json:"cash_refunds"`
+	PaidIn         int `json:"paid_in"`
+	PaidOut        int `json:"paid_out"`
+	// ExpectedCash - opening_float + cash_sales - cash_refunds + paid_in - paid_out
+	ExpectedCash int  `json:"expected_cash"`
+	CountedCash  *int `json:"counted_cash"`
+	Variance     *int `json:"variance"` // counted_cash - expected_cash, minus berarti kurang
+
+	ClosingNote   string         `json:"closing_note,omitempty"`
+	ClosedBy      *int           `json:"closed_by"`
+	OpenedAt      time.Time      `json:"opened_at"`
+	ClosedAt      *time.Time     `json:"closed_at"`
+	CashMovements []CashMovement `json:"cash_movements,omitempty"`
+}
+
+type CashMovement struct {
+	ID        int       `json:"id"`
+	ShiftID   int       `json:"shift_id"`
+	Type      string    `json:"type"` // paid_in | paid_out
+	Amount    int       `json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OpenShiftRequest struct {
+	TerminalID   int `json:"terminal_id"`
+	OpeningFloat int `json:"opening_float"`
+
+	CashierID int `json:"-"` // dari user yang login
+}
+
+type CloseShiftRequest struct {
+	CountedCash *int   `json:"counted_cash"`
+	Note        string `json:"note"`
+
+	ClosedBy int `json:"-"` // dari user yang login
+}
+
+type CashMovementRequest struct {
+	Type   string `json:"type"`
+	Amount int    `json:"amount"`
+	Reason string `json:"reason"`
+
+	CreatedBy int `json:"-"` // dari user yang login
+}
+
+// ShiftFilter - filter untuk GET /api/shifts
+type ShiftFilter struct {
+	TerminalID *int
+	CashierID  *int
+	Status     string
+}
@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// Jenis pergerakan stok. Sale, void dan refund dicatat otomatis oleh transaksi, initial saat
+// produk dibuat, sisanya dicatat manual lewat POST /api/products/{id}/stock-movements.
+const (
+	StockMovementInitial    = "initial"    // stok awal produk baru / saat ledger mulai dipakai
+	StockMovementSale       = "sale"       // checkout, termasuk komponen resep
+	StockMovementVoid       = "void"       // transaksi di-void, stok kembali
+	StockMovementRefund     = "refund"     // item direfund, stok kembali
+	StockMovementAdjustment = "adjustment" // koreksi manual (rusak, hilang, salah hitung)
+	StockMovementReceiving  = "receiving"  // barang masuk dari supplier
+	StockMovementTransfer   = "transfer"   // pindah gudang / outlet, quantity negatif = keluar
+	StockMovementOpname     = "opname"     // hitung fisik, quantity = selisih terhadap stok sistem
+)
+
+// Dokumen sumber pergerakan stok otomatis
+const (
+	StockReferenceTransaction = "transaction"
+	StockReferenceRefund      = "refund"
+)
+
+// StockMovement - satu baris ledger stok, tidak pernah diubah setelah dicatat. Jumlah Quantity
+// seluruh pergerakan satu produk selalu sama dengan stok produk tersebut.
+type StockMovement struct {
+	ID            int       `json:"id"`
+	ProductID     int       `json:"product_id"`
+	Type          string    `json:"type"`
+	Quantity      int       `json:"quantity"` // perubahan stok, negatif = keluar
+	Balance       int       `json:"balance"`  // stok setelah perubahan
+	ReferenceType string    `json:"reference_type"`
+	ReferenceID   *int      `json:"reference_id"`
+	Reference     string    `json:"reference"` // nomor dokumen: nomor struk, surat jalan, berita acara opname
+	Note          string    `json:"note"`
+	CreatedBy     *int      `json:"created_by"` // null untuk saldo awal migrasi atau user yang sudah dihapus
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StockMovementRequest - pergerakan manual. Quantity untuk adjustment / receiving / transfer,
+// Counted (stok hasil hitung fisik) untuk opname.
+type StockMovementRequest struct {
+	Type      string `json:"type"`
+	Quantity  int    `json:"quantity"`
+	Counted   *int   `json:"counted"`
+	Reference string `json:"reference"`
+	Note      string `json:"note"`
+
+	ProductID int `json:"-"` // dari URL
+	CreatedBy int `json:"-"` // dari user yang login
+}
+
+type StockMovementFilter struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+	Type      string
+	Page      int
+	Limit     int
+}
+
+// StockMovementList - terbaru dulu
+type StockMovementList struct {
+	Data  []StockMovement `json:"data"`
+	Page  int             `json:"page"`
+	Limit int             `json:"limit"`
+	Total int             `json:"total"`
+}
+
+// StockDiscrepancy - produk yang stoknya tidak sama dengan hasil hitung ulang ledger.
+// LastBalance = balance pergerakan terakhir (null jika belum ada pergerakan).
+type StockDiscrepancy struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Stock       int    `json:"stock"`
+	LedgerStock int    `json:"ledger_stock"` // jumlah quantity seluruh pergerakan
+	LastBalance *int   `json:"last_balance"`
+	Difference  int    `json:"difference"` // stock - ledger_stock
+}
+
+// StockCheck - hasil pengecekan konsistensi stok terhadap ledger
+type StockCheck struct {
+	CheckedProducts int                `json:"checked_products"`
+	Consistent      bool               `json:"consistent"`
+	Discrepancies   []StockDiscrepancy `json:"discrepancies"`
+}
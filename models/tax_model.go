@@ -0,0 +1,23 @@
+package models
+
+const (
+	// TaxModeExclusive - harga produk belum termasuk PPN, pajak ditambahkan di atas harga
+	TaxModeExclusive = "exclusive"
+	// TaxModeInclusive - harga produk sudah termasuk PPN, pajak dihitung mundur dari harga
+	TaxModeInclusive = "inclusive"
+)
+
+// TaxConfig - pengaturan pajak outlet.
+// Tarif per produk (products.tax_rate) mengalahkan tarif kategori, tarif kategori mengalahkan DefaultRate.
+// Produk dengan tax_exempt = true tidak dikenai PPN.
+type TaxConfig struct {
+	Mode              string  // exclusive | inclusive
+	DefaultRate       float64 // persen, contoh 11
+	ServiceChargeRate float64 // persen dari DPP, 0 = tanpa service charge
+}
+
+type TaxRateTotal struct {
+	Rate      float64 `json:"rate"`
+	TaxBase   int     `json:"dpp"`
+	TaxAmount int     `json:"pajak"`
+}
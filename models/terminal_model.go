@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Terminal - mesin kasir / register. Terminal yang sudah dipakai transaksi tidak dihapus,
+// cukup dinonaktifkan supaya riwayat tetap bisa ditelusuri.
+type Terminal struct {
+	ID     int    `json:"id"`
+	Code   string `json:"code"` // kode unik, misalnya "KASIR-01"
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+	// PrinterAddress - printer thermal ESC/POS jaringan "host:port" (default port 9100),
+	// kosong = struk tidak dicetak otomatis
+	PrinterAddress string    `json:"printer_address"`
+	PrinterWidth   int       `json:"printer_width"` // lebar kertas mm: 58 | 80
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -2,27 +2,114 @@ package models
 
 import "time"
 
+const (
+	TransactionStatusCompleted         = "completed"
+	TransactionStatusVoided            = "voided"
+	TransactionStatusPartiallyRefunded = "partially_refunded"
+	TransactionStatusRefunded          = "refunded"
+)
+
+// Mode lock stok saat checkout (CHECKOUT_LOCK_MODE)
+const (
+	// CheckoutLockPessimistic - baris produk di-lock (SELECT ... FOR UPDATE) sebelum stok dicek
+	CheckoutLockPessimistic = "pessimistic"
+	// CheckoutLockOptimistic - tanpa lock di awal, UPDATE stok bersyarat menolak checkout yang kalah balapan
+	CheckoutLockOptimistic = "optimistic"
+)
+
 type Transaction struct {
-	ID          int                 `json:"id"`
-	TotalAmount int                 `json:"total_amount"`
-	CreatedAt   time.Time           `json:"created_at"`
-	Details     []TransactionDetail `json:"details"`
+	ID             int    `json:"id"`
+	ReceiptNumber  string `json:"receipt_number"`  // kosong untuk transaksi sebelum ada nomor struk
+	SubtotalAmount int    `json:"subtotal_amount"` // sebelum diskon
+	DiscountAmount int    `json:"discount_amount"`
+	// PriceIncludesTax - true jika harga produk sudah termasuk PPN saat transaksi dibuat
+	PriceIncludesTax bool                `json:"price_includes_tax"`
+	TaxBase          int                 `json:"tax_base"` // DPP
+	TaxAmount        int                 `json:"tax_amount"`
+	ServiceCharge    int                 `json:"service_charge"`
+	TotalAmount      int                 `json:"total_amount"`
+	PaidAmount       int                 `json:"paid_amount"`
+	ChangeAmount     int                 `json:"change_amount"`
+	Status           string              `json:"status"`
+	RefundedAmount   int                 `json:"refunded_amount"`
+	VoidReason       string              `json:"void_reason,omitempty"`
+	VoidedAt         *time.Time          `json:"voided_at,omitempty"`
+	CashierID        *int                `json:"cashier_id"`  // null untuk transaksi sebelum ada login
+	TerminalID       *int                `json:"terminal_id"` // null untuk transaksi sebelum ada login
+	ShiftID          *int                `json:"shift_id"`    // null untuk transaksi sebelum ada shift
+	CreatedAt        time.Time           `json:"created_at"`
+	Details          []TransactionDetail `json:"details,omitempty"`
+	Promotions       []AppliedPromotion  `json:"promotions,omitempty"`
+	Payments         []Payment           `json:"payments,omitempty"`
+	Refunds          []Refund            `json:"refunds,omitempty"`
 }
 
 type TransactionDetail struct {
-	ID            int    `json:"id"`
-	TransactionID int    `json:"transaction_id"`
-	ProductID     int    `json:"product_id"`
-	ProductName   string `json:"product_name,omitempty"`
-	Quantity      int    `json:"quantity"`
-	Subtotal      int    `json:"subtotal"`
+	ID               int     `json:"id"`
+	TransactionID    int     `json:"transaction_id"`
+	ProductID        int     `json:"product_id"`
+	ProductName      string  `json:"product_name"`  // snapshot saat transaksi
+	ProductPrice     int     `json:"product_price"` // snapshot harga satuan saat transaksi
+	CategoryID       *int    `json:"category_id"`   // snapshot kategori saat transaksi
+	CategoryName     string  `json:"category_name"`
+	ParentID         *int    `json:"parent_id"` // snapshot induk varian saat transaksi
+	ParentName       string  `json:"parent_name"`
+	Quantity         int     `json:"quantity"`
+	RefundedQuantity int     `json:"refunded_quantity"`
+	Subtotal         int     `json:"subtotal"` // product_price * quantity
+	DiscountAmount   int     `json:"discount_amount"`
+	NetAmount        int     `json:"net_amount"` // subtotal - discount_amount
+	TaxRate          float64 `json:"tax_rate"`
+	TaxBase          int     `json:"tax_base"`
+	TaxAmount        int     `json:"tax_amount"`
+	ServiceCharge    int     `json:"service_charge"`
+	TotalAmount      int     `json:"total_amount"` // yang dibayar untuk baris ini
+
+	Promotions []AppliedPromotion `json:"promotions,omitempty"`
+	Components []RecipeItem       `json:"components,omitempty"` // produk komposit: resep per 1 item saat transaksi
 }
 
+// CheckoutItem - produk dipilih lewat salah satu dari product_id, barcode atau sku.
+// Barcode / sku diganti product_id oleh service sebelum transaksi disimpan.
 type CheckoutItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID int    `json:"product_id"`
+	Barcode   string `json:"barcode,omitempty"`
+	SKU       string `json:"sku,omitempty"`
+	Quantity  int    `json:"quantity"`
 }
 
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
+	TerminalID int            `json:"terminal_id"`
+	Items      []CheckoutItem `json:"items"`
+	Payments   []PaymentInput `json:"payments"`
+
+	CashierID int `json:"-"` // dari user yang login, bukan dari body
+
+	IdempotencyKey string `json:"-"` // dari header Idempotency-Key
+	RequestHash    string `json:"-"` // sha256 payload yang sudah dinormalisasi
+}
+
+// TransactionFilter - filter, urutan dan paginasi untuk GET /api/transactions
+type TransactionFilter struct {
+	StartDate     *time.Time
+	EndDate       *time.Time
+	MinAmount     *int
+	MaxAmount     *int
+	ProductID     *int
+	CashierID     *int
+	TerminalID    *int
+	ShiftID       *int
+	ReceiptNumber string // cocok sebagian, tidak peduli huruf besar/kecil
+	Status        string
+	SortBy        string // created_at | total_amount | id
+	SortDesc      bool
+	Page          int
+	Limit         int
+}
+
+type TransactionList struct {
+	Data  []Transaction `json:"data"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+	Total int           `json:"total"`
 }
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Role bawaan. Role lain bisa dibuat lewat /api/roles.
+const (
+	RoleCashier    = "cashier"
+	RoleSupervisor = "supervisor"
+	RoleOwner      = "owner"
+)
+
+// User - akun kasir / supervisor / owner. Hash password dan PIN tidak pernah dikirim ke client.
+type User struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"username"`
+	Name         string    `json:"name"`
+	Role         string    `json:"role"`
+	Active       bool      `json:"active"`
+	HasPIN       bool      `json:"has_pin"`
+	CreatedAt    time.Time `json:"created_at"`
+	PasswordHash string    `json:"-"`
+	PINHash      string    `json:"-"`
+
+	// Permissions - izin tambahan khusus user ini, di luar izin role-nya
+	Permissions []string `json:"permissions"`
+	// EffectivePermissions - izin role + izin tambahan, diisi saat login / autentikasi
+	EffectivePermissions []string `json:"effective_permissions,omitempty"`
+}
+
+func (u *User) Can(permission string) bool {
+	return slices.Contains(u.EffectivePermissions, permission)
+}
+
+// UserInput - body create/update user. Password / PIN kosong saat update berarti tidak diubah.
+type UserInput struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	Active   *bool  `json:"active"`
+	Password string `json:"password"`
+	PIN      string `json:"pin"`
+
+	// Permissions - izin tambahan, nil saat update berarti tidak diubah
+	Permissions []string `json:"permissions"`
+}
+
+// Session - satu login. Token disimpan sebagai hash SHA-256, token asli hanya ada di client.
+type Session struct {
+	ID               int        `json:"id"`
+	UserID           int        `json:"user_id"`
+	TokenHash        string     `json:"-"`
+	RefreshTokenHash string     `json:"-"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RefreshExpiresAt time.Time  `json:"refresh_expires_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+}
+
+// LoginRequest - login dengan username + password, atau username + PIN untuk kasir
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	PIN      string `json:"pin"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// AuthTokens - response login dan refresh
+type AuthTokens struct {
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	User             User      `json:"user"`
+}
+
+// AuthConfig - masa berlaku token dan batas percobaan login
+type AuthConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// MaxLoginAttempts gagal dalam LoginWindow per username (dan 4x lipatnya per IP)
+	// membuat login diblokir sampai window berakhir
+	MaxLoginAttempts int
+	LoginWindow      time.Duration
+}
@@ -0,0 +1,350 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Perhitungan checkout yang tidak bergantung pada database, dipakai implementasi
+// Postgres dan in-memory supaya hasilnya identik.
+
+// MaxTransactionAmount - nominal maksimal subtotal / total per baris dan per transaksi, di bawah
+// batas kolom INT Postgres (2^31-1)
+const MaxTransactionAmount = 1_000_000_000
+
+// buildTransaction - hitung promo, pajak, service charge dan pembayaran untuk keranjang
+// yang stoknya sudah dicek. products berisi data produk yang sudah di-lock, items
+// diasumsikan sudah dinormalisasi service (satu baris per product_id).
+func buildTransaction(req models.CheckoutRequest, products map[int]lockedProduct, promotions []models.Promotion, cfg models.TaxConfig) (*models.Transaction, error) {
+	lines := make([]cartLine, len(req.Items))
+	for i, item := range req.Items {
+		product := products[item.ProductID]
+		lines[i] = cartLine{
+			productID:  item.ProductID,
+			categoryID: product.categoryID,
+			price:      product.price,
+			quantity:   item.Quantity,
+		}
+	}
+
+	cashierID, terminalID := req.CashierID, req.TerminalID
+	t := &models.Transaction{
+		CashierID:        &cashierID,
+		TerminalID:       &terminalID,
+		Status:           models.TransactionStatusCompleted,
+		PriceIncludesTax: cfg.Mode == models.TaxModeInclusive,
+		Promotions:       applyPromotions(lines, promotions),
+		Details:          make([]models.TransactionDetail, 0, len(req.Items)),
+	}
+
+	for i, item := range req.Items {
+		product := products[item.ProductID]
+		line := lines[i]
+		lt := calculateLineTax(line.net(), effectiveTaxRate(product, cfg), cfg)
+		if line.subtotal() > MaxTransactionAmount || lt.total > MaxTransactionAmount {
+			return nil, models.NewValidationError("items", fmt.Sprintf("total %s melebihi batas %d per baris", product.name, MaxTransactionAmount))
+		}
+
+		t.SubtotalAmount += line.subtotal()
+		t.DiscountAmount += line.discount
+		t.TaxBase += lt.base
+		t.TaxAmount += lt.tax
+		t.ServiceCharge += lt.serviceCharge
+		t.TotalAmount += lt.total
+
+		t.Details = append(t.Details, models.TransactionDetail{
+			ProductID:      item.ProductID,
+			ProductName:    product.name,
+			ProductPrice:   product.price,
+			CategoryID:     product.categoryID,
+			CategoryName:   product.category,
+			ParentID:       product.parentID,
+			ParentName:     product.parentName,
+			Quantity:       item.Quantity,
+			Subtotal:       line.subtotal(),
+			DiscountAmount: line.discount,
+			NetAmount:      line.net(),
+			TaxRate:        lt.rate,
+			TaxBase:        lt.base,
+			TaxAmount:      lt.tax,
+			ServiceCharge:  lt.serviceCharge,
+			TotalAmount:    lt.total,
+			Promotions:     promotionsForProduct(t.Promotions, item.ProductID),
+			Components:     product.recipe,
+		})
+	}
+
+	if t.SubtotalAmount > MaxTransactionAmount || t.TotalAmount > MaxTransactionAmount {
+		return nil, models.NewValidationError("items", fmt.Sprintf("total belanja melebihi batas %d per transaksi", MaxTransactionAmount))
+	}
+
+	payments, changeAmount, err := allocatePayments(t.TotalAmount, req.Payments)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Payments = payments
+	t.ChangeAmount = changeAmount
+	t.PaidAmount = t.TotalAmount + changeAmount
+
+	return t, nil
+}
+
+// allocatePayments - cek total tender >= total belanja dan hitung kembalian.
+// Kembalian hanya bisa dari cash, jadi tender non-tunai tidak boleh melebihi total.
+func allocatePayments(totalAmount int, inputs []models.PaymentInput) ([]models.Payment, int, error) {
+	cash, nonCash := 0, 0
+	for _, in := range inputs {
+		if in.Method == models.PaymentMethodCash {
+			cash += in.Amount
+		} else {
+			nonCash += in.Amount
+		}
+	}
+
+	if cash+nonCash < totalAmount {
+		return nil, 0, &models.ValidationError{Errors: []models.FieldError{{
+			Field:   "payments",
+			Message: fmt.Sprintf("total pembayaran %d kurang dari total belanja %d", cash+nonCash, totalAmount),
+		}}}
+	}
+
+	if nonCash > totalAmount {
+		return nil, 0, &models.ValidationError{Errors: []models.FieldError{{
+			Field:   "payments",
+			Message: fmt.Sprintf("pembayaran non-tunai %d melebihi total belanja %d", nonCash, totalAmount),
+		}}}
+	}
+
+	change := cash + nonCash - totalAmount
+
+	payments := make([]models.Payment, len(inputs))
+	for i, in := range inputs {
+		payments[i] = models.Payment{
+			Method:         in.Method,
+			Amount:         in.Amount,
+			TenderedAmount: in.Amount,
+			Reference:      in.Reference,
+		}
+	}
+
+	// kembalian dikurangkan dari tender cash, mulai dari yang terakhir
+	remaining := change
+	for i := len(payments) - 1; i >= 0 && remaining > 0; i-- {
+		if payments[i].Method != models.PaymentMethodCash {
+			continue
+		}
+		deduct := min(remaining, payments[i].Amount)
+		payments[i].Amount -= deduct
+		remaining -= deduct
+	}
+
+	return payments, change, nil
+}
+
+// promotionsForProduct - promo item yang berlaku untuk satu produk (promo total belanja tidak ikut)
+func promotionsForProduct(applied []models.AppliedPromotion, productID int) []models.AppliedPromotion {
+	var result []models.AppliedPromotion
+	for _, ap := range applied {
+		if ap.ProductID != nil && *ap.ProductID == productID {
+			result = append(result, ap)
+		}
+	}
+	return result
+}
+
+// checkSellable - produk yang sudah dihapus tidak bisa dijual. Produk induk yang punya varian
+// tidak punya stok sendiri, yang dijual variannya.
+func checkSellable(productIDs []int, products map[int]lockedProduct) error {
+	for _, id := range productIDs {
+		p := products[id]
+		if !p.active {
+			return models.NewValidationError("items", fmt.Sprintf("produk %s sudah dihapus", p.name))
+		}
+		if p.hasVariants {
+			return models.NewValidationError("items", fmt.Sprintf("produk %s punya varian, pilih salah satu varian", p.name))
+		}
+	}
+	return nil
+}
+
+// stockRequirements - stok yang dikurangi checkout per produk, urut id. Produk biasa dikurangi
+// stoknya sendiri, produk komposit dikurangi stok komponennya (quantity x resep). Komponen yang
+// juga dibeli langsung dijumlahkan.
+func stockRequirements(productIDs []int, products map[int]lockedProduct, requested map[int]int) ([]int, map[int]int) {
+	needed := make(map[int]int)
+	for _, id := range productIDs {
+		p := products[id]
+		if len(p.recipe) == 0 {
+			needed[id] += requested[id]
+			continue
+		}
+		for _, item := range p.recipe {
+			needed[item.ComponentID] += requested[id] * item.Quantity
+		}
+	}
+
+	stockIDs := make([]int, 0, len(needed))
+	for id := range needed {
+		stockIDs = append(stockIDs, id)
+	}
+	sort.Ints(stockIDs)
+	return stockIDs, needed
+}
+
+// addReturnedStock - stok yang kembali saat quantity item baris d di-void / direfund, produk
+// komposit mengembalikan komponen sesuai resep saat transaksi
+func addReturnedStock(returned map[int]int, d models.TransactionDetail, quantity int) {
+	if len(d.Components) == 0 {
+		returned[d.ProductID] += quantity
+		return
+	}
+	for _, c := range d.Components {
+		returned[c.ComponentID] += quantity * c.Quantity
+	}
+}
+
+func stockShortages(productIDs []int, products map[int]lockedProduct, requested map[int]int) []models.StockShortage {
+	shortages := make([]models.StockShortage, 0)
+	for _, id := range productIDs {
+		p := products[id]
+		if p.stock < requested[id] {
+			shortages = append(shortages, models.StockShortage{
+				ProductID:   id,
+				ProductName: p.name,
+				Requested:   requested[id],
+				Available:   p.stock,
+			})
+		}
+	}
+	return shortages
+}
+
+// proportional - bagian amount untuk quantity unit berikutnya setelah done unit dari total unit.
+// Dihitung kumulatif supaya jumlah semua bagian selalu sama dengan amount.
+func proportional(amount, done, quantity, total int) int {
+	return amount*(done+quantity)/total - amount*done/total
+}
+
+// checkVoidable - void hanya untuk transaksi hari ini yang belum pernah direfund dan
+// shift-nya belum ditutup (uang tunai sudah dihitung di penutupan shift)
+func checkVoidable(status string, isToday, shiftClosed bool) error {
+	switch {
+	case status == models.TransactionStatusVoided:
+		return &models.ConflictError{Message: "transaksi sudah di-void"}
+	case status != models.TransactionStatusCompleted:
+		return &models.ConflictError{Message: "transaksi yang sudah direfund tidak bisa di-void"}
+	case !isToday:
+		return &models.ConflictError{Message: "void hanya untuk transaksi hari ini, gunakan refund"}
+	case shiftClosed:
+		return errVoidShiftClosed
+	}
+	return nil
+}
+
+// refundCashAmount - bagian refund yang dikembalikan tunai. Uang tunai dikembalikan lebih dulu
+// sampai sebesar pembayaran cash transaksi yang belum direfund, sisanya lewat metode non-tunai asal.
+func refundCashAmount(total, cashPaid, cashRefunded int) int {
+	return max(0, min(total, cashPaid-cashRefunded))
+}
+
+// formatReceiptNumber - nomor struk ke-n pada tanggal bisnis day, contoh INV/OUTLET1/20261018/0001
+func formatReceiptNumber(cfg models.ReceiptNumberConfig, day time.Time, n int) string {
+	return fmt.Sprintf("%s/%s/%s/%0*d", cfg.Prefix, cfg.OutletCode, day.Format("20060102"), cfg.Digits, n)
+}
+
+// finishShiftTotals - isi expected_cash dan variance dari total yang sudah dihitung.
+// Shift yang sudah ditutup memakai expected_cash yang disimpan saat penutupan.
+func finishShiftTotals(s *models.Shift, storedExpected *int) {
+	s.ExpectedCash = s.OpeningFloat + s.CashSales - s.CashRefunds + s.PaidIn - s.PaidOut
+	if storedExpected != nil {
+		s.ExpectedCash = *storedExpected
+	}
+
+	s.Variance = nil
+	if s.CountedCash != nil {
+		variance := *s.CountedCash - s.ExpectedCash
+		s.Variance = &variance
+	}
+}
+
+// buildRefund - susun dokumen refund dari detail transaksi (lengkap dengan refunded_quantity).
+// req.Items kosong berarti refund semua sisa item. Nominal per baris dihitung proporsional dari
+// total baris (setelah diskon, termasuk pajak dan service charge) secara kumulatif, sehingga
+// jumlah beberapa refund parsial tidak pernah melebihi total baris karena pembulatan.
+// Mengembalikan quantity yang kembali ke stok per produk dan status transaksi setelah refund.
+func buildRefund(transactionID int, req models.RefundRequest, details []models.TransactionDetail) (*models.Refund, map[int]int, string, error) {
+	detailByID := make(map[int]models.TransactionDetail)
+	for _, d := range details {
+		detailByID[d.ID] = d
+	}
+
+	lines := req.Items
+	if len(lines) == 0 {
+		for _, d := range details {
+			if remaining := d.Quantity - d.RefundedQuantity; remaining > 0 {
+				lines = append(lines, models.RefundItemRequest{TransactionDetailID: d.ID, Quantity: remaining})
+			}
+		}
+		if len(lines) == 0 {
+			return nil, nil, "", &models.ConflictError{Message: "semua item transaksi sudah direfund"}
+		}
+	}
+
+	var errs []models.FieldError
+	refund := &models.Refund{TransactionID: transactionID, Reason: req.Reason, Items: make([]models.RefundItem, 0, len(lines))}
+	returned := make(map[int]int)
+
+	for i, line := range lines {
+		d, ok := detailByID[line.TransactionDetailID]
+		if !ok {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].transaction_detail_id", i),
+				Message: "item tidak ada di transaksi ini",
+			})
+			continue
+		}
+
+		if remaining := d.Quantity - d.RefundedQuantity; line.Quantity > remaining {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].quantity", i),
+				Message: fmt.Sprintf("maksimal %d yang bisa direfund", remaining),
+			})
+			continue
+		}
+
+		amount := proportional(d.TotalAmount, d.RefundedQuantity, line.Quantity, d.Quantity)
+		taxAmount := proportional(d.TaxAmount, d.RefundedQuantity, line.Quantity, d.Quantity)
+		refund.TotalAmount += amount
+		refund.TaxAmount += taxAmount
+		addReturnedStock(returned, d, line.Quantity)
+
+		d.RefundedQuantity += line.Quantity
+		detailByID[d.ID] = d
+
+		refund.Items = append(refund.Items, models.RefundItem{
+			TransactionDetailID: d.ID,
+			ProductID:           d.ProductID,
+			ProductName:         d.ProductName,
+			Quantity:            line.Quantity,
+			Amount:              amount,
+			TaxAmount:           taxAmount,
+		})
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, "", &models.ValidationError{Errors: errs}
+	}
+
+	status := models.TransactionStatusRefunded
+	for _, d := range detailByID {
+		if d.RefundedQuantity < d.Quantity {
+			status = models.TransactionStatusPartiallyRefunded
+			break
+		}
+	}
+
+	return refund, returned, status, nil
+}
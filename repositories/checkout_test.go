@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAllocatePayments(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int
+		inputs  []models.PaymentInput
+		amounts []int // nominal yang dicatat per tender
+		change  int
+		wantErr bool
+	}{
+		{
+			name:    "cash pas",
+			total:   50000,
+			inputs:  []models.PaymentInput{{Method: models.PaymentMethodCash, Amount: 50000}},
+			amounts: []int{50000},
+		},
+		{
+			name:    "cash dengan kembalian",
+			total:   37500,
+			inputs:  []models.PaymentInput{{Method: models.PaymentMethodCash, Amount: 50000}},
+			amounts: []int{37500},
+			change:  12500,
+		},
+		{
+			name:  "split cash dan QRIS, kembalian dari cash",
+			total: 80000,
+			inputs: []models.PaymentInput{
+				{Method: models.PaymentMethodQRIS, Amount: 50000},
+				{Method: models.PaymentMethodCash, Amount: 50000},
+			},
+			amounts: []int{50000, 30000},
+			change:  20000,
+		},
+		{
+			name:    "kurang bayar",
+			total:   80000,
+			inputs:  []models.PaymentInput{{Method: models.PaymentMethodCash, Amount: 50000}},
+			wantErr: true,
+		},
+		{
+			name:    "non-tunai melebihi total",
+			total:   80000,
+			inputs:  []models.PaymentInput{{Method: models.PaymentMethodDebitCard, Amount: 100000}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments, change, err := allocatePayments(tt.total, tt.inputs)
+			if tt.wantErr {
+				var ve *models.ValidationError
+				if !errors.As(err, &ve) {
+					t.Fatalf("err = %v, want ValidationError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if change != tt.change {
+				t.Errorf("change = %d, want %d", change, tt.change)
+			}
+			for i, want := range tt.amounts {
+				if payments[i].Amount != want {
+					t.Errorf("payments[%d].Amount = %d, want %d", i, payments[i].Amount, want)
+				}
+				if payments[i].TenderedAmount != tt.inputs[i].Amount {
+					t.Errorf("payments[%d].TenderedAmount = %d, want %d", i, payments[i].TenderedAmount, tt.inputs[i].Amount)
+				}
+			}
+		})
+	}
+}
+
+func TestProportionalNeverExceedsTotal(t *testing.T) {
+	// refund 1 + 1 + 1 dari 3 unit harus pas dengan total baris
+	amount, done := 10000, 0
+	sum := 0
+	for range 3 {
+		sum += proportional(amount, done, 1, 3)
+		done++
+	}
+
+	if sum != amount {
+		t.Errorf("total refund = %d, want %d", sum, amount)
+	}
+}
+
+func TestFormatReceiptNumber(t *testing.T) {
+	day := time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		cfg  models.ReceiptNumberConfig
+		n    int
+		want string
+	}{
+		{name: "nomor pertama", cfg: models.ReceiptNumberConfig{Prefix: "INV", OutletCode: "OUTLET1", Digits: 4}, n: 1, want: "INV/OUTLET1/20261018/0001"},
+		{name: "lebih panjang dari digits", cfg: models.ReceiptNumberConfig{Prefix: "INV", OutletCode: "OUTLET1", Digits: 4}, n: 12345, want: "INV/OUTLET1/20261018/12345"},
+		{name: "tanpa padding", cfg: models.ReceiptNumberConfig{Prefix: "STR", OutletCode: "BDG", Digits: 1}, n: 7, want: "STR/BDG/20261018/7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatReceiptNumber(tt.cfg, day, tt.n); got != tt.want {
+				t.Errorf("formatReceiptNumber = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRefund(t *testing.T) {
+	details := []models.TransactionDetail{
+		{ID: 1, ProductID: 10, Quantity: 2, TotalAmount: 20000, TaxAmount: 1982},
+		{ID: 2, ProductID: 11, Quantity: 1, RefundedQuantity: 1, TotalAmount: 5000},
+	}
+
+	tests := []struct {
+		name    string
+		req     models.RefundRequest
+		total   int
+		status  string
+		wantErr bool
+	}{
+		{
+			name:   "refund semua sisa item",
+			req:    models.RefundRequest{Reason: "rusak"},
+			total:  20000,
+			status: models.TransactionStatusRefunded,
+		},
+		{
+			name:   "refund sebagian",
+			req:    models.RefundRequest{Reason: "rusak", Items: []models.RefundItemRequest{{TransactionDetailID: 1, Quantity: 1}}},
+			total:  10000,
+			status: models.TransactionStatusPartiallyRefunded,
+		},
+		{
+			name:    "melebihi sisa quantity",
+			req:     models.RefundRequest{Reason: "rusak", Items: []models.RefundItemRequest{{TransactionDetailID: 2, Quantity: 1}}},
+			wantErr: true,
+		},
+		{
+			name:    "detail bukan milik transaksi",
+			req:     models.RefundRequest{Reason: "rusak", Items: []models.RefundItemRequest{{TransactionDetailID: 99, Quantity: 1}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refund, returned, status, err := buildRefund(5, tt.req, details)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if refund.TotalAmount != tt.total {
+				t.Errorf("TotalAmount = %d, want %d", refund.TotalAmount, tt.total)
+			}
+			if status != tt.status {
+				t.Errorf("status = %q, want %q", status, tt.status)
+			}
+			if returned[10] != tt.total/10000 {
+				t.Errorf("returned[10] = %d, want %d", returned[10], tt.total/10000)
+			}
+		})
+	}
+
+	// detail asli tidak boleh ikut berubah
+	if details[0].RefundedQuantity != 0 {
+		t.Errorf("details[0].RefundedQuantity = %d, want 0", details[0].RefundedQuantity)
+	}
+}
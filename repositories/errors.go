@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// kode error Postgres yang diterjemahkan ke error domain
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+)
+
+func isPostgresError(err error, code string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == code
+}
+
+func isForeignKeyViolation(err error) bool {
+	return isPostgresError(err, pgForeignKeyViolation)
+}
+
+func isUniqueViolation(err error) bool {
+	return isPostgresError(err, pgUniqueViolation)
+}
+
+// isUniqueViolationOn - unique violation pada constraint / unique index tertentu, untuk tabel
+// yang punya lebih dari satu unique key
+func isUniqueViolationOn(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == pgUniqueViolation && pqErr.Constraint == constraint
+}
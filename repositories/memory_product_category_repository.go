@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"sort"
+)
+
+type MemoryProductCategoryRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryProductCategoryRepository(store *MemoryStore) *MemoryProductCategoryRepository {
+	return &MemoryProductCategoryRepository{store: store}
+}
+
+func (repo *MemoryProductCategoryRepository) GetAll() ([]models.ProductCategory, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	categories := make([]models.ProductCategory, 0, len(s.categories))
+	for _, c := range s.categories {
+		categories = append(categories, c)
+	}
+
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+	return categories, nil
+}
+
+func (repo *MemoryProductCategoryRepository) Create(productCategory *models.ProductCategory) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	productCategory.ID = s.nextID("product_categories")
+	s.categories[productCategory.ID] = *productCategory
+	return nil
+}
+
+func (repo *MemoryProductCategoryRepository) GetByID(id int) (*models.ProductCategory, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.categories[id]
+	if !ok {
+		return nil, errProductCategoryNotFound
+	}
+	return &c, nil
+}
+
+func (repo *MemoryProductCategoryRepository) Update(productCategory *models.ProductCategory) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.categories[productCategory.ID]; !ok {
+		return errProductCategoryNotFound
+	}
+
+	s.categories[productCategory.ID] = *productCategory
+	return nil
+}
+
+func (repo *MemoryProductCategoryRepository) Delete(id int) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.categories[id]; !ok {
+		return errProductCategoryNotFound
+	}
+
+	delete(s.categories, id)
+
+	// ON DELETE SET NULL
+	for pid, p := range s.products {
+		if p.CategoryID != nil && *p.CategoryID == id {
+			p.CategoryID = nil
+			s.products[pid] = p
+		}
+	}
+	for pid, p := range s.promotions {
+		if p.CategoryID != nil && *p.CategoryID == id {
+			p.CategoryID = nil
+			s.promotions[pid] = p
+		}
+	}
+
+	return nil
+}
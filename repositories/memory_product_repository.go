@@ -0,0 +1,340 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type MemoryProductRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryProductRepository(store *MemoryStore) *MemoryProductRepository {
+	return &MemoryProductRepository{store: store}
+}
+
+func (repo *MemoryProductRepository) GetAll(name string) ([]models.Product, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	products := []models.Product{}
+	for _, p := range s.products {
+		if !p.Active {
+			continue
+		}
+		if name != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(name)) {
+			continue
+		}
+		products = append(products, s.productWithCategory(p))
+	}
+
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products, nil
+}
+
+func (repo *MemoryProductRepository) Create(product *models.Product) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkCategory(product.CategoryID); err != nil {
+		return err
+	}
+	if err := s.checkProductCodes(product, 0); err != nil {
+		return err
+	}
+	if err := s.checkRecipe(product); err != nil {
+		return err
+	}
+
+	product.ID = s.nextID("products")
+	product.Category = nil
+	product.Active = true
+	s.products[product.ID] = storedProduct(product)
+	if m := initialStockMovement(product); m != nil {
+		s.addStockMovement(m)
+	}
+	return nil
+}
+
+func (repo *MemoryProductRepository) GetByID(id int) (*models.Product, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.products[id]
+	if !ok {
+		return nil, errProductNotFound
+	}
+
+	p = s.productWithCategory(p)
+	if p.ParentID == nil {
+		for _, id := range s.variantIDs(p.ID) {
+			p.Variants = append(p.Variants, s.productWithCategory(s.products[id]))
+		}
+	}
+	return &p, nil
+}
+
+// GetByBarcode - seperti Postgres, lookup hanya mencari produk aktif
+func (repo *MemoryProductRepository) GetByBarcode(barcode string) (*models.Product, error) {
+	return repo.find(func(p models.Product) bool { return slices.Contains(p.Barcodes, barcode) })
+}
+
+func (repo *MemoryProductRepository) GetBySKU(sku string) (*models.Product, error) {
+	return repo.find(func(p models.Product) bool { return sku != "" && p.SKU == sku })
+}
+
+func (repo *MemoryProductRepository) IDsByBarcode(barcodes []string) (map[string]int, error) {
+	return repo.idsByCode(barcodes, func(p models.Product) []string { return p.Barcodes })
+}
+
+func (repo *MemoryProductRepository) IDsBySKU(skus []string) (map[string]int, error) {
+	return repo.idsByCode(skus, func(p models.Product) []string { return []string{p.SKU} })
+}
+
+func (repo *MemoryProductRepository) idsByCode(codes []string, productCodes func(models.Product) []string) (map[string]int, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make(map[string]int, len(codes))
+	for _, p := range s.products {
+		if !p.Active {
+			continue
+		}
+		for _, code := range productCodes(p) {
+			if code != "" && slices.Contains(codes, code) {
+				ids[code] = p.ID
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (repo *MemoryProductRepository) find(match func(models.Product) bool) (*models.Product, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.products {
+		if p.Active && match(p) {
+			p = s.productWithCategory(p)
+			return &p, nil
+		}
+	}
+	return nil, errProductNotFound
+}
+
+func (repo *MemoryProductRepository) Update(product *models.Product) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.products[product.ID]
+	if !ok {
+		return errProductNotFound
+	}
+
+	if err := s.checkCategory(product.CategoryID); err != nil {
+		return err
+	}
+	if err := s.checkProductCodes(product, product.ID); err != nil {
+		return err
+	}
+	if err := s.checkRecipe(product); err != nil {
+		return err
+	}
+
+	// stok hanya berubah lewat checkout / stock_movements dan active lewat Delete, sama seperti
+	// UPDATE tanpa kolom stock dan active
+	stored := storedProduct(product)
+	stored.Stock, stored.Active = existing.Stock, existing.Active
+	s.products[product.ID] = stored
+	return nil
+}
+
+func (repo *MemoryProductRepository) AddBarcodes(barcodes map[int]string) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// cek semua dulu supaya tidak ada yang tersimpan sebagian, seperti rollback
+	seen := make(map[string]bool, len(barcodes))
+	for id, barcode := range barcodes {
+		if _, ok := s.products[id]; !ok {
+			return errProductNotFound
+		}
+		if seen[barcode] {
+			return errProductBarcodeTaken
+		}
+		seen[barcode] = true
+		if err := s.checkProductCodes(&models.Product{Barcodes: []string{barcode}}, 0); err != nil {
+			return err
+		}
+	}
+
+	for id, barcode := range barcodes {
+		p := s.products[id]
+		p.Barcodes = append(slices.Clone(p.Barcodes), barcode)
+		s.products[id] = storedProduct(&p)
+	}
+	return nil
+}
+
+// Delete - soft delete seperti Postgres, produk tetap ada dengan active = false
+func (repo *MemoryProductRepository) Delete(id int) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.products[id]
+	switch {
+	case !ok:
+		return errProductNotFound
+	case !p.Active:
+		return errProductDeleted
+	case len(s.variantIDs(id)) > 0:
+		return errProductHasVariants
+	case s.isActiveComponent(id):
+		return errProductIsComponent
+	}
+
+	p.Active = false
+	s.products[id] = p
+	return nil
+}
+
+// storedProduct - salinan produk untuk disimpan, tanpa objek category dan barcode selalu terurut
+// seperti array_agg(... ORDER BY barcode)
+func storedProduct(product *models.Product) models.Product {
+	p := *product
+	p.Category = nil
+	p.Barcodes = slices.Clone(p.Barcodes)
+	if p.Barcodes == nil {
+		p.Barcodes = []string{}
+	}
+	slices.Sort(p.Barcodes)
+	p.Variants = nil
+	p.Recipe = make([]models.RecipeItem, len(product.Recipe))
+	for i, item := range product.Recipe {
+		p.Recipe[i] = models.RecipeItem{ComponentID: item.ComponentID, Quantity: item.Quantity}
+	}
+	slices.SortFunc(p.Recipe, func(a, b models.RecipeItem) int { return a.ComponentID - b.ComponentID })
+	return cloneProductOptions(p)
+}
+
+// cloneProductOptions - salinan options / option_values supaya data di store tidak ikut berubah
+func cloneProductOptions(p models.Product) models.Product {
+	options := make([]models.ProductOption, len(p.Options))
+	for i, o := range p.Options {
+		options[i] = models.ProductOption{Name: o.Name, Values: slices.Clone(o.Values)}
+	}
+	p.Options = options
+	p.OptionValues = append([]string{}, p.OptionValues...)
+	return p
+}
+
+// variantIDs - id varian aktif produk induk, urut seperti ORDER BY id
+func (s *MemoryStore) variantIDs(parentID int) []int {
+	var ids []int
+	for _, p := range s.products {
+		if p.Active && p.ParentID != nil && *p.ParentID == parentID {
+			ids = append(ids, p.ID)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// productWithCategory - isi objek category seperti LEFT JOIN product_categories, nama komponen
+// resep seperti JOIN products
+func (s *MemoryStore) productWithCategory(p models.Product) models.Product {
+	p = cloneProductOptions(p)
+	p.Barcodes = slices.Clone(p.Barcodes)
+	p.Recipe = slices.Clone(p.Recipe)
+	for i := range p.Recipe {
+		p.Recipe[i].ComponentName = s.products[p.Recipe[i].ComponentID].Name
+	}
+	p.Category = nil
+	if p.CategoryID != nil {
+		if c, ok := s.categories[*p.CategoryID]; ok {
+			p.Category = &c
+		}
+	}
+	return p
+}
+
+// checkCategory - sama seperti foreign key products.category_id
+func (s *MemoryStore) checkCategory(categoryID *int) error {
+	if categoryID == nil {
+		return nil
+	}
+	if _, ok := s.categories[*categoryID]; !ok {
+		return errInvalidProductCategory
+	}
+	return nil
+}
+
+// checkProductCodes - sama seperti unique products.sku, products_variant_options_key dan primary
+// key product_barcodes
+func (s *MemoryStore) checkProductCodes(product *models.Product, exceptID int) error {
+	for _, p := range s.products {
+		if p.ID == exceptID {
+			continue
+		}
+		if product.SKU != "" && p.SKU == product.SKU {
+			return errProductSKUTaken
+		}
+		if product.ParentID != nil && p.Active && intPtrEqual(p.ParentID, *product.ParentID) && slices.Equal(p.OptionValues, product.OptionValues) {
+			return errVariantOptionsTaken
+		}
+		for _, barcode := range product.Barcodes {
+			if slices.Contains(p.Barcodes, barcode) {
+				return errProductBarcodeTaken
+			}
+		}
+	}
+	return nil
+}
+
+// checkRecipe - sama seperti foreign key product_components.component_id, dan produk yang dipakai
+// sebagai komponen tidak bisa punya resep
+func (s *MemoryStore) checkRecipe(product *models.Product) error {
+	for _, item := range product.Recipe {
+		if _, ok := s.products[item.ComponentID]; !ok {
+			return errInvalidComponent
+		}
+	}
+	if len(product.Recipe) > 0 && product.ID != 0 && s.isComponent(product.ID) {
+		return errProductIsComponent
+	}
+	return nil
+}
+
+// isComponent - produk dipakai di resep produk lain
+func (s *MemoryStore) isComponent(productID int) bool {
+	return s.usedAsComponent(productID, false)
+}
+
+// isActiveComponent - produk dipakai di resep produk lain yang belum dihapus
+func (s *MemoryStore) isActiveComponent(productID int) bool {
+	return s.usedAsComponent(productID, true)
+}
+
+func (s *MemoryStore) usedAsComponent(productID int, activeOnly bool) bool {
+	for _, p := range s.products {
+		if activeOnly && !p.Active {
+			continue
+		}
+		for _, item := range p.Recipe {
+			if item.ComponentID == productID {
+				return true
+			}
+		}
+	}
+	return false
+}
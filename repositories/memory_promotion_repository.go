@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"sort"
+	"time"
+)
+
+type MemoryPromotionRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryPromotionRepository(store *MemoryStore) *MemoryPromotionRepository {
+	return &MemoryPromotionRepository{store: store}
+}
+
+func (repo *MemoryPromotionRepository) GetAll() ([]models.Promotion, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	promotions := make([]models.Promotion, 0, len(s.promotions))
+	for _, p := range s.promotions {
+		promotions = append(promotions, clonePromotion(p))
+	}
+
+	sort.Slice(promotions, func(i, j int) bool {
+		if promotions[i].Priority != promotions[j].Priority {
+			return promotions[i].Priority > promotions[j].Priority
+		}
+		return promotions[i].ID < promotions[j].ID
+	})
+	return promotions, nil
+}
+
+func (repo *MemoryPromotionRepository) Create(promotion *models.Promotion) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	promotion.ID = s.nextID("promotions")
+	promotion.CreatedAt = s.Now()
+	s.promotions[promotion.ID] = clonePromotion(*promotion)
+	return nil
+}
+
+func (repo *MemoryPromotionRepository) GetByID(id int) (*models.Promotion, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.promotions[id]
+	if !ok {
+		return nil, &models.NotFoundError{Message: "promo tidak ditemukan"}
+	}
+
+	p = clonePromotion(p)
+	return &p, nil
+}
+
+func (repo *MemoryPromotionRepository) Update(promotion *models.Promotion) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.promotions[promotion.ID]
+	if !ok {
+		return &models.NotFoundError{Message: "promo tidak ditemukan"}
+	}
+
+	promotion.CreatedAt = existing.CreatedAt
+	s.promotions[promotion.ID] = clonePromotion(*promotion)
+	return nil
+}
+
+func (repo *MemoryPromotionRepository) Delete(id int) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.promotions[id]; !ok {
+		return &models.NotFoundError{Message: "promo tidak ditemukan"}
+	}
+
+	delete(s.promotions, id)
+
+	// transaction_promotions.promotion_id ON DELETE SET NULL, nama promo tetap tersimpan
+	for _, t := range s.transactions {
+		for i, ap := range t.Promotions {
+			if ap.PromotionID != nil && *ap.PromotionID == id {
+				t.Promotions[i].PromotionID = nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// activePromotions - padanan activePromotions(q) untuk store in-memory
+func (s *MemoryStore) activePromotions(now time.Time) []models.Promotion {
+	promotions := make([]models.Promotion, 0)
+	for _, p := range s.promotions {
+		if !p.Active {
+			continue
+		}
+		if p.StartsAt != nil && p.StartsAt.After(now) {
+			continue
+		}
+		if p.EndsAt != nil && !p.EndsAt.After(now) {
+			continue
+		}
+		promotions = append(promotions, clonePromotion(p))
+	}
+	return promotions
+}
+
+func clonePromotion(p models.Promotion) models.Promotion {
+	p.ProductIDs = append([]int{}, p.ProductIDs...)
+	p.BundleItems = append([]models.PromotionBundleItem{}, p.BundleItems...)
+	return p
+}
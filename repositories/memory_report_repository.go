@@ -0,0 +1,533 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"sort"
+	"time"
+)
+
+type MemoryReportRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryReportRepository(store *MemoryStore) *MemoryReportRepository {
+	return &MemoryReportRepository{store: store}
+}
+
+func (repo *MemoryReportRepository) GetDailyReport() (*models.DailyReport, error) {
+	today := dateOf(repo.store.Now())
+
+	report, err := repo.GetReportByDateRange(today, today)
+	if err != nil {
+		return nil, err
+	}
+
+	daily := models.DailyReport(*report)
+	return &daily, nil
+}
+
+// GetReportByDateRange - agregasi yang sama dengan query Postgres: transaksi void tidak dihitung,
+// refund dihitung berdasarkan tanggal refund dibuat.
+func (repo *MemoryReportRepository) GetReportByDateRange(startDate, endDate time.Time) (*models.DateRangeReport, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reportByDateRange(startDate, endDate), nil
+}
+
+// reportByDateRange - harus dipanggil saat mutex dipegang
+func (s *MemoryStore) reportByDateRange(startDate, endDate time.Time) *models.DateRangeReport {
+	inRange := func(t time.Time) bool {
+		d := dateOf(t)
+		return !d.Before(dateOf(startDate)) && !d.After(dateOf(endDate))
+	}
+
+	// productSold - nama diambil dari detail terbaru, padanan ARRAY_AGG(... ORDER BY td.id DESC)[1]
+	type productSold struct {
+		qty          int
+		name         string
+		lastDetailID int
+	}
+	type promotionKey struct {
+		id   int // 0 jika promo sudah dihapus
+		name string
+	}
+
+	report := &models.DateRangeReport{
+		Pembayaran: make([]models.PaymentMethodTotal, 0),
+		Promosi:    make([]models.PromotionTotal, 0),
+		Pajak:      make([]models.TaxRateTotal, 0),
+	}
+
+	totalAmount := 0
+	sold := make(map[int]*productSold)
+	payments := make(map[string]*models.PaymentMethodTotal)
+	promotions := make(map[promotionKey]*models.PromotionTotal)
+	taxes := make(map[float64]*models.TaxRateTotal)
+
+	ids := make([]int, 0, len(s.transactions))
+	for id := range s.transactions {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		t := s.transactions[id]
+
+		for _, r := range t.Refunds {
+			if inRange(r.CreatedAt) {
+				report.TotalRefund += r.TotalAmount
+				report.PajakRefund += r.TaxAmount
+			}
+		}
+
+		if t.Status == models.TransactionStatusVoided || !inRange(t.CreatedAt) {
+			continue
+		}
+
+		report.GrossSales += t.SubtotalAmount
+		report.TotalDiskon += t.DiscountAmount
+		report.DPP += t.TaxBase
+		report.TotalPajak += t.TaxAmount
+		report.ServiceCharge += t.ServiceCharge
+		report.TotalTransaksi++
+		totalAmount += t.TotalAmount
+
+		for _, d := range t.Details {
+			parent := detailParent(d)
+			id, name := parent.ProductID, parent.ProductName
+			ps, ok := sold[id]
+			if !ok {
+				ps = &productSold{}
+				sold[id] = ps
+			}
+			ps.qty += d.Quantity
+			if d.ID > ps.lastDetailID {
+				ps.name, ps.lastDetailID = name, d.ID
+			}
+
+			tr, ok := taxes[d.TaxRate]
+			if !ok {
+				tr = &models.TaxRateTotal{Rate: d.TaxRate}
+				taxes[d.TaxRate] = tr
+			}
+			tr.TaxBase += d.TaxBase
+			tr.TaxAmount += d.TaxAmount
+		}
+
+		counted := make(map[string]bool)
+		for _, p := range t.Payments {
+			pm, ok := payments[p.Method]
+			if !ok {
+				pm = &models.PaymentMethodTotal{Method: p.Method}
+				payments[p.Method] = pm
+			}
+			if !counted[p.Method] {
+				pm.TotalTransaksi++
+				counted[p.Method] = true
+			}
+			pm.Amount += p.Amount
+		}
+
+		countedPromo := make(map[promotionKey]bool)
+		for _, ap := range t.Promotions {
+			key := promotionKey{name: ap.PromotionName}
+			if ap.PromotionID != nil {
+				key.id = *ap.PromotionID
+			}
+			pt, ok := promotions[key]
+			if !ok {
+				pt = &models.PromotionTotal{PromotionID: ap.PromotionID, PromotionName: ap.PromotionName}
+				promotions[key] = pt
+			}
+			if !countedPromo[key] {
+				pt.TotalTransaksi++
+				countedPromo[key] = true
+			}
+			pt.DiscountAmount += ap.DiscountAmount
+		}
+	}
+
+	report.NetRevenue = totalAmount - report.TotalRefund
+
+	// produk terlaris, jika seri dipilih product_id terkecil supaya deterministik
+	best := 0
+	for id, ps := range sold {
+		if ps.qty > report.ProdukTerlaris.QtyTerjual ||
+			(ps.qty == report.ProdukTerlaris.QtyTerjual && id < best) {
+			best = id
+			report.ProdukTerlaris = models.BestSellingProduct{Nama: ps.name, QtyTerjual: ps.qty}
+		}
+	}
+
+	for _, pm := range payments {
+		report.Pembayaran = append(report.Pembayaran, *pm)
+	}
+	sort.Slice(report.Pembayaran, func(i, j int) bool {
+		return report.Pembayaran[i].Method < report.Pembayaran[j].Method
+	})
+
+	for _, pt := range promotions {
+		report.Promosi = append(report.Promosi, *pt)
+	}
+	sort.Slice(report.Promosi, func(i, j int) bool {
+		if report.Promosi[i].DiscountAmount != report.Promosi[j].DiscountAmount {
+			return report.Promosi[i].DiscountAmount > report.Promosi[j].DiscountAmount
+		}
+		return report.Promosi[i].PromotionName < report.Promosi[j].PromotionName
+	})
+
+	for _, tr := range taxes {
+		report.Pajak = append(report.Pajak, *tr)
+	}
+	sort.Slice(report.Pajak, func(i, j int) bool { return report.Pajak[i].Rate < report.Pajak[j].Rate })
+
+	return report
+}
+
+// GetProductSalesReport - agregasi yang sama dengan query Postgres, varian digabung ke induknya
+func (repo *MemoryReportRepository) GetProductSalesReport(startDate, endDate *time.Time) ([]models.ProductSalesTotal, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start, end := dateOf(s.Now()), dateOf(s.Now())
+	if startDate != nil && endDate != nil {
+		start, end = dateOf(*startDate), dateOf(*endDate)
+	}
+
+	totals := make(map[int]*productSales)
+	var ids []int
+	for _, t := range s.transactions {
+		d := dateOf(t.CreatedAt)
+		if t.Status == models.TransactionStatusVoided || d.Before(start) || d.After(end) {
+			continue
+		}
+		for _, detail := range t.Details {
+			ps, ok := totals[detail.ProductID]
+			if !ok {
+				ps = &productSales{}
+				ps.total.ProductID = detail.ProductID
+				totals[detail.ProductID] = ps
+				ids = append(ids, detail.ProductID)
+			}
+			if detail.ID > ps.lastDetailID {
+				ps.parent, ps.total.ProductName, ps.lastDetailID = detailParent(detail), detail.ProductName, detail.ID
+			}
+			ps.total.Quantity += detail.Quantity
+			ps.total.GrossSales += detail.Subtotal
+			ps.total.DiscountAmount += detail.DiscountAmount
+			ps.total.NetSales += detail.TotalAmount
+		}
+	}
+
+	sales := make([]productSales, 0, len(ids))
+	for _, id := range ids {
+		sales = append(sales, *totals[id])
+	}
+	return groupProductSales(sales), nil
+}
+
+// GetComponentConsumption - agregasi yang sama dengan query Postgres dari snapshot resep di
+// detail transaksi
+func (repo *MemoryReportRepository) GetComponentConsumption(startDate, endDate *time.Time) ([]models.ComponentConsumption, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start, end := dateOf(s.Now()), dateOf(s.Now())
+	if startDate != nil && endDate != nil {
+		start, end = dateOf(*startDate), dateOf(*endDate)
+	}
+	inRange := func(t time.Time) bool {
+		d := dateOf(t)
+		return !d.Before(start) && !d.After(end)
+	}
+
+	components := make(map[int]*models.ComponentConsumption)
+	row := func(c models.RecipeItem) *models.ComponentConsumption {
+		cc, ok := components[c.ComponentID]
+		if !ok {
+			cc = &models.ComponentConsumption{ComponentID: c.ComponentID, ComponentName: c.ComponentName}
+			if p, ok := s.products[c.ComponentID]; ok {
+				cc.ComponentName = p.Name
+				cc.Stock = p.Stock
+			}
+			components[c.ComponentID] = cc
+		}
+		return cc
+	}
+
+	for _, t := range s.transactions {
+		details := make(map[int]models.TransactionDetail)
+		for _, d := range t.Details {
+			details[d.ID] = d
+			if t.Status == models.TransactionStatusVoided || !inRange(t.CreatedAt) {
+				continue
+			}
+			for _, c := range d.Components {
+				row(c).Quantity += d.Quantity * c.Quantity
+			}
+		}
+
+		for _, r := range t.Refunds {
+			if !inRange(r.CreatedAt) {
+				continue
+			}
+			for _, item := range r.Items {
+				for _, c := range details[item.TransactionDetailID].Components {
+					row(c).Returned += item.Quantity * c.Quantity
+				}
+			}
+		}
+	}
+
+	report := make([]models.ComponentConsumption, 0, len(components))
+	for _, c := range components {
+		c.NetQuantity = c.Quantity - c.Returned
+		report = append(report, *c)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].ComponentID < report[j].ComponentID })
+	return report, nil
+}
+
+// detailParent - produk induk untuk laporan dari snapshot detail: induk varian, selain itu
+// produknya sendiri, seperti parentNameSnapshot
+func detailParent(d models.TransactionDetail) models.ProductSalesTotal {
+	if d.ParentID != nil {
+		return models.ProductSalesTotal{ProductID: *d.ParentID, ProductName: d.ParentName}
+	}
+	return models.ProductSalesTotal{ProductID: d.ProductID, ProductName: d.ProductName}
+}
+
+// GetCashierReport - agregasi yang sama dengan query Postgres, dikelompokkan per kasir per terminal
+func (repo *MemoryReportRepository) GetCashierReport(startDate, endDate *time.Time) ([]models.CashierSalesReport, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start, end := dateOf(s.Now()), dateOf(s.Now())
+	if startDate != nil && endDate != nil {
+		start, end = dateOf(*startDate), dateOf(*endDate)
+	}
+	inRange := func(t time.Time) bool {
+		d := dateOf(t)
+		return !d.Before(start) && !d.After(end)
+	}
+
+	type cashierKey struct {
+		cashierID  int // 0 untuk transaksi lama tanpa kasir
+		terminalID int
+	}
+
+	rows := make(map[cashierKey]*models.CashierSalesReport)
+	row := func(t *models.Transaction) *models.CashierSalesReport {
+		var key cashierKey
+		if t.CashierID != nil {
+			key.cashierID = *t.CashierID
+		}
+		if t.TerminalID != nil {
+			key.terminalID = *t.TerminalID
+		}
+
+		r, ok := rows[key]
+		if !ok {
+			r = &models.CashierSalesReport{
+				CashierID:    t.CashierID,
+				CashierName:  s.users[key.cashierID].Name,
+				TerminalID:   t.TerminalID,
+				TerminalName: s.terminals[key.terminalID].Name,
+			}
+			rows[key] = r
+		}
+		return r
+	}
+
+	for _, t := range s.transactions {
+		for _, refund := range t.Refunds {
+			if inRange(refund.CreatedAt) {
+				row(t).TotalRefund += refund.TotalAmount
+			}
+		}
+
+		if !inRange(t.CreatedAt) {
+			continue
+		}
+
+		r := row(t)
+		if t.Status == models.TransactionStatusVoided {
+			r.TotalVoid++
+			r.VoidAmount += t.TotalAmount
+			continue
+		}
+
+		r.TotalTransaksi++
+		r.GrossSales += t.SubtotalAmount
+		r.TotalDiskon += t.DiscountAmount
+		r.NetSales += t.TotalAmount
+		for _, p := range t.Payments {
+			if p.Method == models.PaymentMethodCash {
+				r.CashAmount += p.Amount
+			}
+		}
+	}
+
+	report := make([]models.CashierSalesReport, 0, len(rows))
+	keys := make([]cashierKey, 0, len(rows))
+	for key := range rows {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].cashierID != keys[j].cashierID {
+			return keys[i].cashierID < keys[j].cashierID
+		}
+		return keys[i].terminalID < keys[j].terminalID
+	})
+	for _, key := range keys {
+		report = append(report, *rows[key])
+	}
+
+	return report, nil
+}
+
+// CreateEndOfDayReport - perilaku sama dengan versi Postgres, mutex store menggantikan kunci tanggal
+func (repo *MemoryReportRepository) CreateEndOfDayReport(reportType string, businessDate *time.Time, createdBy int) (*models.EndOfDayReport, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.Now()
+	today := dateOf(now)
+	day := today
+	if businessDate != nil {
+		day = dateOf(*businessDate)
+	}
+	if day.After(today) {
+		return nil, errFutureBusinessDate
+	}
+
+	if reportType == models.EndOfDayReportZ {
+		if err := s.checkDayOpen(day); err != nil {
+			return nil, err
+		}
+		for _, shift := range s.shifts {
+			if shift.Status == models.ShiftStatusOpen && !dateOf(shift.OpenedAt).After(day) {
+				return nil, errOpenShiftsBeforeZ
+			}
+		}
+	}
+
+	report := models.EndOfDayReport{
+		ID:              s.nextID("end_of_day_reports"),
+		Type:            reportType,
+		BusinessDate:    day,
+		Sequence:        1,
+		DateRangeReport: *s.reportByDateRange(day, day),
+		Kategori:        make([]models.CategorySalesTotal, 0),
+		CreatedBy:       createdBy,
+		CreatedAt:       now,
+	}
+	report.NetSales = report.NetRevenue + report.TotalRefund
+
+	for _, r := range s.endOfDayReports {
+		if r.Type == reportType && r.BusinessDate.Equal(day) {
+			report.Sequence = max(report.Sequence, r.Sequence+1)
+		}
+	}
+
+	categories := make(map[int]*models.CategorySalesTotal) // 0 = tanpa kategori
+	lastDetailID := make(map[int]int)                      // nama kategori diambil dari detail terakhir
+	for _, t := range s.transactions {
+		if !sameDate(t.CreatedAt, day) {
+			continue
+		}
+
+		if report.FirstTransactionID == nil || t.ID < *report.FirstTransactionID {
+			report.FirstTransactionID = &t.ID
+			report.FirstReceiptNumber = t.ReceiptNumber
+		}
+		if report.LastTransactionID == nil || t.ID > *report.LastTransactionID {
+			report.LastTransactionID = &t.ID
+			report.LastReceiptNumber = t.ReceiptNumber
+		}
+
+		if t.Status == models.TransactionStatusVoided {
+			report.TotalVoid++
+			report.VoidAmount += t.TotalAmount
+			continue
+		}
+
+		for _, d := range t.Details {
+			categoryID := 0
+			if d.CategoryID != nil {
+				categoryID = *d.CategoryID
+			}
+
+			ct, ok := categories[categoryID]
+			if !ok {
+				ct = &models.CategorySalesTotal{CategoryID: d.CategoryID}
+				categories[categoryID] = ct
+			}
+			if d.ID > lastDetailID[categoryID] {
+				lastDetailID[categoryID] = d.ID
+				ct.CategoryName = d.CategoryName
+			}
+			ct.Quantity += d.Quantity
+			ct.GrossSales += d.Subtotal
+			ct.DiscountAmount += d.DiscountAmount
+			ct.NetSales += d.TotalAmount
+		}
+	}
+
+	ids := make([]int, 0, len(categories))
+	for id := range categories {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		report.Kategori = append(report.Kategori, *categories[id])
+	}
+
+	s.endOfDayReports[report.ID] = report
+	return &report, nil
+}
+
+func (repo *MemoryReportRepository) GetEndOfDayReports(reportType string, businessDate *time.Time) ([]models.EndOfDayReport, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reports := make([]models.EndOfDayReport, 0)
+	for _, r := range s.endOfDayReports {
+		if reportType != "" && r.Type != reportType {
+			continue
+		}
+		if businessDate != nil && !r.BusinessDate.Equal(dateOf(*businessDate)) {
+			continue
+		}
+		reports = append(reports, r)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		a, b := reports[i], reports[j]
+		if !a.BusinessDate.Equal(b.BusinessDate) {
+			return a.BusinessDate.After(b.BusinessDate)
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Sequence > b.Sequence
+	})
+	return reports, nil
+}
+
+// checkDayOpen - padanan lockOpenDay versi Postgres, harus dipanggil saat mutex dipegang
+func (s *MemoryStore) checkDayOpen(day time.Time) error {
+	for _, r := range s.endOfDayReports {
+		if r.Type == models.EndOfDayReportZ && r.BusinessDate.Equal(dateOf(day)) {
+			return dayClosedError(r.BusinessDate)
+		}
+	}
+	return nil
+}
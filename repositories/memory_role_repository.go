@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"slices"
+	"sort"
+)
+
+type MemoryRoleRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryRoleRepository(store *MemoryStore) *MemoryRoleRepository {
+	return &MemoryRoleRepository{store: store}
+}
+
+func (repo *MemoryRoleRepository) GetAll() ([]models.Role, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	roles := make([]models.Role, 0, len(s.roles))
+	for _, r := range s.roles {
+		roles = append(roles, *cloneRole(r))
+	}
+
+	// ORDER BY built_in DESC, name
+	sort.Slice(roles, func(i, j int) bool {
+		if roles[i].BuiltIn != roles[j].BuiltIn {
+			return roles[i].BuiltIn
+		}
+		return roles[i].Name < roles[j].Name
+	})
+	return roles, nil
+}
+
+func (repo *MemoryRoleRepository) GetByName(name string) (*models.Role, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.roles[name]
+	if !ok {
+		return nil, errRoleNotFound
+	}
+	return cloneRole(r), nil
+}
+
+func (repo *MemoryRoleRepository) Create(role *models.Role) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.roles[role.Name]; ok {
+		return errRoleExists
+	}
+
+	role.BuiltIn = false
+	role.CreatedAt = s.Now()
+	s.roles[role.Name] = *cloneRole(*role)
+	return nil
+}
+
+func (repo *MemoryRoleRepository) Update(role *models.Role) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.roles[role.Name]
+	if !ok {
+		return errRoleNotFound
+	}
+
+	role.BuiltIn = existing.BuiltIn
+	role.CreatedAt = existing.CreatedAt
+	s.roles[role.Name] = *cloneRole(*role)
+	return nil
+}
+
+func (repo *MemoryRoleRepository) Delete(name string) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.roles[name]; !ok {
+		return errRoleNotFound
+	}
+
+	// padanan FOREIGN KEY users.role
+	for _, u := range s.users {
+		if u.Role == name {
+			return errRoleInUse
+		}
+	}
+
+	delete(s.roles, name)
+	return nil
+}
+
+func cloneRole(r models.Role) *models.Role {
+	r.Permissions = slices.Clone(r.Permissions)
+	return &r
+}
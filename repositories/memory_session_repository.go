@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"time"
+)
+
+type MemorySessionRepository struct {
+	store *MemoryStore
+}
+
+func NewMemorySessionRepository(store *MemoryStore) *MemorySessionRepository {
+	return &MemorySessionRepository{store: store}
+}
+
+func (repo *MemorySessionRepository) Create(session *models.Session) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session.ID = s.nextID("user_sessions")
+	session.CreatedAt = s.Now()
+	s.sessions[session.ID] = *session
+	return nil
+}
+
+func (repo *MemorySessionRepository) GetByTokenHash(tokenHash string) (*models.Session, error) {
+	return repo.find(func(session models.Session) bool { return session.TokenHash == tokenHash })
+}
+
+func (repo *MemorySessionRepository) GetByRefreshTokenHash(refreshTokenHash string) (*models.Session, error) {
+	return repo.find(func(session models.Session) bool { return session.RefreshTokenHash == refreshTokenHash })
+}
+
+func (repo *MemorySessionRepository) find(match func(models.Session) bool) (*models.Session, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, session := range s.sessions {
+		if session.RevokedAt == nil && match(session) {
+			return &session, nil
+		}
+	}
+	return nil, errSessionNotFound
+}
+
+func (repo *MemorySessionRepository) Rotate(session *models.Session, oldRefreshTokenHash string) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.sessions[session.ID]
+	if !ok || existing.RevokedAt != nil || existing.RefreshTokenHash != oldRefreshTokenHash {
+		return errSessionNotFound
+	}
+
+	existing.TokenHash = session.TokenHash
+	existing.RefreshTokenHash = session.RefreshTokenHash
+	existing.ExpiresAt = session.ExpiresAt
+	existing.RefreshExpiresAt = session.RefreshExpiresAt
+	s.sessions[session.ID] = existing
+	return nil
+}
+
+func (repo *MemorySessionRepository) Revoke(id int, at time.Time) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[id]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &at
+		s.sessions[id] = session
+	}
+	return nil
+}
+
+func (repo *MemorySessionRepository) RevokeAllForUser(userID int, at time.Time) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+			s.sessions[id] = session
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"sort"
+)
+
+type MemoryShiftRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryShiftRepository(store *MemoryStore) *MemoryShiftRepository {
+	return &MemoryShiftRepository{store: store}
+}
+
+func (repo *MemoryShiftRepository) GetAll(filter models.ShiftFilter) ([]models.Shift, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shifts := make([]models.Shift, 0)
+	for _, shift := range s.shifts {
+		if filter.TerminalID != nil && shift.TerminalID != *filter.TerminalID {
+			continue
+		}
+		if filter.CashierID != nil && shift.CashierID != *filter.CashierID {
+			continue
+		}
+		if filter.Status != "" && shift.Status != filter.Status {
+			continue
+		}
+		shifts = append(shifts, *s.shiftWithTotals(shift, false))
+	}
+
+	sort.Slice(shifts, func(i, j int) bool {
+		if !shifts[i].OpenedAt.Equal(shifts[j].OpenedAt) {
+			return shifts[i].OpenedAt.After(shifts[j].OpenedAt)
+		}
+		return shifts[i].ID > shifts[j].ID
+	})
+	return shifts, nil
+}
+
+func (repo *MemoryShiftRepository) GetByID(id int) (*models.Shift, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shift, ok := s.shifts[id]
+	if !ok {
+		return nil, errShiftNotFound
+	}
+	return s.shiftWithTotals(shift, true), nil
+}
+
+func (repo *MemoryShiftRepository) GetOpenByTerminal(terminalID int) (*models.Shift, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := s.openShiftID(terminalID)
+	if err != nil {
+		return nil, err
+	}
+	return s.shiftWithTotals(s.shifts[id], true), nil
+}
+
+func (repo *MemoryShiftRepository) Open(req models.OpenShiftRequest) (*models.Shift, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkTerminal(req.TerminalID); err != nil {
+		return nil, err
+	}
+	if _, err := s.openShiftID(req.TerminalID); err == nil {
+		return nil, errShiftAlreadyOpen
+	}
+
+	shift := models.Shift{
+		ID:           s.nextID("shifts"),
+		TerminalID:   req.TerminalID,
+		CashierID:    req.CashierID,
+		Status:       models.ShiftStatusOpen,
+		OpeningFloat: req.OpeningFloat,
+		OpenedAt:     s.Now(),
+	}
+	s.shifts[shift.ID] = shift
+
+	return s.shiftWithTotals(shift, true), nil
+}
+
+func (repo *MemoryShiftRepository) AddCashMovement(shiftID int, req models.CashMovementRequest) (*models.CashMovement, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shift, ok := s.shifts[shiftID]
+	if !ok {
+		return nil, errShiftNotFound
+	}
+	if shift.Status != models.ShiftStatusOpen {
+		return nil, errShiftClosed
+	}
+
+	m := models.CashMovement{
+		ID:        s.nextID("cash_movements"),
+		ShiftID:   shiftID,
+		Type:      req.Type,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+		CreatedBy: req.CreatedBy,
+		CreatedAt: s.Now(),
+	}
+	s.cashMovements[m.ID] = m
+
+	return &m, nil
+}
+
+func (repo *MemoryShiftRepository) Close(id int, req models.CloseShiftRequest) (*models.Shift, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shift, ok := s.shifts[id]
+	if !ok {
+		return nil, errShiftNotFound
+	}
+	if shift.Status != models.ShiftStatusOpen {
+		return nil, errShiftClosed
+	}
+
+	now := s.Now()
+	closedBy, countedCash := req.ClosedBy, *req.CountedCash
+	shift.ExpectedCash = s.shiftWithTotals(shift, false).ExpectedCash
+	shift.Status = models.ShiftStatusClosed
+	shift.CountedCash = &countedCash
+	shift.ClosingNote = req.Note
+	shift.ClosedBy = &closedBy
+	shift.ClosedAt = &now
+	s.shifts[id] = shift
+
+	return s.shiftWithTotals(shift, true), nil
+}
+
+// shiftWithTotals - salinan shift dengan total laci yang dihitung dari transaksi, refund dan
+// cash movement, padanan shiftColumns versi Postgres. Harus dipanggil saat mutex dipegang.
+func (s *MemoryStore) shiftWithTotals(shift models.Shift, withMovements bool) *models.Shift {
+	result := shift
+	result.TotalTransaksi, result.CashSales, result.CashRefunds, result.PaidIn, result.PaidOut = 0, 0, 0, 0, 0
+
+	for _, t := range s.transactions {
+		for _, r := range t.Refunds {
+			if intPtrEqual(r.ShiftID, shift.ID) {
+				result.CashRefunds += r.CashAmount
+			}
+		}
+
+		if !intPtrEqual(t.ShiftID, shift.ID) || t.Status == models.TransactionStatusVoided {
+			continue
+		}
+		result.TotalTransaksi++
+		for _, p := range t.Payments {
+			if p.Method == models.PaymentMethodCash {
+				result.CashSales += p.Amount
+			}
+		}
+	}
+
+	movements := make([]models.CashMovement, 0)
+	for _, m := range s.cashMovements {
+		if m.ShiftID != shift.ID {
+			continue
+		}
+		if m.Type == models.CashMovementPaidIn {
+			result.PaidIn += m.Amount
+		} else {
+			result.PaidOut += m.Amount
+		}
+		movements = append(movements, m)
+	}
+
+	var storedExpected *int
+	if shift.Status == models.ShiftStatusClosed {
+		storedExpected = &shift.ExpectedCash
+	}
+	finishShiftTotals(&result, storedExpected)
+
+	result.CashMovements = nil
+	if withMovements {
+		sort.Slice(movements, func(i, j int) bool { return movements[i].ID < movements[j].ID })
+		result.CashMovements = movements
+	}
+
+	return &result
+}
+
+// openShiftID - padanan openShiftID versi Postgres, harus dipanggil saat mutex dipegang
+func (s *MemoryStore) openShiftID(terminalID int) (int, error) {
+	for _, shift := range s.shifts {
+		if shift.TerminalID == terminalID && shift.Status == models.ShiftStatusOpen {
+			return shift.ID, nil
+		}
+	}
+	return 0, errNoOpenShift
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"slices"
+	"sort"
+)
+
+type MemoryStockMovementRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryStockMovementRepository(store *MemoryStore) *MemoryStockMovementRepository {
+	return &MemoryStockMovementRepository{store: store}
+}
+
+func (repo *MemoryStockMovementRepository) Create(req models.StockMovementRequest) (*models.StockMovement, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.products[req.ProductID]
+	if !ok {
+		return nil, errProductNotFound
+	}
+
+	m, err := buildStockMovement(req, p.Name, p.Stock)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Stock = m.Balance
+	s.products[p.ID] = p
+	s.addStockMovement(m)
+	return m, nil
+}
+
+func (repo *MemoryStockMovementRepository) GetByProduct(productID int, filter models.StockMovementFilter) (*models.StockMovementList, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.products[productID]; !ok {
+		return nil, errProductNotFound
+	}
+
+	matched := make([]models.StockMovement, 0)
+	for _, m := range s.stockMovements {
+		if m.ProductID != productID {
+			continue
+		}
+		if filter.StartDate != nil && dateOf(m.CreatedAt).Before(dateOf(*filter.StartDate)) {
+			continue
+		}
+		if filter.EndDate != nil && dateOf(m.CreatedAt).After(dateOf(*filter.EndDate)) {
+			continue
+		}
+		if filter.Type != "" && m.Type != filter.Type {
+			continue
+		}
+		matched = append(matched, m)
+	}
+	slices.Reverse(matched)
+
+	list := &models.StockMovementList{
+		Data:  []models.StockMovement{},
+		Page:  filter.Page,
+		Limit: filter.Limit,
+		Total: len(matched),
+	}
+
+	start := min((filter.Page-1)*filter.Limit, len(matched))
+	end := min(start+filter.Limit, len(matched))
+	list.Data = append(list.Data, matched[start:end]...)
+
+	return list, nil
+}
+
+func (repo *MemoryStockMovementRepository) Check() (*models.StockCheck, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ledger := make(map[int]int)
+	lastBalance := make(map[int]int)
+	for _, m := range s.stockMovements {
+		ledger[m.ProductID] += m.Quantity
+		lastBalance[m.ProductID] = m.Balance
+	}
+
+	ids := make([]int, 0, len(s.products))
+	for id := range s.products {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	check := &models.StockCheck{Consistent: true, Discrepancies: []models.StockDiscrepancy{}}
+	for _, id := range ids {
+		p := s.products[id]
+		d := models.StockDiscrepancy{
+			ProductID:   p.ID,
+			ProductName: p.Name,
+			Stock:       p.Stock,
+			LedgerStock: ledger[p.ID],
+		}
+		if balance, ok := lastBalance[p.ID]; ok {
+			d.LastBalance = &balance
+		}
+		addStockCheck(check, d)
+	}
+
+	return check, nil
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"sync"
+	"time"
+)
+
+// MemoryStore - penyimpanan in-memory yang dipakai bersama oleh semua Memory*Repository,
+// pengganti Postgres untuk test dan development. Satu mutex untuk seluruh data sehingga
+// checkout, void dan refund tetap atomik seperti transaksi database.
+type MemoryStore struct {
+	mu sync.Mutex
+
+	// Now - sumber waktu untuk created_at, masa berlaku promo dan laporan harian.
+	// Bisa diganti di test untuk mensimulasikan hari yang berbeda.
+	Now func() time.Time
+
+	categories      map[int]models.ProductCategory
+	products        map[int]models.Product
+	promotions      map[int]models.Promotion
+	transactions    map[int]*models.Transaction
+	idempotencyKeys map[string]memoryIdempotencyKey
+	users           map[int]models.User
+	sessions        map[int]models.Session
+	roles           map[string]models.Role
+	terminals       map[int]models.Terminal
+	shifts          map[int]models.Shift // tanpa total, dihitung saat dibaca
+	cashMovements   map[int]models.CashMovement
+	stockMovements  []models.StockMovement        // append-only, urut id
+	endOfDayReports map[int]models.EndOfDayReport // tidak pernah diubah setelah dibuat
+	receiptNumbers  map[string]int                // "outlet_code/YYYYMMDD" -> nomor terakhir
+
+	lastID map[string]int
+}
+
+type memoryIdempotencyKey struct {
+	requestHash   string
+	transactionID int
+}
+
+// NewMemoryStore - store kosong, kecuali role bawaan yang di Postgres diisi oleh migrasi
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		Now:             time.Now,
+		categories:      make(map[int]models.ProductCategory),
+		products:        make(map[int]models.Product),
+		promotions:      make(map[int]models.Promotion),
+		transactions:    make(map[int]*models.Transaction),
+		idempotencyKeys: make(map[string]memoryIdempotencyKey),
+		users:           make(map[int]models.User),
+		sessions:        make(map[int]models.Session),
+		roles:           make(map[string]models.Role),
+		terminals:       make(map[int]models.Terminal),
+		shifts:          make(map[int]models.Shift),
+		cashMovements:   make(map[int]models.CashMovement),
+		endOfDayReports: make(map[int]models.EndOfDayReport),
+		receiptNumbers:  make(map[string]int),
+		lastID:          make(map[string]int),
+	}
+
+	for _, role := range models.DefaultRoles() {
+		role.CreatedAt = s.Now()
+		s.roles[role.Name] = role
+	}
+
+	return s
+}
+
+// nextID - pengganti SERIAL, harus dipanggil saat mutex dipegang
+func (s *MemoryStore) nextID(table string) int {
+	s.lastID[table]++
+	return s.lastID[table]
+}
+
+// nextReceiptNumber - padanan receipt_sequences, harus dipanggil saat mutex dipegang dan hanya
+// setelah checkout pasti berhasil supaya nomor tidak bolong
+func (s *MemoryStore) nextReceiptNumber(cfg models.ReceiptNumberConfig, now time.Time) string {
+	day := dateOf(now)
+	key := cfg.OutletCode + "/" + day.Format("20060102")
+	s.receiptNumbers[key]++
+	return formatReceiptNumber(cfg, day, s.receiptNumbers[key])
+}
+
+// addStockMovement - padanan INSERT INTO stock_movements, harus dipanggil saat mutex dipegang
+// dan setelah stok produk diubah
+func (s *MemoryStore) addStockMovement(m *models.StockMovement) {
+	m.ID = s.nextID("stock_movements")
+	m.CreatedAt = s.Now()
+	s.stockMovements = append(s.stockMovements, *m)
+}
+
+// sameDate - padanan DATE(a) = DATE(b)
+func sameDate(a, b time.Time) bool {
+	return dateOf(a).Equal(dateOf(b))
+}
+
+// dateOf - tanggal (tanpa jam) dari t menurut zona waktu t sendiri
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
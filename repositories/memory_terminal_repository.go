@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"sort"
+	"strings"
+)
+
+type MemoryTerminalRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryTerminalRepository(store *MemoryStore) *MemoryTerminalRepository {
+	return &MemoryTerminalRepository{store: store}
+}
+
+func (repo *MemoryTerminalRepository) GetAll() ([]models.Terminal, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	terminals := make([]models.Terminal, 0, len(s.terminals))
+	for _, t := range s.terminals {
+		terminals = append(terminals, t)
+	}
+
+	sort.Slice(terminals, func(i, j int) bool { return terminals[i].Code < terminals[j].Code })
+	return terminals, nil
+}
+
+func (repo *MemoryTerminalRepository) GetByID(id int) (*models.Terminal, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.terminals[id]
+	if !ok {
+		return nil, errTerminalNotFound
+	}
+	return &t, nil
+}
+
+func (repo *MemoryTerminalRepository) Create(terminal *models.Terminal) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.terminalCodeTaken(terminal.Code, 0) {
+		return errTerminalCodeTaken
+	}
+
+	terminal.ID = s.nextID("terminals")
+	terminal.CreatedAt = s.Now()
+	s.terminals[terminal.ID] = *terminal
+	return nil
+}
+
+func (repo *MemoryTerminalRepository) Update(terminal *models.Terminal) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.terminals[terminal.ID]
+	if !ok {
+		return errTerminalNotFound
+	}
+	if s.terminalCodeTaken(terminal.Code, terminal.ID) {
+		return errTerminalCodeTaken
+	}
+
+	terminal.CreatedAt = existing.CreatedAt
+	s.terminals[terminal.ID] = *terminal
+	return nil
+}
+
+func (s *MemoryStore) terminalCodeTaken(code string, exceptID int) bool {
+	for _, t := range s.terminals {
+		if t.ID != exceptID && strings.EqualFold(t.Code, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTerminal - padanan checkTerminal versi Postgres, harus dipanggil saat mutex dipegang
+func (s *MemoryStore) checkTerminal(id int) error {
+	t, ok := s.terminals[id]
+	if !ok {
+		return errInvalidTerminal
+	}
+	if !t.Active {
+		return errInactiveTerminal
+	}
+	return nil
+}
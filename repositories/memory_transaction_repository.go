@@ -0,0 +1,407 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type MemoryTransactionRepository struct {
+	store         *MemoryStore
+	taxConfig     models.TaxConfig
+	receiptConfig models.ReceiptNumberConfig
+}
+
+func NewMemoryTransactionRepository(store *MemoryStore, taxConfig models.TaxConfig, receiptConfig models.ReceiptNumberConfig) *MemoryTransactionRepository {
+	return &MemoryTransactionRepository{store: store, taxConfig: taxConfig, receiptConfig: receiptConfig}
+}
+
+// CreateTransaction - perilaku sama dengan versi Postgres. useLock tidak berpengaruh karena
+// seluruh checkout berjalan di bawah mutex store.
+func (repo *MemoryTransactionRepository) CreateTransaction(req models.CheckoutRequest, useLock bool) (*models.Transaction, bool, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.IdempotencyKey != "" {
+		if existing, ok := s.idempotencyKeys[req.IdempotencyKey]; ok {
+			if existing.requestHash != req.RequestHash {
+				return nil, false, &models.ConflictError{Message: "Idempotency-Key sudah dipakai untuk request checkout yang berbeda"}
+			}
+			return s.cloneTransaction(s.transactions[existing.transactionID]), true, nil
+		}
+	}
+
+	if err := s.checkTerminal(req.TerminalID); err != nil {
+		return nil, false, err
+	}
+
+	shiftID, err := s.openShiftID(req.TerminalID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := s.checkDayOpen(s.Now()); err != nil {
+		return nil, false, err
+	}
+
+	requested := make(map[int]int)
+	productIDs := make([]int, 0, len(req.Items))
+	for _, item := range req.Items {
+		if _, ok := requested[item.ProductID]; !ok {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+	sort.Ints(productIDs)
+
+	products := make(map[int]lockedProduct)
+	for _, id := range productIDs {
+		p, ok := s.products[id]
+		if !ok {
+			return nil, false, &models.NotFoundError{Message: fmt.Sprintf("produk id %d tidak ditemukan", id)}
+		}
+
+		lp := lockedProduct{
+			name:        p.Name,
+			price:       p.Price,
+			stock:       p.Stock,
+			categoryID:  p.CategoryID,
+			taxRate:     p.TaxRate,
+			taxExempt:   p.TaxExempt,
+			active:      p.Active,
+			parentID:    p.ParentID,
+			hasVariants: len(s.variantIDs(id)) > 0,
+			recipe:      s.productWithCategory(p).Recipe,
+		}
+		if p.ParentID != nil {
+			lp.parentName = s.products[*p.ParentID].Name
+		}
+		if p.CategoryID != nil {
+			category := s.categories[*p.CategoryID]
+			lp.category = category.Name
+			if lp.taxRate == nil {
+				lp.taxRate = category.TaxRate
+			}
+		}
+		products[id] = lp
+	}
+
+	if err := checkSellable(productIDs, products); err != nil {
+		return nil, false, err
+	}
+
+	// stok komponen resep ikut dicek dan dikurangi
+	stockIDs, needed := stockRequirements(productIDs, products, requested)
+	for _, id := range stockIDs {
+		if _, ok := products[id]; !ok {
+			products[id] = lockedProduct{name: s.products[id].Name, stock: s.products[id].Stock}
+		}
+	}
+
+	if shortages := stockShortages(stockIDs, products, needed); len(shortages) > 0 {
+		return nil, false, &models.InsufficientStockError{Items: shortages}
+	}
+
+	now := s.Now()
+	t, err := buildTransaction(req, products, s.activePromotions(now), repo.taxConfig)
+	if err != nil {
+		return nil, false, err
+	}
+	t.ShiftID = &shiftID
+
+	t.ID = s.nextID("transactions")
+	t.ReceiptNumber = s.nextReceiptNumber(repo.receiptConfig, now)
+	t.CreatedAt = now
+	for i := range t.Details {
+		t.Details[i].ID = s.nextID("transaction_details")
+		t.Details[i].TransactionID = t.ID
+	}
+	for i := range t.Payments {
+		t.Payments[i].ID = s.nextID("payments")
+		t.Payments[i].TransactionID = t.ID
+	}
+	t.Refunds = []models.Refund{}
+
+	for _, id := range stockIDs {
+		p := s.products[id]
+		p.Stock -= needed[id]
+		s.products[id] = p
+		m := saleStockMovement(t, id, needed[id], p.Stock)
+		s.addStockMovement(&m)
+	}
+
+	s.transactions[t.ID] = t
+	if req.IdempotencyKey != "" {
+		s.idempotencyKeys[req.IdempotencyKey] = memoryIdempotencyKey{requestHash: req.RequestHash, transactionID: t.ID}
+	}
+
+	return s.cloneTransaction(t), false, nil
+}
+
+// GetAll - daftar transaksi (tanpa detail) sesuai filter, beserta total data untuk paginasi
+func (repo *MemoryTransactionRepository) GetAll(filter models.TransactionFilter) (*models.TransactionList, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matched := make([]*models.Transaction, 0)
+	for _, t := range s.transactions {
+		if filter.StartDate != nil && dateOf(t.CreatedAt).Before(dateOf(*filter.StartDate)) {
+			continue
+		}
+		if filter.EndDate != nil && dateOf(t.CreatedAt).After(dateOf(*filter.EndDate)) {
+			continue
+		}
+		if filter.MinAmount != nil && t.TotalAmount < *filter.MinAmount {
+			continue
+		}
+		if filter.MaxAmount != nil && t.TotalAmount > *filter.MaxAmount {
+			continue
+		}
+		if filter.ProductID != nil && !hasProduct(t, *filter.ProductID) {
+			continue
+		}
+		if filter.CashierID != nil && !intPtrEqual(t.CashierID, *filter.CashierID) {
+			continue
+		}
+		if filter.TerminalID != nil && !intPtrEqual(t.TerminalID, *filter.TerminalID) {
+			continue
+		}
+		if filter.ShiftID != nil && !intPtrEqual(t.ShiftID, *filter.ShiftID) {
+			continue
+		}
+		if filter.ReceiptNumber != "" && !strings.Contains(strings.ToLower(t.ReceiptNumber), strings.ToLower(filter.ReceiptNumber)) {
+			continue
+		}
+		if filter.Status != "" && t.Status != filter.Status {
+			continue
+		}
+		matched = append(matched, t)
+	}
+
+	less := func(a, b *models.Transaction) bool {
+		switch filter.SortBy {
+		case "id":
+			return a.ID < b.ID
+		case "total_amount":
+			if a.TotalAmount != b.TotalAmount {
+				return a.TotalAmount < b.TotalAmount
+			}
+		default:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if filter.SortDesc {
+			return less(matched[j], matched[i])
+		}
+		return less(matched[i], matched[j])
+	})
+
+	list := &models.TransactionList{
+		Data:  []models.Transaction{},
+		Page:  filter.Page,
+		Limit: filter.Limit,
+		Total: len(matched),
+	}
+
+	start := min((filter.Page-1)*filter.Limit, len(matched))
+	end := min(start+filter.Limit, len(matched))
+	for _, t := range matched[start:end] {
+		summary := *t
+		summary.Details = nil
+		summary.Promotions = nil
+		summary.Payments = nil
+		summary.Refunds = nil
+		list.Data = append(list.Data, summary)
+	}
+
+	return list, nil
+}
+
+func (repo *MemoryTransactionRepository) GetByID(id int) (*models.Transaction, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.transactions[id]
+	if !ok {
+		return nil, &models.NotFoundError{Message: "transaksi tidak ditemukan"}
+	}
+
+	return s.cloneTransaction(t), nil
+}
+
+// Void - batalkan transaksi hari ini yang belum pernah direfund, seluruh stok dikembalikan
+func (repo *MemoryTransactionRepository) Void(id int, req models.VoidRequest) (*models.Transaction, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.transactions[id]
+	if !ok {
+		return nil, &models.NotFoundError{Message: "transaksi tidak ditemukan"}
+	}
+
+	now := s.Now()
+	shiftClosed := t.ShiftID != nil && s.shifts[*t.ShiftID].Status != models.ShiftStatusOpen
+	if err := checkVoidable(t.Status, sameDate(t.CreatedAt, now), shiftClosed); err != nil {
+		return nil, err
+	}
+	if err := s.checkDayOpen(t.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	returned := make(map[int]int)
+	for _, d := range t.Details {
+		addReturnedStock(returned, d, d.Quantity)
+	}
+	s.restoreStock(returned, voidStockMovement(id, t.ReceiptNumber, req))
+
+	t.Status = models.TransactionStatusVoided
+	t.VoidReason = req.Reason
+	t.VoidedAt = &now
+
+	return s.cloneTransaction(t), nil
+}
+
+// Refund - kembalikan sebagian atau seluruh item transaksi dan tambahkan stoknya kembali
+func (repo *MemoryTransactionRepository) Refund(id int, req models.RefundRequest) (*models.Refund, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.transactions[id]
+	if !ok {
+		return nil, &models.NotFoundError{Message: "transaksi tidak ditemukan"}
+	}
+
+	if t.Status == models.TransactionStatusVoided {
+		return nil, &models.ConflictError{Message: "transaksi sudah di-void"}
+	}
+	if err := s.checkDayOpen(s.Now()); err != nil {
+		return nil, err
+	}
+
+	refund, returned, newStatus, err := buildRefund(id, req, t.Details)
+	if err != nil {
+		return nil, err
+	}
+
+	cashPaid, cashRefunded := 0, 0
+	for _, p := range t.Payments {
+		if p.Method == models.PaymentMethodCash {
+			cashPaid += p.Amount
+		}
+	}
+	for _, r := range t.Refunds {
+		cashRefunded += r.CashAmount
+	}
+
+	refund.CashAmount = refundCashAmount(refund.TotalAmount, cashPaid, cashRefunded)
+	if refund.CashAmount > 0 {
+		refundTerminal := req.TerminalID
+		if refundTerminal == 0 && t.TerminalID != nil {
+			refundTerminal = *t.TerminalID
+		}
+		shiftID, err := s.openShiftID(refundTerminal)
+		if err != nil {
+			return nil, errRefundNoShift
+		}
+		refund.ShiftID = &shiftID
+	}
+
+	refund.ID = s.nextID("refunds")
+	refund.CreatedAt = s.Now()
+	for i := range refund.Items {
+		refund.Items[i].ID = s.nextID("refund_details")
+		refund.Items[i].RefundID = refund.ID
+	}
+
+	refunded := make(map[int]int)
+	for _, item := range refund.Items {
+		refunded[item.TransactionDetailID] += item.Quantity
+	}
+	for i := range t.Details {
+		t.Details[i].RefundedQuantity += refunded[t.Details[i].ID]
+	}
+
+	s.restoreStock(returned, refundStockMovement(refund, t.ReceiptNumber, req))
+
+	t.Status = newStatus
+	t.RefundedAmount += refund.TotalAmount
+	t.Refunds = append(t.Refunds, *refund)
+
+	result := *refund
+	result.Items = append([]models.RefundItem{}, refund.Items...)
+	return &result, nil
+}
+
+// restoreStock - produk yang sudah dihapus dilewati, sama seperti UPDATE tanpa baris
+func (s *MemoryStore) restoreStock(quantities map[int]int, movement models.StockMovement) {
+	productIDs := make([]int, 0, len(quantities))
+	for id := range quantities {
+		productIDs = append(productIDs, id)
+	}
+	sort.Ints(productIDs)
+
+	for _, id := range productIDs {
+		p, ok := s.products[id]
+		if !ok {
+			continue
+		}
+		p.Stock += quantities[id]
+		s.products[id] = p
+
+		m := movement
+		m.ProductID, m.Quantity, m.Balance = id, quantities[id], p.Stock
+		s.addStockMovement(&m)
+	}
+}
+
+func hasProduct(t *models.Transaction, productID int) bool {
+	for _, d := range t.Details {
+		if d.ProductID == productID {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneTransaction - salinan lengkap supaya pemanggil tidak bisa mengubah data store
+func (s *MemoryStore) cloneTransaction(t *models.Transaction) *models.Transaction {
+	c := *t
+	if t.VoidedAt != nil {
+		voidedAt := *t.VoidedAt
+		c.VoidedAt = &voidedAt
+	}
+
+	c.Promotions = append([]models.AppliedPromotion{}, t.Promotions...)
+	c.Payments = append([]models.Payment{}, t.Payments...)
+
+	c.Details = make([]models.TransactionDetail, len(t.Details))
+	for i, d := range t.Details {
+		d.Promotions = promotionsForProduct(c.Promotions, d.ProductID)
+		d.Components = slices.Clone(d.Components)
+		c.Details[i] = d
+	}
+
+	c.Refunds = make([]models.Refund, len(t.Refunds))
+	for i, r := range t.Refunds {
+		r.Items = append([]models.RefundItem{}, r.Items...)
+		c.Refunds[i] = r
+	}
+
+	return &c
+}
+
+// intPtrEqual - padanan kolom nullable = value (NULL tidak pernah sama)
+func intPtrEqual(p *int, value int) bool {
+	return p != nil && *p == value
+}
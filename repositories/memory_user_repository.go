@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type MemoryUserRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryUserRepository(store *MemoryStore) *MemoryUserRepository {
+	return &MemoryUserRepository{store: store}
+}
+
+func (repo *MemoryUserRepository) GetAll() ([]models.User, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := make([]models.User, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, *cloneUser(u))
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (repo *MemoryUserRepository) GetByID(id int) (*models.User, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return nil, errUserNotFound
+	}
+	return cloneUser(u), nil
+}
+
+func (repo *MemoryUserRepository) GetByUsername(username string) (*models.User, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if strings.EqualFold(u.Username, username) {
+			return cloneUser(u), nil
+		}
+	}
+	return nil, errUserNotFound
+}
+
+func (repo *MemoryUserRepository) Count() (int, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.users), nil
+}
+
+func (repo *MemoryUserRepository) Create(user *models.User) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.usernameTaken(user.Username, 0) {
+		return errUsernameTaken
+	}
+	if _, ok := s.roles[user.Role]; !ok {
+		return errInvalidRole
+	}
+
+	user.ID = s.nextID("users")
+	user.CreatedAt = s.Now()
+	user.HasPIN = user.PINHash != ""
+	s.users[user.ID] = *cloneUser(*user)
+	return nil
+}
+
+func (repo *MemoryUserRepository) Update(user *models.User) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.users[user.ID]
+	if !ok {
+		return errUserNotFound
+	}
+	if s.usernameTaken(user.Username, user.ID) {
+		return errUsernameTaken
+	}
+	if _, ok := s.roles[user.Role]; !ok {
+		return errInvalidRole
+	}
+
+	user.CreatedAt = existing.CreatedAt
+	user.HasPIN = user.PINHash != ""
+	s.users[user.ID] = *cloneUser(*user)
+	return nil
+}
+
+// usernameTaken - padanan unique index LOWER(username)
+func (s *MemoryStore) usernameTaken(username string, exceptID int) bool {
+	for _, u := range s.users {
+		if u.ID != exceptID && strings.EqualFold(u.Username, username) {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneUser(u models.User) *models.User {
+	u.Permissions = slices.Clone(u.Permissions)
+	u.EffectivePermissions = nil
+	return &u
+}
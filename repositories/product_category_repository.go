@@ -3,19 +3,20 @@ package repositories
 import (
 	"aplikasi-kasir/models"
 	"database/sql"
-	"errors"
 )
 
-type ProductCategoryRepository struct {
+var errProductCategoryNotFound = &models.NotFoundError{Message: "produk category tidak ditemukan"}
+
+type PostgresProductCategoryRepository struct {
 	db *sql.DB
 }
 
-func NewProductCategoryRepository(db *sql.DB) *ProductCategoryRepository {
-	return &ProductCategoryRepository{db: db}
+func NewProductCategoryRepository(db *sql.DB) *PostgresProductCategoryRepository {
+	return &PostgresProductCategoryRepository{db: db}
 }
 
-func (repo *ProductCategoryRepository) GetAll() ([]models.ProductCategory, error) {
-	query := "SELECT id, name FROM product_categories"
+func (repo *PostgresProductCategoryRepository) GetAll() ([]models.ProductCategory, error) {
+	query := "SELECT id, name, tax_rate FROM product_categories"
 	rows, err := repo.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -25,41 +26,50 @@ func (repo *ProductCategoryRepository) GetAll() ([]models.ProductCategory, error
 	products := make([]models.ProductCategory, 0)
 	for rows.Next() {
 		var p models.ProductCategory
-		err := rows.Scan(&p.ID, &p.Name)
+		var taxRate sql.NullFloat64
+		err := rows.Scan(&p.ID, &p.Name, &taxRate)
 		if err != nil {
 			return nil, err
 		}
+		if taxRate.Valid {
+			p.TaxRate = &taxRate.Float64
+		}
 		products = append(products, p)
 	}
 
 	return products, nil
 }
 
-func (repo *ProductCategoryRepository) Create(productCategory *models.ProductCategory) error {
-	query := "INSERT INTO product_categories (name) VALUES ($1) RETURNING id"
-	err := repo.db.QueryRow(query, productCategory.Name).Scan(&productCategory.ID)
+func (repo *PostgresProductCategoryRepository) Create(productCategory *models.ProductCategory) error {
+	query := "INSERT INTO product_categories (name, tax_rate) VALUES ($1, $2) RETURNING id"
+	err := repo.db.QueryRow(query, productCategory.Name, productCategory.TaxRate).Scan(&productCategory.ID)
 	return err
 }
 
 // GetByID - ambil product categories by ID
-func (repo *ProductCategoryRepository) GetByID(id int) (*models.ProductCategory, error) {
-	query := "SELECT id, name FROM product_categories WHERE id = $1"
+func (repo *PostgresProductCategoryRepository) GetByID(id int) (*models.ProductCategory, error) {
+	query := "SELECT id, name, tax_rate FROM product_categories WHERE id = $1"
 
 	var p models.ProductCategory
-	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name)
+	var taxRate sql.NullFloat64
+	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &taxRate)
 	if err == sql.ErrNoRows {
-		return nil, errors.New("produk category tidak ditemukan")
+		return nil, errProductCategoryNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
 
+	if taxRate.Valid {
+		p.TaxRate = &taxRate.Float64
+	}
+
 	return &p, nil
 }
 
-func (repo *ProductCategoryRepository) Update(product *models.ProductCategory) error {
-	query := "UPDATE product_categories SET name = $1 WHERE id = $2"
-	result, err := repo.db.Exec(query, product.Name, product.ID)
+func (repo *PostgresProductCategoryRepository) Update(product *models.ProductCategory) error {
+	query := "UPDATE product_categories SET name = $1, tax_rate = $2 WHERE id = $3"
+	result, err := repo.db.Exec(query, product.Name, product.TaxRate, product.ID)
 	if err != nil {
 		return err
 	}
@@ -70,13 +80,13 @@ func (repo *ProductCategoryRepository) Update(product *models.ProductCategory) e
 	}
 
 	if rows == 0 {
-		return errors.New("produk category tidak ditemukan")
+		return errProductCategoryNotFound
 	}
 
 	return nil
 }
 
-func (repo *ProductCategoryRepository) Delete(id int) error {
+func (repo *PostgresProductCategoryRepository) Delete(id int) error {
 	query := "DELETE FROM product_categories WHERE id = $1"
 	result, err := repo.db.Exec(query, id)
 	if err != nil {
@@ -88,8 +98,8 @@ func (repo *ProductCategoryRepository) Delete(id int) error {
 	}
 
 	if rows == 0 {
-		return errors.New("produk category tidak ditemukan")
+		return errProductCategoryNotFound
 	}
 
-	return err
+	return nil
 }
@@ -3,51 +3,142 @@ package repositories
 import (
 	"aplikasi-kasir/models"
 	"database/sql"
-	"errors"
+	"encoding/json"
+	"sort"
+
+	"github.com/lib/pq"
+)
+
+// error yang sama dipakai implementasi Postgres dan in-memory
+var (
+	errProductNotFound        = &models.NotFoundError{Message: "produk tidak ditemukan"}
+	errInvalidProductCategory = models.NewValidationError("category_id", "kategori tidak ditemukan")
+	errProductSKUTaken        = &models.ConflictError{Message: "SKU sudah dipakai produk lain"}
+	errProductBarcodeTaken    = &models.ConflictError{Message: "barcode sudah dipakai produk lain"}
+	errProductHasVariants     = &models.ConflictError{Message: "produk masih punya varian, hapus variannya dulu"}
+	errVariantOptionsTaken    = &models.ConflictError{Message: "varian dengan kombinasi option yang sama sudah ada"}
+	errInvalidComponent       = models.NewValidationError("recipe", "komponen tidak ditemukan")
+	errProductIsComponent     = &models.ConflictError{Message: "produk dipakai sebagai komponen resep produk lain"}
+	errProductDeleted         = &models.ConflictError{Message: "produk sudah dihapus"}
 )
 
-type ProductRepository struct {
+type PostgresProductRepository struct {
 	db *sql.DB
 }
 
-func NewProductRepository(db *sql.DB) *ProductRepository {
-	return &ProductRepository{db: db}
+func NewProductRepository(db *sql.DB) *PostgresProductRepository {
+	return &PostgresProductRepository{db: db}
 }
 
-// CREATE TABLE IF NOT EXISTS transactions (
-// 	id SERIAL PRIMARY KEY,
-// 	total_amount INT NOT NULL,
-// 	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-// );
+// productSelect - produk beserta kategori, daftar barcode dan resepnya
+const productSelect = `
+	SELECT
+		p.id,
+		p.name,
+		COALESCE(p.sku, ''),
+		COALESCE((SELECT array_agg(b.barcode ORDER BY b.barcode) FROM product_barcodes b WHERE b.product_id = p.id), '{}'),
+		p.price,
+		p.stock,
+		p.category_id,
+		p.tax_rate,
+		p.tax_exempt,
+		p.active,
+		p.parent_id,
+		p.options,
+		p.option_values,
+		COALESCE((
+			SELECT json_agg(json_build_object('component_id', pc.component_id, 'component_name', cp.name, 'quantity', pc.quantity) ORDER BY pc.component_id)
+			FROM product_components pc
+			JOIN products cp ON cp.id = pc.component_id
+			WHERE pc.product_id = p.id
+		), '[]'),
+		c.id,
+		c.name,
+		c.tax_rate
+	FROM products p
+	LEFT JOIN product_categories c
+		ON c.id = p.category_id
+`
+
+func scanProduct(row rowScanner) (*models.Product, error) {
+	var p models.Product
 
-// CREATE TABLE IF NOT EXISTS transaction_details (
-// 	id SERIAL PRIMARY KEY,
-// 	transaction_id INT REFERENCES transactions(id) ON DELETE CASCADE,
-// 	product_id INT REFERENCES products(id),
-// 	product_name VARCHAR(200) NOT NULL,
-// 	product_price INT NOT NULL,
-// 	quantity INT NOT NULL,
-// 	subtotal INT NOT NULL,
-// );
+	var barcodes pq.StringArray
+	var parentID sql.NullInt64
+	var options []byte
+	var optionValues pq.StringArray
+	var recipe []byte
+	var categoryID sql.NullInt64
+	var taxRate sql.NullFloat64
+	var catID sql.NullInt64
+	var catName sql.NullString
+	var catTaxRate sql.NullFloat64
 
-func (repo *ProductRepository) GetAll(name string) ([]models.Product, error) {
-	query := `
-		SELECT
-			p.id,
-			p.name,
-			p.price,
-			p.stock,
-			p.category_id,
-			c.id,
-			c.name
-		FROM products p
-		LEFT JOIN product_categories c
-			ON c.id = p.category_id
-	`
+	err := row.Scan(
+		&p.ID,
+		&p.Name,
+		&p.SKU,
+		&barcodes,
+		&p.Price,
+		&p.Stock,
+		&categoryID,
+		&taxRate,
+		&p.TaxExempt,
+		&p.Active,
+		&parentID,
+		&options,
+		&optionValues,
+		&recipe,
+		&catID,
+		&catName,
+		&catTaxRate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Barcodes = append([]string{}, barcodes...)
+	p.ParentID = nullIntPtr(parentID)
+	p.OptionValues = append([]string{}, optionValues...)
+	if err := json.Unmarshal(options, &p.Options); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(recipe, &p.Recipe); err != nil {
+		return nil, err
+	}
+
+	// set category_id
+	if categoryID.Valid {
+		id := int(categoryID.Int64)
+		p.CategoryID = &id
+	}
+
+	if taxRate.Valid {
+		p.TaxRate = &taxRate.Float64
+	}
+
+	// set category object (jika ada)
+	if catID.Valid {
+		id := int(catID.Int64)
+		p.Category = &models.ProductCategory{
+			ID:   id,
+			Name: catName.String,
+		}
+		if catTaxRate.Valid {
+			p.Category.TaxRate = &catTaxRate.Float64
+		}
+	}
+
+	return &p, nil
+}
+
+// GetAll - produk aktif, produk yang sudah dihapus (soft delete) tidak ikut
+func (repo *PostgresProductRepository) GetAll(name string) ([]models.Product, error) {
+	query := productSelect + " WHERE p.active"
 
 	var args []any
 	if name != "" {
-		query += " WHERE p.name ILIKE $1"
+		query += " AND p.name ILIKE $1"
 		args = append(args, "%"+name+"%")
 	}
 
@@ -60,41 +151,11 @@ func (repo *ProductRepository) GetAll(name string) ([]models.Product, error) {
 	products := []models.Product{}
 
 	for rows.Next() {
-		var p models.Product
-
-		var categoryID sql.NullInt64
-		var catID sql.NullInt64
-		var catName sql.NullString
-
-		err := rows.Scan(
-			&p.ID,
-			&p.Name,
-			&p.Price,
-			&p.Stock,
-			&categoryID,
-			&catID,
-			&catName,
-		)
+		p, err := scanProduct(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		// set category_id
-		if categoryID.Valid {
-			id := int(categoryID.Int64)
-			p.CategoryID = &id
-		}
-
-		// set category object (jika ada)
-		if catID.Valid {
-			id := int(catID.Int64)
-			p.Category = &models.ProductCategory{
-				ID:   id,
-				Name: catName.String,
-			}
-		}
-
-		products = append(products, p)
+		products = append(products, *p)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -104,102 +165,313 @@ func (repo *ProductRepository) GetAll(name string) ([]models.Product, error) {
 	return products, nil
 }
 
-func (repo *ProductRepository) Create(product *models.Product) error {
-	query := "INSERT INTO products (name, price, stock, category_id) VALUES ($1, $2, $3, $4) RETURNING id"
-	err := repo.db.QueryRow(query, product.Name, product.Price, product.Stock, product.CategoryID).Scan(&product.ID)
-	return err
-}
+func (repo *PostgresProductRepository) Create(product *models.Product) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	options, err := json.Marshal(product.Options)
+	if err != nil {
+		return err
+	}
 
-func (repo *ProductRepository) GetByID(id int) (*models.Product, error) {
 	query := `
-		SELECT
-			p.id,
-			p.name,
-			p.price,
-			p.stock,
-			p.category_id,
-			c.id,
-			c.name
-		FROM products p
-		LEFT JOIN product_categories c
-			ON c.id = p.category_id
-		WHERE p.id = $1
+		INSERT INTO products (name, sku, price, stock, category_id, tax_rate, tax_exempt, parent_id, options, option_values)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
 	`
+	err = tx.QueryRow(
+		query, product.Name, product.SKU, product.Price, product.Stock, product.CategoryID, product.TaxRate, product.TaxExempt,
+		product.ParentID, options, pq.StringArray(product.OptionValues),
+	).Scan(&product.ID)
+	if err := productWriteError(err); err != nil {
+		return err
+	}
+	product.Active = true
 
-	var p models.Product
-
-	var categoryID sql.NullInt64
-	var catID sql.NullInt64
-	var catName sql.NullString
+	if err := replaceBarcodes(tx, product.ID, product.Barcodes); err != nil {
+		return err
+	}
+	if err := replaceRecipe(tx, product.ID, product.Recipe); err != nil {
+		return err
+	}
+	if m := initialStockMovement(product); m != nil {
+		if err := insertStockMovement(tx, m); err != nil {
+			return err
+		}
+	}
 
-	err := repo.db.QueryRow(query, id).Scan(
-		&p.ID,
-		&p.Name,
-		&p.Price,
-		&p.Stock,
-		&categoryID,
-		&catID,
-		&catName,
-	)
+	return tx.Commit()
+}
 
-	if err == sql.ErrNoRows {
-		return nil, errors.New("produk tidak ditemukan")
+// GetByID - produk induk dikembalikan beserta seluruh varian aktifnya. Produk yang sudah dihapus
+// tetap bisa dibaca (active = false) untuk riwayat stok dan transaksi.
+func (repo *PostgresProductRepository) GetByID(id int) (*models.Product, error) {
+	p, err := repo.getOne(productSelect+" WHERE p.id = $1", id)
+	if err != nil || p.ParentID != nil {
+		return p, err
 	}
+
+	rows, err := repo.db.Query(productSelect+" WHERE p.parent_id = $1 AND p.active ORDER BY p.id", id)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	// set category_id
-	if categoryID.Valid {
-		cid := int(categoryID.Int64)
-		p.CategoryID = &cid
+	for rows.Next() {
+		variant, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		p.Variants = append(p.Variants, *variant)
 	}
 
-	// set category object
-	if catID.Valid {
-		cid := int(catID.Int64)
-		p.Category = &models.ProductCategory{
-			ID:   cid,
-			Name: catName.String,
+	return p, rows.Err()
+}
+
+// GetByBarcode - lookup hasil scan, lewat primary key product_barcodes. Lookup hanya mencari
+// produk aktif.
+func (repo *PostgresProductRepository) GetByBarcode(barcode string) (*models.Product, error) {
+	return repo.getOne(productSelect+" WHERE p.id = (SELECT product_id FROM product_barcodes WHERE barcode = $1) AND p.active", barcode)
+}
+
+func (repo *PostgresProductRepository) GetBySKU(sku string) (*models.Product, error) {
+	return repo.getOne(productSelect+" WHERE p.sku = $1 AND p.active", sku)
+}
+
+// IDsByBarcode - product_id untuk banyak barcode sekaligus (item checkout hasil scan), barcode
+// yang tidak terdaftar tidak ada di map
+func (repo *PostgresProductRepository) IDsByBarcode(barcodes []string) (map[string]int, error) {
+	return repo.idsByCode(`
+		SELECT b.barcode, b.product_id
+		FROM product_barcodes b
+		JOIN products p ON p.id = b.product_id
+		WHERE b.barcode = ANY($1) AND p.active
+	`, barcodes)
+}
+
+func (repo *PostgresProductRepository) IDsBySKU(skus []string) (map[string]int, error) {
+	return repo.idsByCode("SELECT sku, id FROM products WHERE sku = ANY($1) AND active", skus)
+}
+
+func (repo *PostgresProductRepository) idsByCode(query string, codes []string) (map[string]int, error) {
+	ids := make(map[string]int, len(codes))
+	if len(codes) == 0 {
+		return ids, nil
+	}
+
+	rows, err := repo.db.Query(query, pq.StringArray(codes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code string
+		var id int
+		if err := rows.Scan(&code, &id); err != nil {
+			return nil, err
 		}
+		ids[code] = id
 	}
 
-	return &p, nil
+	return ids, rows.Err()
 }
 
-func (repo *ProductRepository) Update(product *models.Product) error {
-	query := "UPDATE products SET name = $1, price = $2, stock = $3, category_id = $4 WHERE id = $5"
-	result, err := repo.db.Exec(query, product.Name, product.Price, product.Stock, product.CategoryID, product.ID)
+func (repo *PostgresProductRepository) getOne(query string, arg any) (*models.Product, error) {
+	p, err := scanProduct(repo.db.QueryRow(query, arg))
+	if err == sql.ErrNoRows {
+		return nil, errProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (repo *PostgresProductRepository) Update(product *models.Product) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	options, err := json.Marshal(product.Options)
 	if err != nil {
 		return err
 	}
 
+	query := `
+		UPDATE products
+		SET name = $1, sku = NULLIF($2, ''), price = $3, category_id = $4, tax_rate = $5, tax_exempt = $6,
+			options = $7, option_values = $8
+		WHERE id = $9
+	`
+	result, err := tx.Exec(
+		query, product.Name, product.SKU, product.Price, product.CategoryID, product.TaxRate, product.TaxExempt,
+		options, pq.StringArray(product.OptionValues), product.ID,
+	)
+	if err := productWriteError(err); err != nil {
+		return err
+	}
+
 	rows, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
 
 	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
+		return errProductNotFound
+	}
+
+	if err := replaceBarcodes(tx, product.ID, product.Barcodes); err != nil {
+		return err
+	}
+	if err := replaceRecipe(tx, product.ID, product.Recipe); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// productWriteError - terjemahkan error insert / update products ke error domain
+func productWriteError(err error) error {
+	switch {
+	case isForeignKeyViolation(err):
+		return errInvalidProductCategory
+	case isUniqueViolationOn(err, "products_variant_options_key"):
+		return errVariantOptionsTaken
+	case isUniqueViolation(err):
+		return errProductSKUTaken
+	}
+	return err
+}
+
+// replaceBarcodes - ganti seluruh barcode produk dengan daftar baru
+func replaceBarcodes(tx *sql.Tx, productID int, barcodes []string) error {
+	if _, err := tx.Exec("DELETE FROM product_barcodes WHERE product_id = $1", productID); err != nil {
+		return err
+	}
+	if len(barcodes) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(
+		"INSERT INTO product_barcodes (barcode, product_id) SELECT unnest($1::text[]), $2",
+		pq.StringArray(barcodes), productID,
+	)
+	if isUniqueViolation(err) {
+		return errProductBarcodeTaken
+	}
+	return err
+}
+
+// replaceRecipe - ganti seluruh resep produk. Resep hanya satu tingkat: produk yang dipakai
+// sebagai komponen tidak bisa punya resep sendiri.
+func replaceRecipe(tx *sql.Tx, productID int, recipe []models.RecipeItem) error {
+	if _, err := tx.Exec("DELETE FROM product_components WHERE product_id = $1", productID); err != nil {
+		return err
+	}
+	if len(recipe) == 0 {
+		return nil
+	}
+
+	var isComponent bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM product_components WHERE component_id = $1)", productID).Scan(&isComponent); err != nil {
+		return err
+	}
+	if isComponent {
+		return errProductIsComponent
 	}
 
-	return nil
+	componentIDs := make([]int64, len(recipe))
+	quantities := make([]int64, len(recipe))
+	for i, item := range recipe {
+		componentIDs[i] = int64(item.ComponentID)
+		quantities[i] = int64(item.Quantity)
+	}
+	_, err := tx.Exec(
+		"INSERT INTO product_components (product_id, component_id, quantity) SELECT $1, unnest($2::int[]), unnest($3::int[])",
+		productID, pq.Array(componentIDs), pq.Array(quantities),
+	)
+	if isForeignKeyViolation(err) {
+		return errInvalidComponent
+	}
+	return err
 }
 
-func (repo *ProductRepository) Delete(id int) error {
-	query := "DELETE FROM products WHERE id = $1"
-	result, err := repo.db.Exec(query, id)
+// AddBarcodes - tambah barcode (product_id -> barcode) dalam satu transaksi, gagal satu batal semua
+func (repo *PostgresProductRepository) AddBarcodes(barcodes map[int]string) error {
+	tx, err := repo.db.Begin()
 	if err != nil {
 		return err
 	}
-	rows, err := result.RowsAffected()
+	defer tx.Rollback()
+
+	productIDs := make([]int, 0, len(barcodes))
+	for id := range barcodes {
+		productIDs = append(productIDs, id)
+	}
+	sort.Ints(productIDs)
+
+	for _, id := range productIDs {
+		_, err := tx.Exec("INSERT INTO product_barcodes (barcode, product_id) VALUES ($1, $2)", barcodes[id], id)
+		if isForeignKeyViolation(err) {
+			return errProductNotFound
+		}
+		if isUniqueViolation(err) {
+			return errProductBarcodeTaken
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete - soft delete (active = false). Produk dengan riwayat stok atau transaksi tidak bisa
+// dihapus permanen, barcode dan SKU tetap tersimpan tapi tidak ikut lookup.
+func (repo *PostgresProductRepository) Delete(id int) error {
+	tx, err := repo.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
+	var active, hasVariants, isComponent bool
+	err = tx.QueryRow(`
+		SELECT
+			p.active,
+			EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id AND v.active),
+			EXISTS (
+				SELECT 1 FROM product_components pc
+				JOIN products cp ON cp.id = pc.product_id
+				WHERE pc.component_id = p.id AND cp.active
+			)
+		FROM products p
+		WHERE p.id = $1
+		FOR UPDATE
+	`, id).Scan(&active, &hasVariants, &isComponent)
+	if err == sql.ErrNoRows {
+		return errProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case !active:
+		return errProductDeleted
+	case hasVariants:
+		return errProductHasVariants
+	case isComponent:
+		return errProductIsComponent
 	}
 
-	return err
+	if _, err := tx.Exec("UPDATE products SET active = FALSE WHERE id = $1", id); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"sort"
+)
+
+// cartLine - satu baris keranjang yang sedang dihitung harganya
+type cartLine struct {
+	productID  int
+	categoryID *int
+	price      int
+	quantity   int
+	discount   int
+}
+
+func (l cartLine) subtotal() int {
+	return l.price * l.quantity
+}
+
+func (l cartLine) net() int {
+	return l.subtotal() - l.discount
+}
+
+// applyPromotions - hitung diskon untuk setiap baris keranjang secara deterministik:
+//  1. bundle, urut prioritas; quantity yang masuk paket tidak ikut promo item
+//  2. promo item (percentage, fixed, buy_x_get_y): per baris dipilih satu promo dengan potongan terbesar
+//  3. promo total belanja: dipilih satu promo dengan potongan terbesar, lalu dibagi proporsional ke baris
+//
+// Diskon ditambahkan ke lines[i].discount. Kembalian berisi rincian promo yang dipakai.
+func applyPromotions(lines []cartLine, promotions []models.Promotion) []models.AppliedPromotion {
+	sorted := make([]models.Promotion, len(promotions))
+	copy(sorted, promotions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority > sorted[j].Priority
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	index := make(map[int]int)
+	remaining := make([]int, len(lines))
+	for i, l := range lines {
+		index[l.productID] = i
+		remaining[i] = l.quantity
+	}
+
+	applied := make([]models.AppliedPromotion, 0)
+	record := func(p models.Promotion, productID *int, amount int) {
+		if amount <= 0 {
+			return
+		}
+		id := p.ID
+		applied = append(applied, models.AppliedPromotion{
+			PromotionID:    &id,
+			PromotionName:  p.Name,
+			ProductID:      productID,
+			DiscountAmount: amount,
+		})
+	}
+
+	// 1. bundle
+	for _, p := range sorted {
+		if p.Type != models.PromotionTypeBundle || len(p.BundleItems) == 0 {
+			continue
+		}
+
+		count, normalPrice := -1, 0
+		for _, item := range p.BundleItems {
+			idx, ok := index[item.ProductID]
+			if !ok || item.Quantity <= 0 {
+				count = 0
+				break
+			}
+			if c := remaining[idx] / item.Quantity; count < 0 || c < count {
+				count = c
+			}
+			normalPrice += lines[idx].price * item.Quantity
+		}
+
+		if count <= 0 || normalPrice <= p.BundlePrice {
+			continue
+		}
+
+		discount := count * (normalPrice - p.BundlePrice)
+		allocated := 0
+		for k, item := range p.BundleItems {
+			idx := index[item.ProductID]
+			share := discount * lines[idx].price * item.Quantity / normalPrice
+			if k == len(p.BundleItems)-1 {
+				share = discount - allocated
+			}
+			allocated += share
+
+			lines[idx].discount += share
+			remaining[idx] -= count * item.Quantity
+
+			productID := lines[idx].productID
+			record(p, &productID, share)
+		}
+	}
+
+	// 2. promo per item
+	for i := range lines {
+		if remaining[i] == 0 {
+			continue
+		}
+
+		var best *models.Promotion
+		bestDiscount := 0
+		for k := range sorted {
+			p := &sorted[k]
+			if !promotionAppliesToLine(p, lines[i]) {
+				continue
+			}
+			if d := lineDiscount(p, lines[i].price, remaining[i]); d > bestDiscount {
+				best, bestDiscount = p, d
+			}
+		}
+
+		if best != nil {
+			lines[i].discount += bestDiscount
+			productID := lines[i].productID
+			record(*best, &productID, bestDiscount)
+		}
+	}
+
+	// 3. promo total belanja
+	base := 0
+	for _, l := range lines {
+		base += l.net()
+	}
+	if base <= 0 {
+		return applied
+	}
+
+	var best *models.Promotion
+	bestDiscount := 0
+	for k := range sorted {
+		p := &sorted[k]
+		if base < p.MinSpend {
+			continue
+		}
+
+		d := 0
+		switch p.Type {
+		case models.PromotionTypeCartPercentage:
+			d = base * p.Value / 100
+			if p.MaxDiscount > 0 && d > p.MaxDiscount {
+				d = p.MaxDiscount
+			}
+		case models.PromotionTypeCartFixed:
+			d = min(p.Value, base)
+		}
+
+		if d > bestDiscount {
+			best, bestDiscount = p, d
+		}
+	}
+
+	if best != nil {
+		allocated := 0
+		for i := range lines {
+			share := bestDiscount * lines[i].net() / base
+			if i == len(lines)-1 {
+				share = bestDiscount - allocated
+			}
+			allocated += share
+			lines[i].discount += share
+		}
+		record(*best, nil, bestDiscount)
+	}
+
+	return applied
+}
+
+func promotionAppliesToLine(p *models.Promotion, line cartLine) bool {
+	switch p.Type {
+	case models.PromotionTypePercentage, models.PromotionTypeFixed, models.PromotionTypeBuyXGetY:
+	default:
+		return false
+	}
+
+	if len(p.ProductIDs) > 0 {
+		found := false
+		for _, id := range p.ProductIDs {
+			if id == line.productID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if p.CategoryID != nil && (line.categoryID == nil || *line.categoryID != *p.CategoryID) {
+		return false
+	}
+
+	return true
+}
+
+// lineDiscount - potongan untuk quantity unit produk dengan harga satuan price
+func lineDiscount(p *models.Promotion, price, quantity int) int {
+	switch p.Type {
+	case models.PromotionTypePercentage:
+		return price * quantity * p.Value / 100
+	case models.PromotionTypeFixed:
+		return min(p.Value, price) * quantity
+	case models.PromotionTypeBuyXGetY:
+		if p.BuyQuantity <= 0 || p.GetQuantity <= 0 {
+			return 0
+		}
+		free := quantity / (p.BuyQuantity + p.GetQuantity) * p.GetQuantity
+		return free * price
+	}
+	return 0
+}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"testing"
+)
+
+func TestApplyPromotions(t *testing.T) {
+	drinks := 7
+
+	tests := []struct {
+		name       string
+		lines      []cartLine
+		promotions []models.Promotion
+		discounts  []int // diskon per baris setelah promo
+		applied    int   // jumlah baris transaction_promotions
+	}{
+		{
+			name:      "tanpa promo",
+			lines:     []cartLine{{productID: 1, price: 10000, quantity: 2}},
+			discounts: []int{0},
+		},
+		{
+			name:  "percentage hanya untuk produk terdaftar",
+			lines: []cartLine{{productID: 1, price: 10000, quantity: 2}, {productID: 2, price: 5000, quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Value: 10, ProductIDs: []int{1}},
+			},
+			discounts: []int{2000, 0},
+			applied:   1,
+		},
+		{
+			name:  "fixed tidak melebihi harga satuan",
+			lines: []cartLine{{productID: 1, price: 3000, quantity: 2}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypeFixed, Value: 5000},
+			},
+			discounts: []int{6000},
+			applied:   1,
+		},
+		{
+			name:  "promo item dipilih yang potongannya terbesar",
+			lines: []cartLine{{productID: 1, price: 10000, quantity: 3}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Value: 10, Priority: 10},
+				{ID: 2, Type: models.PromotionTypeFixed, Value: 2000},
+			},
+			discounts: []int{6000},
+			applied:   1,
+		},
+		{
+			name:  "buy 2 get 1",
+			lines: []cartLine{{productID: 1, price: 4000, quantity: 7}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypeBuyXGetY, BuyQuantity: 2, GetQuantity: 1},
+			},
+			discounts: []int{8000},
+			applied:   1,
+		},
+		{
+			name:  "kategori tidak cocok",
+			lines: []cartLine{{productID: 1, price: 10000, quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Value: 50, CategoryID: &drinks},
+			},
+			discounts: []int{0},
+		},
+		{
+			name: "kategori cocok",
+			lines: []cartLine{
+				{productID: 1, categoryID: &drinks, price: 10000, quantity: 1},
+			},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypePercentage, Value: 50, CategoryID: &drinks},
+			},
+			discounts: []int{5000},
+			applied:   1,
+		},
+		{
+			name:  "bundle memakai quantity sebelum promo item",
+			lines: []cartLine{{productID: 1, price: 10000, quantity: 2}, {productID: 2, price: 5000, quantity: 1}},
+			promotions: []models.Promotion{
+				{
+					ID: 1, Type: models.PromotionTypeBundle, BundlePrice: 12000,
+					BundleItems: []models.PromotionBundleItem{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 1}},
+				},
+				{ID: 2, Type: models.PromotionTypePercentage, Value: 10},
+			},
+			// bundle: normal 15000, potongan 3000 dibagi 2000/1000; sisa 1 unit produk 1 kena 10%
+			discounts: []int{3000, 1000},
+			applied:   3,
+		},
+		{
+			name:  "cart percentage dengan minimal belanja dan batas potongan",
+			lines: []cartLine{{productID: 1, price: 30000, quantity: 2}, {productID: 2, price: 40000, quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypeCartPercentage, Value: 10, MinSpend: 100000, MaxDiscount: 5000},
+			},
+			// potongan 5000 dibagi proporsional 60000:40000
+			discounts: []int{3000, 2000},
+			applied:   1,
+		},
+		{
+			name:  "cart promo tidak berlaku di bawah minimal belanja",
+			lines: []cartLine{{productID: 1, price: 30000, quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypeCartFixed, Value: 5000, MinSpend: 50000},
+			},
+			discounts: []int{0},
+		},
+		{
+			name:  "cart fixed dibagi proporsional, sisa pembulatan ke baris terakhir",
+			lines: []cartLine{{productID: 1, price: 1000, quantity: 1}, {productID: 2, price: 1000, quantity: 1}, {productID: 3, price: 1000, quantity: 1}},
+			promotions: []models.Promotion{
+				{ID: 1, Type: models.PromotionTypeCartFixed, Value: 100},
+			},
+			discounts: []int{33, 33, 34},
+			applied:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied := applyPromotions(tt.lines, tt.promotions)
+
+			if len(applied) != tt.applied {
+				t.Errorf("applied promotions = %d, want %d (%+v)", len(applied), tt.applied, applied)
+			}
+
+			for i, want := range tt.discounts {
+				if got := tt.lines[i].discount; got != want {
+					t.Errorf("line %d discount = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+}
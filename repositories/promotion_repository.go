@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/lib/pq"
+)
+
+type PostgresPromotionRepository struct {
+	db *sql.DB
+}
+
+func NewPromotionRepository(db *sql.DB) *PostgresPromotionRepository {
+	return &PostgresPromotionRepository{db: db}
+}
+
+const promotionColumns = `
+	id, name, type, value, min_spend, max_discount, product_ids, category_id,
+	buy_quantity, get_quantity, bundle_items, bundle_price, priority, active,
+	starts_at, ends_at, created_at
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPromotion(row rowScanner) (*models.Promotion, error) {
+	var p models.Promotion
+	var productIDs pq.Int64Array
+	var categoryID sql.NullInt64
+	var bundleItems []byte
+	var startsAt, endsAt sql.NullTime
+
+	err := row.Scan(
+		&p.ID, &p.Name, &p.Type, &p.Value, &p.MinSpend, &p.MaxDiscount, &productIDs, &categoryID,
+		&p.BuyQuantity, &p.GetQuantity, &bundleItems, &p.BundlePrice, &p.Priority, &p.Active,
+		&startsAt, &endsAt, &p.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ProductIDs = make([]int, len(productIDs))
+	for i, id := range productIDs {
+		p.ProductIDs[i] = int(id)
+	}
+
+	if categoryID.Valid {
+		id := int(categoryID.Int64)
+		p.CategoryID = &id
+	}
+
+	p.BundleItems = []models.PromotionBundleItem{}
+	if len(bundleItems) > 0 {
+		if err := json.Unmarshal(bundleItems, &p.BundleItems); err != nil {
+			return nil, err
+		}
+	}
+
+	if startsAt.Valid {
+		p.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		p.EndsAt = &endsAt.Time
+	}
+
+	return &p, nil
+}
+
+func promotionArgs(p *models.Promotion) ([]any, error) {
+	productIDs := make(pq.Int64Array, len(p.ProductIDs))
+	for i, id := range p.ProductIDs {
+		productIDs[i] = int64(id)
+	}
+
+	bundleItems, err := json.Marshal(p.BundleItems)
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{
+		p.Name, p.Type, p.Value, p.MinSpend, p.MaxDiscount, productIDs, p.CategoryID,
+		p.BuyQuantity, p.GetQuantity, bundleItems, p.BundlePrice, p.Priority, p.Active,
+		p.StartsAt, p.EndsAt,
+	}, nil
+}
+
+func (repo *PostgresPromotionRepository) GetAll() ([]models.Promotion, error) {
+	rows, err := repo.db.Query("SELECT " + promotionColumns + " FROM promotions ORDER BY priority DESC, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := make([]models.Promotion, 0)
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, *p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+func (repo *PostgresPromotionRepository) Create(promotion *models.Promotion) error {
+	args, err := promotionArgs(promotion)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO promotions (
+			name, type, value, min_spend, max_discount, product_ids, category_id,
+			buy_quantity, get_quantity, bundle_items, bundle_price, priority, active,
+			starts_at, ends_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id, created_at
+	`
+	return repo.db.QueryRow(query, args...).Scan(&promotion.ID, &promotion.CreatedAt)
+}
+
+func (repo *PostgresPromotionRepository) GetByID(id int) (*models.Promotion, error) {
+	p, err := scanPromotion(repo.db.QueryRow("SELECT "+promotionColumns+" FROM promotions WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, &models.NotFoundError{Message: "promo tidak ditemukan"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (repo *PostgresPromotionRepository) Update(promotion *models.Promotion) error {
+	args, err := promotionArgs(promotion)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE promotions SET
+			name = $1, type = $2, value = $3, min_spend = $4, max_discount = $5, product_ids = $6,
+			category_id = $7, buy_quantity = $8, get_quantity = $9, bundle_items = $10,
+			bundle_price = $11, priority = $12, active = $13, starts_at = $14, ends_at = $15
+		WHERE id = $16
+		RETURNING created_at
+	`
+	err = repo.db.QueryRow(query, append(args, promotion.ID)...).Scan(&promotion.CreatedAt)
+	if err == sql.ErrNoRows {
+		return &models.NotFoundError{Message: "promo tidak ditemukan"}
+	}
+
+	return err
+}
+
+func (repo *PostgresPromotionRepository) Delete(id int) error {
+	result, err := repo.db.Exec("DELETE FROM promotions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return &models.NotFoundError{Message: "promo tidak ditemukan"}
+	}
+
+	return nil
+}
+
+// activePromotions - promo aktif yang berlaku saat ini, dibaca di dalam transaksi checkout
+func activePromotions(q queryer) ([]models.Promotion, error) {
+	rows, err := q.Query(`
+		SELECT ` + promotionColumns + `
+		FROM promotions
+		WHERE active
+			AND (starts_at IS NULL OR starts_at <= CURRENT_TIMESTAMP)
+			AND (ends_at IS NULL OR ends_at > CURRENT_TIMESTAMP)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := make([]models.Promotion, 0)
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, *p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
@@ -3,95 +3,652 @@ package repositories
 import (
 	"aplikasi-kasir/models"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
 	"time"
 )
 
-type ReportRepository struct {
+type PostgresReportRepository struct {
 	db *sql.DB
 }
 
-func NewReportRepository(db *sql.DB) *ReportRepository {
-	return &ReportRepository{db: db}
+func NewReportRepository(db *sql.DB) *PostgresReportRepository {
+	return &PostgresReportRepository{db: db}
 }
 
-func (repo *ReportRepository) GetDailyReport() (*models.DailyReport, error) {
-	report := &models.DailyReport{}
+func (repo *PostgresReportRepository) GetDailyReport() (*models.DailyReport, error) {
+	// pakai tanggal database supaya konsisten dengan DATE(created_at)
+	var today time.Time
+	if err := repo.db.QueryRow("SELECT CURRENT_DATE").Scan(&today); err != nil {
+		return nil, err
+	}
+
+	report, err := repo.GetReportByDateRange(today, today)
+	if err != nil {
+		return nil, err
+	}
+
+	daily := models.DailyReport(*report)
+	return &daily, nil
+}
+
+func (repo *PostgresReportRepository) GetReportByDateRange(startDate, endDate time.Time) (*models.DateRangeReport, error) {
+	return reportByDateRange(repo.db, startDate, endDate)
+}
+
+// reportByDateRange - q bisa *sql.Tx supaya Z report dihitung di dalam transaksi yang memegang kunci hari
+func reportByDateRange(q queryer, startDate, endDate time.Time) (*models.DateRangeReport, error) {
+	report := &models.DateRangeReport{}
 
-	// Get total revenue for today
-	err := repo.db.QueryRow(`
-		SELECT COALESCE(SUM(total_amount), 0) 
+	// Get sales, discount, tax totals and total transactions for date range (transaksi void tidak dihitung)
+	var totalAmount int
+	err := q.QueryRow(`
+		SELECT
+			COALESCE(SUM(subtotal_amount), 0),
+			COALESCE(SUM(discount_amount), 0),
+			COALESCE(SUM(tax_base), 0),
+			COALESCE(SUM(tax_amount), 0),
+			COALESCE(SUM(service_charge), 0),
+			COALESCE(SUM(total_amount), 0),
+			COUNT(*)
 		FROM transactions 
-		WHERE DATE(created_at) = CURRENT_DATE
-	`).Scan(&report.TotalRevenue)
+		WHERE DATE(created_at) >= $1 AND DATE(created_at) <= $2
+			AND status <> 'voided'
+	`, startDate, endDate).Scan(
+		&report.GrossSales,
+		&report.TotalDiskon,
+		&report.DPP,
+		&report.TotalPajak,
+		&report.ServiceCharge,
+		&totalAmount,
+		&report.TotalTransaksi,
+	)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
 
-	// Get total transactions for today
-	err = repo.db.QueryRow(`
-		SELECT COALESCE(COUNT(*), 0) 
-		FROM transactions 
-		WHERE DATE(created_at) = CURRENT_DATE
-	`).Scan(&report.TotalTransaksi)
+	// Get total refunds created in date range
+	err = q.QueryRow(`
+		SELECT COALESCE(SUM(total_amount), 0), COALESCE(SUM(tax_amount), 0)
+		FROM refunds
+		WHERE DATE(created_at) >= $1 AND DATE(created_at) <= $2
+	`, startDate, endDate).Scan(&report.TotalRefund, &report.PajakRefund)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
 
-	// Get best-selling product for today
-	err = repo.db.QueryRow(`
-		SELECT p.name, SUM(td.quantity)
+	report.NetRevenue = totalAmount - report.TotalRefund
+
+	// Get best-selling product for date range, varian dihitung sebagai produk induknya. Dikelompokkan
+	// per product_id saja supaya produk yang di-rename tidak terpecah, nama dari snapshot penjualan terbaru.
+	err = q.QueryRow(`
+		SELECT (ARRAY_AGG(`+parentNameSnapshot+` ORDER BY td.id DESC))[1], SUM(td.quantity)
 		FROM transaction_details td
 		JOIN transactions t ON td.transaction_id = t.id
-		JOIN products p ON td.product_id = p.id
-		WHERE DATE(t.created_at) = CURRENT_DATE
-		GROUP BY p.id, p.name
-		ORDER BY SUM(td.quantity) DESC
+		WHERE DATE(t.created_at) >= $1 AND DATE(t.created_at) <= $2
+			AND t.status <> 'voided'
+		GROUP BY COALESCE(td.parent_id, td.product_id)
+		ORDER BY SUM(td.quantity) DESC, COALESCE(td.parent_id, td.product_id)
 		LIMIT 1
-	`).Scan(&report.ProdukTerlaris.Nama, &report.ProdukTerlaris.QtyTerjual)
+	`, startDate, endDate).Scan(&report.ProdukTerlaris.Nama, &report.ProdukTerlaris.QtyTerjual)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
 
+	// Get payment method breakdown for date range
+	rows, err := q.Query(`
+		SELECT p.method, COUNT(DISTINCT p.transaction_id), SUM(p.amount)
+		FROM payments p
+		JOIN transactions t ON p.transaction_id = t.id
+		WHERE DATE(t.created_at) >= $1 AND DATE(t.created_at) <= $2
+			AND t.status <> 'voided'
+		GROUP BY p.method
+		ORDER BY p.method
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report.Pembayaran = make([]models.PaymentMethodTotal, 0)
+	for rows.Next() {
+		var pm models.PaymentMethodTotal
+		if err := rows.Scan(&pm.Method, &pm.TotalTransaksi, &pm.Amount); err != nil {
+			return nil, err
+		}
+		report.Pembayaran = append(report.Pembayaran, pm)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get discount totals per promotion for date range
+	promoRows, err := q.Query(`
+		SELECT tp.promotion_id, tp.promotion_name, COUNT(DISTINCT tp.transaction_id), SUM(tp.discount_amount)
+		FROM transaction_promotions tp
+		JOIN transactions t ON tp.transaction_id = t.id
+		WHERE DATE(t.created_at) >= $1 AND DATE(t.created_at) <= $2
+			AND t.status <> 'voided'
+		GROUP BY tp.promotion_id, tp.promotion_name
+		ORDER BY SUM(tp.discount_amount) DESC
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer promoRows.Close()
+
+	report.Promosi = make([]models.PromotionTotal, 0)
+	for promoRows.Next() {
+		var pt models.PromotionTotal
+		var promotionID sql.NullInt64
+		if err := promoRows.Scan(&promotionID, &pt.PromotionName, &pt.TotalTransaksi, &pt.DiscountAmount); err != nil {
+			return nil, err
+		}
+		if promotionID.Valid {
+			id := int(promotionID.Int64)
+			pt.PromotionID = &id
+		}
+		report.Promosi = append(report.Promosi, pt)
+	}
+
+	if err := promoRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get tax totals per rate for date range
+	taxRows, err := q.Query(`
+		SELECT td.tax_rate, SUM(td.tax_base), SUM(td.tax_amount)
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		WHERE DATE(t.created_at) >= $1 AND DATE(t.created_at) <= $2
+			AND t.status <> 'voided'
+		GROUP BY td.tax_rate
+		ORDER BY td.tax_rate
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer taxRows.Close()
+
+	report.Pajak = make([]models.TaxRateTotal, 0)
+	for taxRows.Next() {
+		var tr models.TaxRateTotal
+		if err := taxRows.Scan(&tr.Rate, &tr.TaxBase, &tr.TaxAmount); err != nil {
+			return nil, err
+		}
+		report.Pajak = append(report.Pajak, tr)
+	}
+
+	if err := taxRows.Err(); err != nil {
+		return nil, err
+	}
+
 	return report, nil
 }
 
-func (repo *ReportRepository) GetReportByDateRange(startDate, endDate time.Time) (*models.DateRangeReport, error) {
-	report := &models.DateRangeReport{}
+// GetCashierReport - penjualan per kasir per terminal. startDate / endDate nil berarti hari ini
+// menurut tanggal database.
+func (repo *PostgresReportRepository) GetCashierReport(startDate, endDate *time.Time) ([]models.CashierSalesReport, error) {
+	var today time.Time
+	if startDate == nil || endDate == nil {
+		if err := repo.db.QueryRow("SELECT CURRENT_DATE").Scan(&today); err != nil {
+			return nil, err
+		}
+		startDate, endDate = &today, &today
+	}
 
-	// Get total revenue for date range
-	err := repo.db.QueryRow(`
-		SELECT COALESCE(SUM(total_amount), 0) 
-		FROM transactions 
-		WHERE DATE(created_at) >= $1 AND DATE(created_at) <= $2
-	`, startDate, endDate).Scan(&report.TotalRevenue)
-	if err != nil && err != sql.ErrNoRows {
+	// kasir / terminal NULL (transaksi lama) dikelompokkan dengan key 0
+	rows, err := repo.db.Query(`
+		WITH sales AS (
+			SELECT
+				COALESCE(t.cashier_id, 0) AS cashier_key,
+				COALESCE(t.terminal_id, 0) AS terminal_key,
+				COUNT(*) FILTER (WHERE t.status <> 'voided') AS total_transaksi,
+				COALESCE(SUM(t.subtotal_amount) FILTER (WHERE t.status <> 'voided'), 0) AS gross_sales,
+				COALESCE(SUM(t.discount_amount) FILTER (WHERE t.status <> 'voided'), 0) AS total_diskon,
+				COALESCE(SUM(t.total_amount) FILTER (WHERE t.status <> 'voided'), 0) AS net_sales,
+				COUNT(*) FILTER (WHERE t.status = 'voided') AS total_void,
+				COALESCE(SUM(t.total_amount) FILTER (WHERE t.status = 'voided'), 0) AS void_amount,
+				COALESCE(SUM(cash.amount) FILTER (WHERE t.status <> 'voided'), 0) AS cash_amount
+			FROM transactions t
+			LEFT JOIN (
+				SELECT transaction_id, SUM(amount) AS amount FROM payments WHERE method = $3 GROUP BY transaction_id
+			) cash ON cash.transaction_id = t.id
+			WHERE DATE(t.created_at) >= $1 AND DATE(t.created_at) <= $2
+			GROUP BY 1, 2
+		),
+		refund_totals AS (
+			SELECT
+				COALESCE(t.cashier_id, 0) AS cashier_key,
+				COALESCE(t.terminal_id, 0) AS terminal_key,
+				SUM(r.total_amount) AS total_refund
+			FROM refunds r
+			JOIN transactions t ON r.transaction_id = t.id
+			WHERE DATE(r.created_at) >= $1 AND DATE(r.created_at) <= $2
+			GROUP BY 1, 2
+		),
+		keys AS (
+			SELECT cashier_key, terminal_key FROM sales
+			UNION
+			SELECT cashier_key, terminal_key FROM refund_totals
+		)
+		SELECT
+			NULLIF(k.cashier_key, 0), COALESCE(u.name, ''), NULLIF(k.terminal_key, 0), COALESCE(tm.name, ''),
+			COALESCE(s.total_transaksi, 0), COALESCE(s.gross_sales, 0), COALESCE(s.total_diskon, 0),
+			COALESCE(s.net_sales, 0), COALESCE(rt.total_refund, 0), COALESCE(s.total_void, 0),
+			COALESCE(s.void_amount, 0), COALESCE(s.cash_amount, 0)
+		FROM keys k
+		LEFT JOIN sales s ON s.cashier_key = k.cashier_key AND s.terminal_key = k.terminal_key
+		LEFT JOIN refund_totals rt ON rt.cashier_key = k.cashier_key AND rt.terminal_key = k.terminal_key
+		LEFT JOIN users u ON u.id = k.cashier_key
+		LEFT JOIN terminals tm ON tm.id = k.terminal_key
+		ORDER BY k.cashier_key, k.terminal_key
+	`, *startDate, *endDate, models.PaymentMethodCash)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	// Get total transactions for date range
-	err = repo.db.QueryRow(`
-		SELECT COALESCE(COUNT(*), 0) 
-		FROM transactions 
-		WHERE DATE(created_at) >= $1 AND DATE(created_at) <= $2
-	`, startDate, endDate).Scan(&report.TotalTransaksi)
-	if err != nil && err != sql.ErrNoRows {
+	report := make([]models.CashierSalesReport, 0)
+	for rows.Next() {
+		var r models.CashierSalesReport
+		var cashierID, terminalID sql.NullInt64
+		err := rows.Scan(
+			&cashierID, &r.CashierName, &terminalID, &r.TerminalName,
+			&r.TotalTransaksi, &r.GrossSales, &r.TotalDiskon,
+			&r.NetSales, &r.TotalRefund, &r.TotalVoid,
+			&r.VoidAmount, &r.CashAmount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		r.CashierID = nullIntPtr(cashierID)
+		r.TerminalID = nullIntPtr(terminalID)
+		report = append(report, r)
+	}
+
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	// Get best-selling product for date range
-	err = repo.db.QueryRow(`
-		SELECT p.name, SUM(td.quantity)
+	return report, nil
+}
+
+// GetProductSalesReport - penjualan per produk, varian digabung ke induknya. startDate / endDate
+// nil berarti hari ini menurut tanggal database.
+func (repo *PostgresReportRepository) GetProductSalesReport(startDate, endDate *time.Time) ([]models.ProductSalesTotal, error) {
+	var today time.Time
+	if startDate == nil || endDate == nil {
+		if err := repo.db.QueryRow("SELECT CURRENT_DATE").Scan(&today); err != nil {
+			return nil, err
+		}
+		startDate, endDate = &today, &today
+	}
+
+	// nama produk dan induk dari snapshot detail transaksi terbaru, bukan data produk saat ini
+	rows, err := repo.db.Query(`
+		SELECT
+			COALESCE(td.parent_id, td.product_id), (ARRAY_AGG(`+parentNameSnapshot+` ORDER BY td.id DESC))[1],
+			td.product_id, (ARRAY_AGG(td.product_name ORDER BY td.id DESC))[1], MAX(td.id),
+			SUM(td.quantity), SUM(td.subtotal), SUM(td.discount_amount), SUM(td.total_amount)
 		FROM transaction_details td
-		JOIN transactions t ON td.transaction_id = t.id
-		JOIN products p ON td.product_id = p.id
+		JOIN transactions t ON t.id = td.transaction_id
 		WHERE DATE(t.created_at) >= $1 AND DATE(t.created_at) <= $2
-		GROUP BY p.id, p.name
-		ORDER BY SUM(td.quantity) DESC
-		LIMIT 1
-	`, startDate, endDate).Scan(&report.ProdukTerlaris.Nama, &report.ProdukTerlaris.QtyTerjual)
-	if err != nil && err != sql.ErrNoRows {
+			AND t.status <> 'voided'
+		GROUP BY COALESCE(td.parent_id, td.product_id), td.product_id
+	`, *startDate, *endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []productSales
+	for rows.Next() {
+		var s productSales
+		err := rows.Scan(
+			&s.parent.ProductID, &s.parent.ProductName, &s.total.ProductID, &s.total.ProductName, &s.lastDetailID,
+			&s.total.Quantity, &s.total.GrossSales, &s.total.DiscountAmount, &s.total.NetSales,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sales = append(sales, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return groupProductSales(sales), nil
+}
+
+// GetComponentConsumption - pemakaian komponen resep dari snapshot transaction_detail_components.
+// startDate / endDate nil berarti hari ini menurut tanggal database.
+func (repo *PostgresReportRepository) GetComponentConsumption(startDate, endDate *time.Time) ([]models.ComponentConsumption, error) {
+	var today time.Time
+	if startDate == nil || endDate == nil {
+		if err := repo.db.QueryRow("SELECT CURRENT_DATE").Scan(&today); err != nil {
+			return nil, err
+		}
+		startDate, endDate = &today, &today
+	}
+
+	rows, err := repo.db.Query(`
+		WITH used AS (
+			SELECT tdc.component_id, MAX(tdc.component_name) AS name, SUM(td.quantity * tdc.quantity) AS quantity
+			FROM transaction_detail_components tdc
+			JOIN transaction_details td ON td.id = tdc.transaction_detail_id
+			JOIN transactions t ON t.id = td.transaction_id
+			WHERE DATE(t.created_at) >= $1 AND DATE(t.created_at) <= $2
+				AND t.status <> 'voided'
+			GROUP BY tdc.component_id
+		),
+		returned AS (
+			SELECT tdc.component_id, MAX(tdc.component_name) AS name, SUM(rd.quantity * tdc.quantity) AS quantity
+			FROM refund_details rd
+			JOIN refunds r ON r.id = rd.refund_id
+			JOIN transaction_detail_components tdc ON tdc.transaction_detail_id = rd.transaction_detail_id
+			WHERE DATE(r.created_at) >= $1 AND DATE(r.created_at) <= $2
+			GROUP BY tdc.component_id
+		)
+		SELECT
+			COALESCE(u.component_id, rt.component_id), COALESCE(p.name, u.name, rt.name),
+			COALESCE(u.quantity, 0), COALESCE(rt.quantity, 0), COALESCE(p.stock, 0)
+		FROM used u
+		FULL JOIN returned rt ON rt.component_id = u.component_id
+		LEFT JOIN products p ON p.id = COALESCE(u.component_id, rt.component_id)
+		ORDER BY 1
+	`, *startDate, *endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := make([]models.ComponentConsumption, 0)
+	for rows.Next() {
+		var c models.ComponentConsumption
+		if err := rows.Scan(&c.ComponentID, &c.ComponentName, &c.Quantity, &c.Returned, &c.Stock); err != nil {
+			return nil, err
+		}
+		c.NetQuantity = c.Quantity - c.Returned
+		report = append(report, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// parentNameSnapshot - nama induk di detail transaksi, nama produk itu sendiri jika bukan varian
+const parentNameSnapshot = "CASE WHEN td.parent_id IS NULL THEN td.product_name ELSE td.parent_name END"
+
+// productSales - total satu produk beserta produk induknya (induk = produk itu sendiri jika bukan
+// varian). Nama dari snapshot detail lastDetailID.
+type productSales struct {
+	parent       models.ProductSalesTotal
+	total        models.ProductSalesTotal
+	lastDetailID int
+}
+
+// groupProductSales - jumlahkan varian ke induknya, urut dari quantity terbanyak (seri: product_id).
+// Nama induk diambil dari varian yang terakhir terjual.
+func groupProductSales(sales []productSales) []models.ProductSalesTotal {
+	parents := make(map[int]*models.ProductSalesTotal)
+	lastDetailID := make(map[int]int)
+	for _, s := range sales {
+		parent, ok := parents[s.parent.ProductID]
+		if !ok {
+			parent = &models.ProductSalesTotal{ProductID: s.parent.ProductID}
+			parents[s.parent.ProductID] = parent
+		}
+		if s.lastDetailID > lastDetailID[s.parent.ProductID] {
+			lastDetailID[s.parent.ProductID] = s.lastDetailID
+			parent.ProductName = s.parent.ProductName
+		}
+		parent.Quantity += s.total.Quantity
+		parent.GrossSales += s.total.GrossSales
+		parent.DiscountAmount += s.total.DiscountAmount
+		parent.NetSales += s.total.NetSales
+		if s.total.ProductID != s.parent.ProductID {
+			parent.Variants = append(parent.Variants, s.total)
+		}
+	}
+
+	byQuantity := func(a, b models.ProductSalesTotal) int {
+		if a.Quantity != b.Quantity {
+			return b.Quantity - a.Quantity
+		}
+		return a.ProductID - b.ProductID
+	}
+
+	report := make([]models.ProductSalesTotal, 0, len(parents))
+	for _, p := range parents {
+		slices.SortFunc(p.Variants, byQuantity)
+		report = append(report, *p)
+	}
+	slices.SortFunc(report, byQuantity)
+	return report
+}
+
+// CreateEndOfDayReport - hitung dan simpan X / Z report untuk satu tanggal bisnis (nil = hari ini).
+// Kunci eksklusif tanggal dipegang selama menghitung supaya tidak ada checkout, void atau refund
+// yang masuk di tengah laporan. Z report hanya sekali per tanggal dan butuh semua shift sudah ditutup.
+func (repo *PostgresReportRepository) CreateEndOfDayReport(reportType string, businessDate *time.Time, createdBy int) (*models.EndOfDayReport, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	today, err := currentDate(tx)
+	if err != nil {
+		return nil, err
+	}
+	day := today
+	if businessDate != nil {
+		day = dateOf(*businessDate)
+	}
+	if day.After(today) {
+		return nil, errFutureBusinessDate
+	}
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1, $2)", dayLockClass, dayLockKey(day)); err != nil {
+		return nil, err
+	}
+
+	if reportType == models.EndOfDayReportZ {
+		var closed, openShifts bool
+		err := tx.QueryRow(`
+			SELECT
+				EXISTS (SELECT 1 FROM end_of_day_reports WHERE type = $1 AND business_date = $2),
+				EXISTS (SELECT 1 FROM shifts WHERE status = $3 AND DATE(opened_at) <= $2)
+		`, models.EndOfDayReportZ, day, models.ShiftStatusOpen).Scan(&closed, &openShifts)
+		if err != nil {
+			return nil, err
+		}
+		if closed {
+			return nil, dayClosedError(day)
+		}
+		if openShifts {
+			return nil, errOpenShiftsBeforeZ
+		}
+	}
+
+	report := &models.EndOfDayReport{Type: reportType, BusinessDate: day, CreatedBy: createdBy}
+	if err := fillEndOfDayReport(tx, report); err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(
+		"SELECT COALESCE(MAX(sequence), 0) + 1 FROM end_of_day_reports WHERE business_date = $1 AND type = $2",
+		day, reportType,
+	).Scan(&report.Sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(
+		`INSERT INTO end_of_day_reports (type, business_date, sequence, data, created_by)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		report.Type, report.BusinessDate, report.Sequence, data, report.CreatedBy,
+	).Scan(&report.ID, &report.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
 	return report, nil
 }
+
+// GetEndOfDayReports - X / Z report yang sudah disimpan, terbaru dulu. reportType kosong = semua tipe,
+// businessDate nil = semua tanggal.
+func (repo *PostgresReportRepository) GetEndOfDayReports(reportType string, businessDate *time.Time) ([]models.EndOfDayReport, error) {
+	rows, err := repo.db.Query(`
+		SELECT id, type, business_date, sequence, data, created_by, created_at
+		FROM end_of_day_reports
+		WHERE ($1 = '' OR type = $1) AND ($2::date IS NULL OR business_date = $2)
+		ORDER BY business_date DESC, type, sequence DESC
+	`, reportType, businessDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make([]models.EndOfDayReport, 0)
+	for rows.Next() {
+		var r models.EndOfDayReport
+		var id, sequence, createdBy int
+		var reportType string
+		var businessDate, createdAt time.Time
+		var data []byte
+		if err := rows.Scan(&id, &reportType, &businessDate, &sequence, &data, &createdBy, &createdAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, err
+		}
+		r.ID, r.Type, r.BusinessDate, r.Sequence, r.CreatedBy, r.CreatedAt = id, reportType, businessDate, sequence, createdBy, createdAt
+		reports = append(reports, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// fillEndOfDayReport - isi angka laporan untuk report.BusinessDate
+func fillEndOfDayReport(q queryer, report *models.EndOfDayReport) error {
+	day := report.BusinessDate
+
+	sales, err := reportByDateRange(q, day, day)
+	if err != nil {
+		return err
+	}
+	report.DateRangeReport = *sales
+	report.NetSales = sales.NetRevenue + sales.TotalRefund
+
+	var firstID, lastID sql.NullInt64
+	err = q.QueryRow(`
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'voided'),
+			COALESCE(SUM(total_amount) FILTER (WHERE status = 'voided'), 0),
+			MIN(id),
+			MAX(id),
+			COALESCE((ARRAY_AGG(receipt_number ORDER BY id))[1], ''),
+			COALESCE((ARRAY_AGG(receipt_number ORDER BY id DESC))[1], '')
+		FROM transactions
+		WHERE DATE(created_at) = $1
+	`, day).Scan(&report.TotalVoid, &report.VoidAmount, &firstID, &lastID, &report.FirstReceiptNumber, &report.LastReceiptNumber)
+	if err != nil {
+		return err
+	}
+	report.FirstTransactionID = nullIntPtr(firstID)
+	report.LastTransactionID = nullIntPtr(lastID)
+
+	rows, err := q.Query(`
+		SELECT td.category_id, (ARRAY_AGG(td.category_name ORDER BY td.id DESC))[1],
+			SUM(td.quantity), SUM(td.subtotal), SUM(td.discount_amount), SUM(td.total_amount)
+		FROM transaction_details td
+		JOIN transactions t ON t.id = td.transaction_id
+		WHERE DATE(t.created_at) = $1 AND t.status <> 'voided'
+		GROUP BY td.category_id
+		ORDER BY COALESCE(td.category_id, 0)
+	`, day)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	report.Kategori = make([]models.CategorySalesTotal, 0)
+	for rows.Next() {
+		var ct models.CategorySalesTotal
+		var categoryID sql.NullInt64
+		if err := rows.Scan(&categoryID, &ct.CategoryName, &ct.Quantity, &ct.GrossSales, &ct.DiscountAmount, &ct.NetSales); err != nil {
+			return err
+		}
+		ct.CategoryID = nullIntPtr(categoryID)
+		report.Kategori = append(report.Kategori, ct)
+	}
+
+	return rows.Err()
+}
+
+var (
+	errOpenShiftsBeforeZ  = &models.ConflictError{Message: "masih ada shift yang terbuka, tutup semua shift sebelum Z report"}
+	errFutureBusinessDate = models.NewValidationError("business_date", "tanggal tidak boleh setelah hari ini")
+)
+
+func dayClosedError(day time.Time) error {
+	return &models.ConflictError{Message: fmt.Sprintf("tanggal %s sudah ditutup dengan Z report", day.Format("2006-01-02"))}
+}
+
+// dayLockClass - key pertama pg_advisory_xact_lock(int, int) untuk kunci tanggal bisnis,
+// key kedua tanggal dalam bentuk YYYYMMDD
+const dayLockClass = 727350102
+
+func dayLockKey(day time.Time) int {
+	y, m, d := day.Date()
+	return y*10000 + int(m)*100 + d
+}
+
+// lockOpenDay - kunci bersama tanggal bisnis lalu pastikan belum ada Z report. Checkout, void dan
+// refund memegang kunci ini sampai commit, Z report memegang kunci eksklusif selama menghitung.
+func lockOpenDay(tx *sql.Tx, day time.Time) error {
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock_shared($1, $2)", dayLockClass, dayLockKey(day)); err != nil {
+		return err
+	}
+
+	var closed bool
+	err := tx.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM end_of_day_reports WHERE type = $1 AND business_date = $2)",
+		models.EndOfDayReportZ, day,
+	).Scan(&closed)
+	if err != nil {
+		return err
+	}
+	if closed {
+		return dayClosedError(day)
+	}
+	return nil
+}
+
+// currentDate - tanggal menurut database, sama dengan CURRENT_DATE di query lain
+func currentDate(q queryer) (time.Time, error) {
+	var today time.Time
+	err := q.QueryRow("SELECT CURRENT_DATE").Scan(&today)
+	return today, err
+}
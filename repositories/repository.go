@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"time"
+)
+
+// Interface repository yang dipakai service. Implementasi Postgres ada di *_repository.go,
+// implementasi in-memory (untuk test dan development tanpa database) ada di memory_*.go.
+
+type ProductRepository interface {
+	GetAll(name string) ([]models.Product, error)
+	Create(product *models.Product) error
+	GetByID(id int) (*models.Product, error)
+	GetByBarcode(barcode string) (*models.Product, error)
+	GetBySKU(sku string) (*models.Product, error)
+	IDsByBarcode(barcodes []string) (map[string]int, error)
+	IDsBySKU(skus []string) (map[string]int, error)
+	Update(product *models.Product) error
+	AddBarcodes(barcodes map[int]string) error
+	Delete(id int) error
+}
+
+type ProductCategoryRepository interface {
+	GetAll() ([]models.ProductCategory, error)
+	Create(productCategory *models.ProductCategory) error
+	GetByID(id int) (*models.ProductCategory, error)
+	Update(productCategory *models.ProductCategory) error
+	Delete(id int) error
+}
+
+type TransactionRepository interface {
+	CreateTransaction(req models.CheckoutRequest, useLock bool) (*models.Transaction, bool, error)
+	GetAll(filter models.TransactionFilter) (*models.TransactionList, error)
+	GetByID(id int) (*models.Transaction, error)
+	Void(id int, req models.VoidRequest) (*models.Transaction, error)
+	Refund(id int, req models.RefundRequest) (*models.Refund, error)
+}
+
+type PromotionRepository interface {
+	GetAll() ([]models.Promotion, error)
+	Create(promotion *models.Promotion) error
+	GetByID(id int) (*models.Promotion, error)
+	Update(promotion *models.Promotion) error
+	Delete(id int) error
+}
+
+type ReportRepository interface {
+	GetDailyReport() (*models.DailyReport, error)
+	GetReportByDateRange(startDate, endDate time.Time) (*models.DateRangeReport, error)
+	GetCashierReport(startDate, endDate *time.Time) ([]models.CashierSalesReport, error)
+	GetProductSalesReport(startDate, endDate *time.Time) ([]models.ProductSalesTotal, error)
+	GetComponentConsumption(startDate, endDate *time.Time) ([]models.ComponentConsumption, error)
+	CreateEndOfDayReport(reportType string, businessDate *time.Time, createdBy int) (*models.EndOfDayReport, error)
+	GetEndOfDayReports(reportType string, businessDate *time.Time) ([]models.EndOfDayReport, error)
+}
+
+type UserRepository interface {
+	GetAll() ([]models.User, error)
+	GetByID(id int) (*models.User, error)
+	GetByUsername(username string) (*models.User, error)
+	Count() (int, error)
+	Create(user *models.User) error
+	Update(user *models.User) error
+}
+
+type SessionRepository interface {
+	Create(session *models.Session) error
+	GetByTokenHash(tokenHash string) (*models.Session, error)
+	GetByRefreshTokenHash(refreshTokenHash string) (*models.Session, error)
+	Rotate(session *models.Session, oldRefreshTokenHash string) error
+	Revoke(id int, at time.Time) error
+	RevokeAllForUser(userID int, at time.Time) error
+}
+
+type RoleRepository interface {
+	GetAll() ([]models.Role, error)
+	GetByName(name string) (*models.Role, error)
+	Create(role *models.Role) error
+	Update(role *models.Role) error
+	Delete(name string) error
+}
+
+type TerminalRepository interface {
+	GetAll() ([]models.Terminal, error)
+	GetByID(id int) (*models.Terminal, error)
+	Create(terminal *models.Terminal) error
+	Update(terminal *models.Terminal) error
+}
+
+type ShiftRepository interface {
+	GetAll(filter models.ShiftFilter) ([]models.Shift, error)
+	GetByID(id int) (*models.Shift, error)
+	GetOpenByTerminal(terminalID int) (*models.Shift, error)
+	Open(req models.OpenShiftRequest) (*models.Shift, error)
+	AddCashMovement(shiftID int, req models.CashMovementRequest) (*models.CashMovement, error)
+	Close(id int, req models.CloseShiftRequest) (*models.Shift, error)
+}
+
+type StockMovementRepository interface {
+	Create(req models.StockMovementRequest) (*models.StockMovement, error)
+	GetByProduct(productID int, filter models.StockMovementFilter) (*models.StockMovementList, error)
+	Check() (*models.StockCheck, error)
+}
+
+var (
+	_ ProductRepository         = (*PostgresProductRepository)(nil)
+	_ ProductRepository         = (*MemoryProductRepository)(nil)
+	_ ProductCategoryRepository = (*PostgresProductCategoryRepository)(nil)
+	_ ProductCategoryRepository = (*MemoryProductCategoryRepository)(nil)
+	_ TransactionRepository     = (*PostgresTransactionRepository)(nil)
+	_ TransactionRepository     = (*MemoryTransactionRepository)(nil)
+	_ PromotionRepository       = (*PostgresPromotionRepository)(nil)
+	_ PromotionRepository       = (*MemoryPromotionRepository)(nil)
+	_ ReportRepository          = (*PostgresReportRepository)(nil)
+	_ ReportRepository          = (*MemoryReportRepository)(nil)
+	_ UserRepository            = (*PostgresUserRepository)(nil)
+	_ UserRepository            = (*MemoryUserRepository)(nil)
+	_ SessionRepository         = (*PostgresSessionRepository)(nil)
+	_ SessionRepository         = (*MemorySessionRepository)(nil)
+	_ RoleRepository            = (*PostgresRoleRepository)(nil)
+	_ RoleRepository            = (*MemoryRoleRepository)(nil)
+	_ TerminalRepository        = (*PostgresTerminalRepository)(nil)
+	_ TerminalRepository        = (*MemoryTerminalRepository)(nil)
+	_ ShiftRepository           = (*PostgresShiftRepository)(nil)
+	_ ShiftRepository           = (*MemoryShiftRepository)(nil)
+	_ StockMovementRepository   = (*PostgresStockMovementRepository)(nil)
+	_ StockMovementRepository   = (*MemoryStockMovementRepository)(nil)
+)
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+var (
+	errRoleNotFound = &models.NotFoundError{Message: "role tidak ditemukan"}
+	errRoleExists   = &models.ConflictError{Message: "role sudah ada"}
+	errRoleInUse    = &models.ConflictError{Message: "role masih dipakai user, tidak bisa dihapus"}
+	errInvalidRole  = models.NewValidationError("role", "role tidak ditemukan")
+)
+
+type PostgresRoleRepository struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) *PostgresRoleRepository {
+	return &PostgresRoleRepository{db: db}
+}
+
+const roleColumns = "name, description, permissions, built_in, created_at"
+
+func scanRole(row rowScanner) (*models.Role, error) {
+	var r models.Role
+	var permissions pq.StringArray
+	err := row.Scan(&r.Name, &r.Description, &permissions, &r.BuiltIn, &r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	r.Permissions = []string(permissions)
+	return &r, nil
+}
+
+func (repo *PostgresRoleRepository) GetAll() ([]models.Role, error) {
+	rows, err := repo.db.Query("SELECT " + roleColumns + " FROM roles ORDER BY built_in DESC, name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make([]models.Role, 0)
+	for rows.Next() {
+		r, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (repo *PostgresRoleRepository) GetByName(name string) (*models.Role, error) {
+	r, err := scanRole(repo.db.QueryRow("SELECT "+roleColumns+" FROM roles WHERE name = $1", name))
+	if err == sql.ErrNoRows {
+		return nil, errRoleNotFound
+	}
+	return r, err
+}
+
+func (repo *PostgresRoleRepository) Create(role *models.Role) error {
+	err := repo.db.QueryRow(
+		"INSERT INTO roles (name, description, permissions) VALUES ($1, $2, $3) RETURNING built_in, created_at",
+		role.Name, role.Description, pq.StringArray(role.Permissions),
+	).Scan(&role.BuiltIn, &role.CreatedAt)
+	if isUniqueViolation(err) {
+		return errRoleExists
+	}
+	return err
+}
+
+// Update - hanya deskripsi dan izin, nama role tidak bisa diganti
+func (repo *PostgresRoleRepository) Update(role *models.Role) error {
+	err := repo.db.QueryRow(
+		"UPDATE roles SET description = $1, permissions = $2 WHERE name = $3 RETURNING built_in, created_at",
+		role.Description, pq.StringArray(role.Permissions), role.Name,
+	).Scan(&role.BuiltIn, &role.CreatedAt)
+	if err == sql.ErrNoRows {
+		return errRoleNotFound
+	}
+	return err
+}
+
+func (repo *PostgresRoleRepository) Delete(name string) error {
+	result, err := repo.db.Exec("DELETE FROM roles WHERE name = $1", name)
+	if isForeignKeyViolation(err) {
+		return errRoleInUse
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errRoleNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"database/sql"
+	"time"
+)
+
+var errSessionNotFound = &models.UnauthorizedError{Message: "sesi tidak valid"}
+
+type PostgresSessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *PostgresSessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+const sessionColumns = "id, user_id, token_hash, refresh_token_hash, expires_at, refresh_expires_at, created_at, revoked_at"
+
+func scanSession(row rowScanner) (*models.Session, error) {
+	var s models.Session
+	var revokedAt sql.NullTime
+	err := row.Scan(&s.ID, &s.UserID, &s.TokenHash, &s.RefreshTokenHash, &s.ExpiresAt, &s.RefreshExpiresAt, &s.CreatedAt, &revokedAt)
+	if err == sql.ErrNoRows {
+		return nil, errSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		s.RevokedAt = &revokedAt.Time
+	}
+	return &s, nil
+}
+
+func (repo *PostgresSessionRepository) Create(session *models.Session) error {
+	return repo.db.QueryRow(
+		`INSERT INTO user_sessions (user_id, token_hash, refresh_token_hash, expires_at, refresh_expires_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		session.UserID, session.TokenHash, session.RefreshTokenHash, session.ExpiresAt, session.RefreshExpiresAt,
+	).Scan(&session.ID, &session.CreatedAt)
+}
+
+// GetByTokenHash - sesi aktif (belum dicabut) untuk access token. Masa berlaku dicek di service.
+func (repo *PostgresSessionRepository) GetByTokenHash(tokenHash string) (*models.Session, error) {
+	return scanSession(repo.db.QueryRow(
+		"SELECT "+sessionColumns+" FROM user_sessions WHERE token_hash = $1 AND revoked_at IS NULL", tokenHash,
+	))
+}
+
+func (repo *PostgresSessionRepository) GetByRefreshTokenHash(refreshTokenHash string) (*models.Session, error) {
+	return scanSession(repo.db.QueryRow(
+		"SELECT "+sessionColumns+" FROM user_sessions WHERE refresh_token_hash = $1 AND revoked_at IS NULL", refreshTokenHash,
+	))
+}
+
+// Rotate - ganti pasangan token sesi. Hanya berhasil jika refresh token lama belum dipakai,
+// sehingga refresh token yang sama tidak bisa dipakai dua kali.
+func (repo *PostgresSessionRepository) Rotate(session *models.Session, oldRefreshTokenHash string) error {
+	result, err := repo.db.Exec(
+		`UPDATE user_sessions SET token_hash = $1, refresh_token_hash = $2, expires_at = $3, refresh_expires_at = $4
+		WHERE id = $5 AND refresh_token_hash = $6 AND revoked_at IS NULL`,
+		session.TokenHash, session.RefreshTokenHash, session.ExpiresAt, session.RefreshExpiresAt, session.ID, oldRefreshTokenHash,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errSessionNotFound
+	}
+
+	return nil
+}
+
+func (repo *PostgresSessionRepository) Revoke(id int, at time.Time) error {
+	_, err := repo.db.Exec("UPDATE user_sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL", at, id)
+	return err
+}
+
+// RevokeAllForUser - logout paksa semua perangkat, dipakai saat user dinonaktifkan atau ganti password
+func (repo *PostgresSessionRepository) RevokeAllForUser(userID int, at time.Time) error {
+	_, err := repo.db.Exec("UPDATE user_sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL", at, userID)
+	return err
+}
@@ -0,0 +1,285 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+var (
+	errShiftNotFound    = &models.NotFoundError{Message: "shift tidak ditemukan"}
+	errShiftAlreadyOpen = &models.ConflictError{Message: "terminal ini masih punya shift yang terbuka"}
+	errShiftClosed      = &models.ConflictError{Message: "shift sudah ditutup"}
+	errNoOpenShift      = &models.ConflictError{Message: "belum ada shift yang dibuka di terminal ini"}
+	errRefundNoShift    = &models.ConflictError{Message: "refund tunai butuh shift yang terbuka di terminal"}
+	errVoidShiftClosed  = &models.ConflictError{Message: "shift transaksi sudah ditutup, gunakan refund"}
+)
+
+type PostgresShiftRepository struct {
+	db *sql.DB
+}
+
+func NewShiftRepository(db *sql.DB) *PostgresShiftRepository {
+	return &PostgresShiftRepository{db: db}
+}
+
+// shiftColumns - kolom shift beserta total laci yang dihitung dari transaksi, refund dan cash movement
+const shiftColumns = `
+	s.id, s.terminal_id, s.cashier_id, s.status, s.opening_float, s.expected_cash, s.counted_cash,
+	s.closing_note, s.closed_by, s.opened_at, s.closed_at,
+	(SELECT COUNT(*) FROM transactions t WHERE t.shift_id = s.id AND t.status <> 'voided'),
+	COALESCE((SELECT SUM(p.amount) FROM payments p JOIN transactions t ON t.id = p.transaction_id
+		WHERE t.shift_id = s.id AND t.status <> 'voided' AND p.method = 'cash'), 0),
+	COALESCE((SELECT SUM(r.cash_amount) FROM refunds r WHERE r.shift_id = s.id), 0),
+	COALESCE((SELECT SUM(m.amount) FROM cash_movements m WHERE m.shift_id = s.id AND m.type = 'paid_in'), 0),
+	COALESCE((SELECT SUM(m.amount) FROM cash_movements m WHERE m.shift_id = s.id AND m.type = 'paid_out'), 0)`
+
+func scanShift(row rowScanner) (*models.Shift, error) {
+	var s models.Shift
+	var expectedCash, countedCash, closedBy sql.NullInt64
+	var closedAt sql.NullTime
+	err := row.Scan(
+		&s.ID, &s.TerminalID, &s.CashierID, &s.Status, &s.OpeningFloat, &expectedCash, &countedCash,
+		&s.ClosingNote, &closedBy, &s.OpenedAt, &closedAt,
+		&s.TotalTransaksi, &s.CashSales, &s.CashRefunds, &s.PaidIn, &s.PaidOut,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.CountedCash = nullIntPtr(countedCash)
+	s.ClosedBy = nullIntPtr(closedBy)
+	if closedAt.Valid {
+		s.ClosedAt = &closedAt.Time
+	}
+	finishShiftTotals(&s, nullIntPtr(expectedCash))
+	return &s, nil
+}
+
+func (repo *PostgresShiftRepository) GetAll(filter models.ShiftFilter) ([]models.Shift, error) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.TerminalID != nil {
+		addCondition("s.terminal_id = $%d", *filter.TerminalID)
+	}
+	if filter.CashierID != nil {
+		addCondition("s.cashier_id = $%d", *filter.CashierID)
+	}
+	if filter.Status != "" {
+		addCondition("s.status = $%d", filter.Status)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := repo.db.Query("SELECT "+shiftColumns+" FROM shifts s"+where+" ORDER BY s.opened_at DESC, s.id DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := make([]models.Shift, 0)
+	for rows.Next() {
+		s, err := scanShift(rows)
+		if err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, *s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return shifts, nil
+}
+
+// GetByID - shift beserta daftar cash movement
+func (repo *PostgresShiftRepository) GetByID(id int) (*models.Shift, error) {
+	s, err := scanShift(repo.db.QueryRow("SELECT "+shiftColumns+" FROM shifts s WHERE s.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, errShiftNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.CashMovements, err = repo.getCashMovements(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// GetOpenByTerminal - shift yang sedang terbuka di terminal
+func (repo *PostgresShiftRepository) GetOpenByTerminal(terminalID int) (*models.Shift, error) {
+	var id int
+	err := repo.db.QueryRow("SELECT id FROM shifts WHERE terminal_id = $1 AND status = 'open'", terminalID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, errNoOpenShift
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+func (repo *PostgresShiftRepository) Open(req models.OpenShiftRequest) (*models.Shift, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkTerminal(tx, req.TerminalID); err != nil {
+		return nil, err
+	}
+
+	var id int
+	err = tx.QueryRow(
+		"INSERT INTO shifts (terminal_id, cashier_id, opening_float) VALUES ($1, $2, $3) RETURNING id",
+		req.TerminalID, req.CashierID, req.OpeningFloat,
+	).Scan(&id)
+	if isUniqueViolation(err) {
+		return nil, errShiftAlreadyOpen
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+func (repo *PostgresShiftRepository) AddCashMovement(shiftID int, req models.CashMovementRequest) (*models.CashMovement, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// FOR SHARE: tidak bisa ditambah saat shift sedang ditutup
+	var status string
+	err = tx.QueryRow("SELECT status FROM shifts WHERE id = $1 FOR SHARE", shiftID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, errShiftNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != models.ShiftStatusOpen {
+		return nil, errShiftClosed
+	}
+
+	m := &models.CashMovement{ShiftID: shiftID, Type: req.Type, Amount: req.Amount, Reason: req.Reason, CreatedBy: req.CreatedBy}
+	err = tx.QueryRow(
+		"INSERT INTO cash_movements (shift_id, type, amount, reason, created_by) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		m.ShiftID, m.Type, m.Amount, m.Reason, m.CreatedBy,
+	).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Close - tutup shift dengan hasil hitung laci. expected_cash disimpan supaya hasil penutupan
+// tidak berubah walaupun data lain berubah setelahnya.
+func (repo *PostgresShiftRepository) Close(id int, req models.CloseShiftRequest) (*models.Shift, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// lock dulu supaya checkout, refund dan cash movement yang sedang berjalan selesai
+	var status string
+	err = tx.QueryRow("SELECT status FROM shifts WHERE id = $1 FOR UPDATE", id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, errShiftNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != models.ShiftStatusOpen {
+		return nil, errShiftClosed
+	}
+
+	s, err := scanShift(tx.QueryRow("SELECT "+shiftColumns+" FROM shifts s WHERE s.id = $1", id))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE shifts SET status = $1, expected_cash = $2, counted_cash = $3, closing_note = $4, closed_by = $5,
+			closed_at = CURRENT_TIMESTAMP
+		WHERE id = $6`,
+		models.ShiftStatusClosed, s.ExpectedCash, *req.CountedCash, req.Note, req.ClosedBy, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(id)
+}
+
+func (repo *PostgresShiftRepository) getCashMovements(shiftID int) ([]models.CashMovement, error) {
+	rows, err := repo.db.Query(`
+		SELECT id, shift_id, type, amount, reason, created_by, created_at
+		FROM cash_movements
+		WHERE shift_id = $1
+		ORDER BY id
+	`, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := make([]models.CashMovement, 0)
+	for rows.Next() {
+		var m models.CashMovement
+		if err := rows.Scan(&m.ID, &m.ShiftID, &m.Type, &m.Amount, &m.Reason, &m.CreatedBy, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// openShiftID - shift terbuka di terminal, di-lock FOR SHARE supaya tidak bisa ditutup
+// sebelum transaksi pemanggil selesai
+func openShiftID(q queryer, terminalID int) (int, error) {
+	var id int
+	err := q.QueryRow("SELECT id FROM shifts WHERE terminal_id = $1 AND status = 'open' FOR SHARE", terminalID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, errNoOpenShift
+	}
+	return id, err
+}
@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type PostgresStockMovementRepository struct {
+	db *sql.DB
+}
+
+func NewStockMovementRepository(db *sql.DB) *PostgresStockMovementRepository {
+	return &PostgresStockMovementRepository{db: db}
+}
+
+// Create - catat pergerakan manual. Baris produk di-lock supaya balance dan selisih opname
+// dihitung dari stok terbaru.
+func (repo *PostgresStockMovementRepository) Create(req models.StockMovementRequest) (*models.StockMovement, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var name string
+	var stock int
+	err = tx.QueryRow("SELECT name, stock FROM products WHERE id = $1 FOR UPDATE", req.ProductID).Scan(&name, &stock)
+	if err == sql.ErrNoRows {
+		return nil, errProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := buildStockMovement(req, name, stock)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("UPDATE products SET stock = $1 WHERE id = $2", m.Balance, m.ProductID); err != nil {
+		return nil, err
+	}
+	if err := insertStockMovement(tx, m); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// GetByProduct - riwayat pergerakan satu produk, terbaru dulu
+func (repo *PostgresStockMovementRepository) GetByProduct(productID int, filter models.StockMovementFilter) (*models.StockMovementList, error) {
+	var exists bool
+	if err := repo.db.QueryRow("SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", productID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errProductNotFound
+	}
+
+	conditions := []string{"product_id = $1"}
+	args := []any{productID}
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.StartDate != nil {
+		addCondition("DATE(created_at) >= $%d", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		addCondition("DATE(created_at) <= $%d", *filter.EndDate)
+	}
+	if filter.Type != "" {
+		addCondition("type = $%d", filter.Type)
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	list := &models.StockMovementList{
+		Data:  []models.StockMovement{},
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}
+
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM stock_movements"+where, args...).Scan(&list.Total); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, product_id, type, quantity, balance, reference_type, reference_id, reference, note, created_by, created_at
+		FROM stock_movements` + where +
+		fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m models.StockMovement
+		var referenceID, createdBy sql.NullInt64
+		err := rows.Scan(
+			&m.ID, &m.ProductID, &m.Type, &m.Quantity, &m.Balance, &m.ReferenceType, &referenceID,
+			&m.Reference, &m.Note, &createdBy, &m.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		m.ReferenceID = nullIntPtr(referenceID)
+		m.CreatedBy = nullIntPtr(createdBy)
+		list.Data = append(list.Data, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Check - hitung ulang stok semua produk dari ledger
+func (repo *PostgresStockMovementRepository) Check() (*models.StockCheck, error) {
+	rows, err := repo.db.Query(`
+		SELECT
+			p.id, p.name, p.stock,
+			COALESCE((SELECT SUM(m.quantity) FROM stock_movements m WHERE m.product_id = p.id), 0),
+			(SELECT m.balance FROM stock_movements m WHERE m.product_id = p.id ORDER BY m.id DESC LIMIT 1)
+		FROM products p
+		ORDER BY p.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	check := &models.StockCheck{Consistent: true, Discrepancies: []models.StockDiscrepancy{}}
+	for rows.Next() {
+		var d models.StockDiscrepancy
+		var lastBalance sql.NullInt64
+		if err := rows.Scan(&d.ProductID, &d.ProductName, &d.Stock, &d.LedgerStock, &lastBalance); err != nil {
+			return nil, err
+		}
+		d.LastBalance = nullIntPtr(lastBalance)
+		addStockCheck(check, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return check, nil
+}
+
+// insertStockMovement - tambah satu baris ledger di dalam transaksi yang mengubah stok
+func insertStockMovement(tx *sql.Tx, m *models.StockMovement) error {
+	return tx.QueryRow(`
+		INSERT INTO stock_movements (product_id, type, quantity, balance, reference_type, reference_id, reference, note, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`, m.ProductID, m.Type, m.Quantity, m.Balance, m.ReferenceType, m.ReferenceID, m.Reference, m.Note, m.CreatedBy).Scan(&m.ID, &m.CreatedAt)
+}
+
+// buildStockMovement - pergerakan manual dari stok saat ini. Opname menghitung selisih dari
+// stok hasil hitung fisik, stok tidak boleh menjadi negatif.
+func buildStockMovement(req models.StockMovementRequest, name string, stock int) (*models.StockMovement, error) {
+	quantity := req.Quantity
+	if req.Type == models.StockMovementOpname {
+		quantity = *req.Counted - stock
+	}
+	if stock+quantity < 0 {
+		return nil, &models.InsufficientStockError{Items: []models.StockShortage{{
+			ProductID:   req.ProductID,
+			ProductName: name,
+			Requested:   -quantity,
+			Available:   stock,
+		}}}
+	}
+
+	return &models.StockMovement{
+		ProductID: req.ProductID,
+		Type:      req.Type,
+		Quantity:  quantity,
+		Balance:   stock + quantity,
+		Reference: req.Reference,
+		Note:      req.Note,
+		CreatedBy: optionalID(req.CreatedBy),
+	}, nil
+}
+
+// saleStockMovement - stok keluar karena checkout, untuk produk komposit yang tercatat adalah
+// komponennya
+func saleStockMovement(t *models.Transaction, productID, quantity, balance int) models.StockMovement {
+	transactionID := t.ID
+	var cashierID *int
+	if t.CashierID != nil {
+		cashierID = optionalID(*t.CashierID)
+	}
+	return models.StockMovement{
+		ProductID:     productID,
+		Type:          models.StockMovementSale,
+		Quantity:      -quantity,
+		Balance:       balance,
+		ReferenceType: models.StockReferenceTransaction,
+		ReferenceID:   &transactionID,
+		Reference:     t.ReceiptNumber,
+		CreatedBy:     cashierID,
+	}
+}
+
+// voidStockMovement - template pergerakan untuk stok yang kembali karena void
+func voidStockMovement(transactionID int, receiptNumber string, req models.VoidRequest) models.StockMovement {
+	return models.StockMovement{
+		Type:          models.StockMovementVoid,
+		ReferenceType: models.StockReferenceTransaction,
+		ReferenceID:   &transactionID,
+		Reference:     receiptNumber,
+		Note:          req.Reason,
+		CreatedBy:     optionalID(req.VoidedBy),
+	}
+}
+
+// refundStockMovement - template pergerakan untuk stok yang kembali karena refund
+func refundStockMovement(refund *models.Refund, receiptNumber string, req models.RefundRequest) models.StockMovement {
+	refundID := refund.ID
+	return models.StockMovement{
+		Type:          models.StockMovementRefund,
+		ReferenceType: models.StockReferenceRefund,
+		ReferenceID:   &refundID,
+		Reference:     receiptNumber,
+		Note:          refund.Reason,
+		CreatedBy:     optionalID(req.CreatedBy),
+	}
+}
+
+// initialStockMovement - stok awal yang diisi saat produk dibuat, nil jika stok 0
+func initialStockMovement(product *models.Product) *models.StockMovement {
+	if product.Stock == 0 {
+		return nil
+	}
+	return &models.StockMovement{
+		ProductID: product.ID,
+		Type:      models.StockMovementInitial,
+		Quantity:  product.Stock,
+		Balance:   product.Stock,
+		CreatedBy: optionalID(product.ChangedBy),
+	}
+}
+
+// addStockCheck - stok konsisten jika sama dengan jumlah ledger dan balance pergerakan terakhir
+func addStockCheck(check *models.StockCheck, d models.StockDiscrepancy) {
+	check.CheckedProducts++
+	d.Difference = d.Stock - d.LedgerStock
+	lastBalance := 0
+	if d.LastBalance != nil {
+		lastBalance = *d.LastBalance
+	}
+	if d.Difference != 0 || lastBalance != d.Stock {
+		check.Discrepancies = append(check.Discrepancies, d)
+		check.Consistent = false
+	}
+}
+
+// optionalID - id user dari request, 0 (tanpa login) disimpan sebagai NULL
+func optionalID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"testing"
+)
+
+func TestStockCheck(t *testing.T) {
+	store := NewMemoryStore()
+	products := NewMemoryProductRepository(store)
+	tea := &models.Product{Name: "Es Teh", Price: 5000, Stock: 10}
+	bread := &models.Product{Name: "Roti", Price: 12000, Stock: 3}
+	empty := &models.Product{Name: "Kopi", Price: 15000}
+	for _, p := range []*models.Product{tea, bread, empty} {
+		if err := products.Create(p); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	repo := NewMemoryStockMovementRepository(store)
+
+	check, err := repo.Check()
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if !check.Consistent || check.CheckedProducts != 3 || len(check.Discrepancies) != 0 {
+		t.Fatalf("check awal = %+v, want konsisten", check)
+	}
+
+	// stok diubah langsung tanpa ledger, seperti UPDATE products manual di database
+	p := store.products[tea.ID]
+	p.Stock = 7
+	store.products[tea.ID] = p
+	// jumlah ledger cocok tapi balance terakhir tidak
+	store.stockMovements = append(store.stockMovements,
+		models.StockMovement{ProductID: bread.ID, Type: models.StockMovementAdjustment, Quantity: -1, Balance: 5},
+		models.StockMovement{ProductID: bread.ID, Type: models.StockMovementAdjustment, Quantity: 1, Balance: 4},
+	)
+
+	check, err = repo.Check()
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if check.Consistent || len(check.Discrepancies) != 2 {
+		t.Fatalf("check = %+v, want 2 selisih", check)
+	}
+
+	tests := []struct {
+		productID, stock, ledger, lastBalance, difference int
+	}{
+		{tea.ID, 7, 10, 10, -3},
+		{bread.ID, 3, 3, 4, 0},
+	}
+	for i, tt := range tests {
+		d := check.Discrepancies[i]
+		if d.ProductID != tt.productID || d.Stock != tt.stock || d.LedgerStock != tt.ledger ||
+			d.LastBalance == nil || *d.LastBalance != tt.lastBalance || d.Difference != tt.difference {
+			t.Errorf("discrepancies[%d] = %+v, want %+v", i, d, tt)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"math"
+)
+
+// lineTax - hasil perhitungan pajak dan service charge satu baris keranjang
+type lineTax struct {
+	rate          float64
+	base          int // DPP
+	tax           int // PPN
+	serviceCharge int
+	total         int // yang dibayar pelanggan untuk baris ini
+}
+
+// effectiveTaxRate - produk bebas pajak -> 0, lalu tarif produk, tarif kategori, tarif default
+func effectiveTaxRate(p lockedProduct, cfg models.TaxConfig) float64 {
+	if p.taxExempt {
+		return 0
+	}
+	if p.taxRate != nil {
+		return *p.taxRate
+	}
+	return cfg.DefaultRate
+}
+
+// calculateLineTax - hitung DPP, PPN dan service charge dari nilai baris setelah diskon.
+// Mode inclusive: net sudah termasuk PPN, DPP = net * 100 / (100 + tarif).
+// Mode exclusive: DPP = net, PPN ditambahkan di atasnya.
+// Service charge dihitung dari DPP dan tidak dikenai PPN.
+func calculateLineTax(net int, rate float64, cfg models.TaxConfig) lineTax {
+	bp := basisPoints(rate)
+
+	var base, tax int
+	if cfg.Mode == models.TaxModeInclusive {
+		base = divRound(net*10000, 10000+bp)
+		tax = net - base
+	} else {
+		base = net
+		tax = divRound(net*bp, 10000)
+	}
+
+	serviceCharge := divRound(base*basisPoints(cfg.ServiceChargeRate), 10000)
+
+	return lineTax{
+		rate:          rate,
+		base:          base,
+		tax:           tax,
+		serviceCharge: serviceCharge,
+		total:         base + tax + serviceCharge,
+	}
+}
+
+// basisPoints - persen ke basis point, 11 -> 1100, 11.5 -> 1150
+func basisPoints(percent float64) int {
+	return int(math.Round(percent * 100))
+}
+
+// divRound - pembagian bilangan tidak negatif dengan pembulatan ke terdekat
+func divRound(a, b int) int {
+	return (a + b/2) / b
+}
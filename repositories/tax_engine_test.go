@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"testing"
+)
+
+func TestCalculateLineTax(t *testing.T) {
+	inclusive := models.TaxConfig{Mode: models.TaxModeInclusive, DefaultRate: 11}
+	exclusive := models.TaxConfig{Mode: models.TaxModeExclusive, DefaultRate: 11}
+	cafe := models.TaxConfig{Mode: models.TaxModeExclusive, DefaultRate: 11, ServiceChargeRate: 5}
+
+	tests := []struct {
+		name string
+		net  int
+		rate float64
+		cfg  models.TaxConfig
+		want lineTax
+	}{
+		{
+			name: "inclusive 11%",
+			net:  111000, rate: 11, cfg: inclusive,
+			want: lineTax{rate: 11, base: 100000, tax: 11000, total: 111000},
+		},
+		{
+			name: "inclusive dengan pembulatan",
+			net:  10000, rate: 11, cfg: inclusive,
+			want: lineTax{rate: 11, base: 9009, tax: 991, total: 10000},
+		},
+		{
+			name: "exclusive 12%",
+			net:  50000, rate: 12, cfg: exclusive,
+			want: lineTax{rate: 12, base: 50000, tax: 6000, total: 56000},
+		},
+		{
+			name: "bebas pajak",
+			net:  20000, rate: 0, cfg: inclusive,
+			want: lineTax{rate: 0, base: 20000, tax: 0, total: 20000},
+		},
+		{
+			name: "service charge dari DPP, tidak kena PPN",
+			net:  100000, rate: 11, cfg: cafe,
+			want: lineTax{rate: 11, base: 100000, tax: 11000, serviceCharge: 5000, total: 116000},
+		},
+		{
+			name: "tarif pecahan",
+			net:  10000, rate: 11.5, cfg: exclusive,
+			want: lineTax{rate: 11.5, base: 10000, tax: 1150, total: 11150},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calculateLineTax(tt.net, tt.rate, tt.cfg); got != tt.want {
+				t.Errorf("calculateLineTax(%d, %v) = %+v, want %+v", tt.net, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectiveTaxRate(t *testing.T) {
+	cfg := models.TaxConfig{Mode: models.TaxModeInclusive, DefaultRate: 11}
+	twelve := 12.0
+
+	tests := []struct {
+		name    string
+		product lockedProduct
+		want    float64
+	}{
+		{name: "tarif default", product: lockedProduct{}, want: 11},
+		{name: "tarif produk/kategori", product: lockedProduct{taxRate: &twelve}, want: 12},
+		{name: "bebas pajak mengalahkan tarif", product: lockedProduct{taxRate: &twelve, taxExempt: true}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := effectiveTaxRate(tt.product, cfg); got != tt.want {
+				t.Errorf("effectiveTaxRate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"database/sql"
+)
+
+var (
+	errTerminalNotFound  = &models.NotFoundError{Message: "terminal tidak ditemukan"}
+	errTerminalCodeTaken = &models.ConflictError{Message: "kode terminal sudah dipakai"}
+	errInvalidTerminal   = models.NewValidationError("terminal_id", "terminal tidak ditemukan")
+	errInactiveTerminal  = models.NewValidationError("terminal_id", "terminal tidak aktif")
+)
+
+type PostgresTerminalRepository struct {
+	db *sql.DB
+}
+
+func NewTerminalRepository(db *sql.DB) *PostgresTerminalRepository {
+	return &PostgresTerminalRepository{db: db}
+}
+
+func (repo *PostgresTerminalRepository) GetAll() ([]models.Terminal, error) {
+	rows, err := repo.db.Query("SELECT id, code, name, active, printer_address, printer_width, created_at FROM terminals ORDER BY code")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terminals := make([]models.Terminal, 0)
+	for rows.Next() {
+		var t models.Terminal
+		if err := rows.Scan(&t.ID, &t.Code, &t.Name, &t.Active, &t.PrinterAddress, &t.PrinterWidth, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		terminals = append(terminals, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return terminals, nil
+}
+
+func (repo *PostgresTerminalRepository) GetByID(id int) (*models.Terminal, error) {
+	var t models.Terminal
+	err := repo.db.QueryRow("SELECT id, code, name, active, printer_address, printer_width, created_at FROM terminals WHERE id = $1", id).
+		Scan(&t.ID, &t.Code, &t.Name, &t.Active, &t.PrinterAddress, &t.PrinterWidth, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errTerminalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (repo *PostgresTerminalRepository) Create(terminal *models.Terminal) error {
+	err := repo.db.QueryRow(
+		"INSERT INTO terminals (code, name, active, printer_address, printer_width) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		terminal.Code, terminal.Name, terminal.Active, terminal.PrinterAddress, terminal.PrinterWidth,
+	).Scan(&terminal.ID, &terminal.CreatedAt)
+	if isUniqueViolation(err) {
+		return errTerminalCodeTaken
+	}
+	return err
+}
+
+func (repo *PostgresTerminalRepository) Update(terminal *models.Terminal) error {
+	err := repo.db.QueryRow(
+		"UPDATE terminals SET code = $1, name = $2, active = $3, printer_address = $4, printer_width = $5 WHERE id = $6 RETURNING created_at",
+		terminal.Code, terminal.Name, terminal.Active, terminal.PrinterAddress, terminal.PrinterWidth, terminal.ID,
+	).Scan(&terminal.CreatedAt)
+	if err == sql.ErrNoRows {
+		return errTerminalNotFound
+	}
+	if isUniqueViolation(err) {
+		return errTerminalCodeTaken
+	}
+	return err
+}
+
+// checkTerminal - terminal harus ada dan aktif untuk dipakai checkout
+func checkTerminal(q queryer, id int) error {
+	var active bool
+	err := q.QueryRow("SELECT active FROM terminals WHERE id = $1", id).Scan(&active)
+	if err == sql.ErrNoRows {
+		return errInvalidTerminal
+	}
+	if err != nil {
+		return err
+	}
+	if !active {
+		return errInactiveTerminal
+	}
+	return nil
+}
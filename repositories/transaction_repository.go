@@ -4,95 +4,1038 @@ import (
 	"aplikasi-kasir/models"
 	"database/sql"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
-type TransactionRepository struct {
-	db *sql.DB
+type PostgresTransactionRepository struct {
+	db            *sql.DB
+	taxConfig     models.TaxConfig
+	receiptConfig models.ReceiptNumberConfig
+}
+
+func NewTransactionRepository(db *sql.DB, taxConfig models.TaxConfig, receiptConfig models.ReceiptNumberConfig) *PostgresTransactionRepository {
+	return &PostgresTransactionRepository{db: db, taxConfig: taxConfig, receiptConfig: receiptConfig}
 }
 
-func NewTransactionRepository(db *sql.DB) *TransactionRepository {
-	return &TransactionRepository{db: db}
+type lockedProduct struct {
+	name        string
+	price       int
+	stock       int
+	categoryID  *int
+	category    string // nama kategori, disimpan sebagai snapshot di transaction_details
+	parentID    *int   // induk varian, disimpan sebagai snapshot bersama namanya
+	parentName  string
+	taxRate     *float64 // tarif produk, atau tarif kategori jika produk kosong
+	taxExempt   bool
+	active      bool                // false = sudah dihapus, tidak bisa dijual
+	hasVariants bool                // produk induk, tidak bisa dijual langsung
+	recipe      []models.RecipeItem // produk komposit: stok komponen yang dikurangi, bukan stok produk
 }
 
-func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem) (*models.Transaction, error) {
+// CreateTransaction - simpan transaksi dan kurangi stok.
+// useLock = true  -> pessimistic: baris produk di-lock (SELECT ... FOR UPDATE) sebelum stok dicek.
+// useLock = false -> optimistic: tanpa lock di awal, pengurangan stok memakai UPDATE bersyarat
+// (stock >= qty) sehingga checkout yang kalah balapan ditolak, bukan membuat stok minus.
+//
+// Jika req.IdempotencyKey diisi dan key tersebut sudah pernah dipakai dengan payload yang sama,
+// transaksi lama dikembalikan dengan replayed = true tanpa mengubah stok.
+func (repo *PostgresTransactionRepository) CreateTransaction(req models.CheckoutRequest, useLock bool) (*models.Transaction, bool, error) {
 	tx, err := repo.db.Begin()
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	defer tx.Rollback()
 
-	totalAmount := 0
-	details := make([]models.TransactionDetail, 0)
+	if req.IdempotencyKey != "" {
+		existingID, err := claimIdempotencyKey(tx, req.IdempotencyKey, req.RequestHash)
+		if err != nil {
+			return nil, false, err
+		}
+		if existingID != 0 {
+			tx.Rollback()
+			transaction, err := repo.GetByID(existingID)
+			if err != nil {
+				return nil, false, err
+			}
+			return transaction, true, nil
+		}
+	}
+
+	if err := checkTerminal(tx, req.TerminalID); err != nil {
+		return nil, false, err
+	}
 
+	shiftID, err := openShiftID(tx, req.TerminalID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	today, err := currentDate(tx)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := lockOpenDay(tx, today); err != nil {
+		return nil, false, err
+	}
+
+	items := req.Items
+
+	// total quantity per produk (item dengan product_id sama dijumlahkan)
+	requested := make(map[int]int)
+	productIDs := make([]int, 0, len(items))
 	for _, item := range items {
-		var productPrice, stock int
-		var productName string
+		if _, ok := requested[item.ProductID]; !ok {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+	// urutan id yang sama di setiap checkout supaya lock tidak saling deadlock
+	sort.Ints(productIDs)
 
-		err := tx.QueryRow("SELECT name, price, stock FROM products WHERE id = $1", item.ProductID).Scan(&productName, &productPrice, &stock)
+	// resep dibaca lebih dulu supaya komponen ikut di-lock dalam satu query berurutan id
+	recipes, err := loadRecipes(tx, productIDs)
+	if err != nil {
+		return nil, false, err
+	}
+	lockIDs := slices.Clone(productIDs)
+	for _, recipe := range recipes {
+		for _, item := range recipe {
+			if !slices.Contains(lockIDs, item.ComponentID) {
+				lockIDs = append(lockIDs, item.ComponentID)
+			}
+		}
+	}
+	sort.Ints(lockIDs)
+
+	products, err := repo.loadProducts(tx, lockIDs, useLock)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, id := range productIDs {
+		p, ok := products[id]
+		if !ok {
+			return nil, false, &models.NotFoundError{Message: fmt.Sprintf("produk id %d tidak ditemukan", id)}
+		}
+		p.recipe = recipes[id]
+		products[id] = p
+	}
+
+	if err := checkSellable(productIDs, products); err != nil {
+		return nil, false, err
+	}
+
+	stockIDs, needed := stockRequirements(productIDs, products, requested)
+	if shortages := stockShortages(stockIDs, products, needed); len(shortages) > 0 {
+		return nil, false, &models.InsufficientStockError{Items: shortages}
+	}
+
+	balances := make(map[int]int, len(stockIDs))
+	for i, id := range stockIDs {
+		var balance int
+		err := tx.QueryRow(
+			"UPDATE products SET stock = stock - $1 WHERE id = $2 AND stock >= $1 RETURNING stock", needed[id], id,
+		).Scan(&balance)
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("product id %d not found", item.ProductID)
+			// stok sudah diambil checkout lain sejak dibaca (mode optimistic)
+			return nil, false, repo.stockConflict(tx, stockIDs, needed, i)
 		}
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
+		balances[id] = balance
+	}
+
+	// promo dievaluasi dengan harga yang sudah di-lock; items diasumsikan sudah
+	// dinormalisasi service (satu baris per product_id)
+	promotions, err := activePromotions(tx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	t, err := buildTransaction(req, products, promotions, repo.taxConfig)
+	if err != nil {
+		return nil, false, err
+	}
+	t.ShiftID = &shiftID
+
+	t.ReceiptNumber, err = nextReceiptNumber(tx, repo.receiptConfig, today)
+	if err != nil {
+		return nil, false, err
+	}
 
-		subtotal := productPrice * item.Quantity
-		totalAmount += subtotal
+	err = tx.QueryRow(
+		`INSERT INTO transactions (
+			receipt_number, cashier_id, terminal_id, shift_id, subtotal_amount, discount_amount, price_includes_tax, tax_base, tax_amount,
+			service_charge, total_amount, paid_amount, change_amount
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, created_at`,
+		t.ReceiptNumber, t.CashierID, t.TerminalID, t.ShiftID, t.SubtotalAmount, t.DiscountAmount, t.PriceIncludesTax, t.TaxBase, t.TaxAmount,
+		t.ServiceCharge, t.TotalAmount, t.PaidAmount, t.ChangeAmount,
+	).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return nil, false, err
+	}
 
-		_, err = tx.Exec("UPDATE products SET stock = stock - $1 WHERE id = $2", item.Quantity, item.ProductID)
+	for _, ap := range t.Promotions {
+		_, err := tx.Exec(
+			`INSERT INTO transaction_promotions (transaction_id, promotion_id, promotion_name, product_id, discount_amount)
+			VALUES ($1, $2, $3, $4, $5)`,
+			t.ID, ap.PromotionID, ap.PromotionName, ap.ProductID, ap.DiscountAmount,
+		)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
+	}
 
-		details = append(details, models.TransactionDetail{
-			ProductID:   item.ProductID,
-			ProductName: productName,
-			Quantity:    item.Quantity,
-			Subtotal:    subtotal,
-		})
+	for i := range t.Payments {
+		p := &t.Payments[i]
+		p.TransactionID = t.ID
+		err := tx.QueryRow(
+			"INSERT INTO payments (transaction_id, method, amount, tendered_amount, reference) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+			t.ID, p.Method, p.Amount, p.TenderedAmount, p.Reference,
+		).Scan(&p.ID)
+		if err != nil {
+			return nil, false, err
+		}
 	}
 
-	var transactionID int
-	err = tx.QueryRow("INSERT INTO transactions (total_amount) VALUES ($1) RETURNING id", totalAmount).Scan(&transactionID)
-	if err != nil {
-		return nil, err
+	if req.IdempotencyKey != "" {
+		_, err = tx.Exec("UPDATE idempotency_keys SET transaction_id = $1 WHERE key = $2", t.ID, req.IdempotencyKey)
+		if err != nil {
+			return nil, false, err
+		}
 	}
 
-	if len(details) > 0 {
-		query := "INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES "
+	if len(t.Details) > 0 {
+		// nama, harga, kategori, dan induk varian disimpan (snapshot) supaya riwayat tidak berubah
+		// ketika produk (atau induknya) di-rename, harganya diubah, atau dipindah kategori
+		columns := []string{
+			"transaction_id", "product_id", "product_name", "product_price", "category_id", "category_name",
+			"parent_id", "parent_name", "quantity", "subtotal", "discount_amount", "tax_rate", "tax_base", "tax_amount", "service_charge", "total_amount",
+		}
+		query := "INSERT INTO transaction_details (" + strings.Join(columns, ", ") + ") VALUES "
 		args := []interface{}{}
 
-		for i, d := range details {
-			details[i].TransactionID = transactionID
+		for i, d := range t.Details {
+			t.Details[i].TransactionID = t.ID
 
-			// ($1, $2, $3, $4), ($5, $6, $7, $8), ...
-			base := i * 4
-			query += fmt.Sprintf("($%d, $%d, $%d, $%d),", base+1, base+2, base+3, base+4)
+			// ($1, $2, ..., $16), ($17, $18, ..., $32), ...
+			placeholders := make([]string, len(columns))
+			for k := range columns {
+				placeholders[k] = fmt.Sprintf("$%d", i*len(columns)+k+1)
+			}
+			query += "(" + strings.Join(placeholders, ", ") + "),"
 
 			args = append(args,
-				transactionID,
+				t.ID,
 				d.ProductID,
+				d.ProductName,
+				d.ProductPrice,
+				d.CategoryID,
+				d.CategoryName,
+				d.ParentID,
+				d.ParentName,
 				d.Quantity,
 				d.Subtotal,
+				d.DiscountAmount,
+				d.TaxRate,
+				d.TaxBase,
+				d.TaxAmount,
+				d.ServiceCharge,
+				d.TotalAmount,
 			)
 		}
 
 		query = strings.TrimSuffix(query, ",")
 
 		_, err = tx.Exec(query, args...)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := insertDetailComponents(tx, t); err != nil {
+		return nil, false, err
+	}
+
+	for _, id := range stockIDs {
+		m := saleStockMovement(t, id, needed[id], balances[id])
+		if err := insertStockMovement(tx, &m); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+
+	return t, false, nil
+}
+
+// loadRecipes - resep produk komposit di keranjang beserta nama komponen saat ini
+func loadRecipes(tx *sql.Tx, productIDs []int) (map[int][]models.RecipeItem, error) {
+	ids := make([]int64, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int64(id)
+	}
+
+	rows, err := tx.Query(`
+		SELECT pc.product_id, pc.component_id, p.name, pc.quantity
+		FROM product_components pc
+		JOIN products p ON p.id = pc.component_id
+		WHERE pc.product_id = ANY($1)
+		ORDER BY pc.product_id, pc.component_id
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipes := make(map[int][]models.RecipeItem)
+	for rows.Next() {
+		var productID int
+		var item models.RecipeItem
+		if err := rows.Scan(&productID, &item.ComponentID, &item.ComponentName, &item.Quantity); err != nil {
+			return nil, err
+		}
+		recipes[productID] = append(recipes[productID], item)
+	}
+
+	return recipes, rows.Err()
+}
+
+// insertDetailComponents - snapshot resep per baris transaksi. Baris dicari lewat product_id
+// karena keranjang sudah dinormalisasi (satu baris per produk).
+func insertDetailComponents(tx *sql.Tx, t *models.Transaction) error {
+	var productIDs, componentIDs, quantities []int64
+	var names []string
+	for _, d := range t.Details {
+		for _, c := range d.Components {
+			productIDs = append(productIDs, int64(d.ProductID))
+			componentIDs = append(componentIDs, int64(c.ComponentID))
+			names = append(names, c.ComponentName)
+			quantities = append(quantities, int64(c.Quantity))
+		}
+	}
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO transaction_detail_components (transaction_detail_id, component_id, component_name, quantity)
+		SELECT td.id, c.component_id, c.component_name, c.quantity
+		FROM unnest($2::int[], $3::int[], $4::text[], $5::int[]) AS c(product_id, component_id, component_name, quantity)
+		JOIN transaction_details td ON td.transaction_id = $1 AND td.product_id = c.product_id
+	`, t.ID, pq.Array(productIDs), pq.Array(componentIDs), pq.Array(names), pq.Array(quantities))
+	return err
+}
+
+// nextReceiptNumber - ambil nomor struk berikutnya untuk outlet dan tanggal bisnis day. Baris
+// receipt_sequences tetap ter-lock sampai checkout commit, jadi checkout lain menunggu dan nomor
+// yang dipakai checkout yang gagal (rollback) dipakai lagi oleh checkout berikutnya.
+func nextReceiptNumber(tx *sql.Tx, cfg models.ReceiptNumberConfig, day time.Time) (string, error) {
+	var n int
+	err := tx.QueryRow(`
+		INSERT INTO receipt_sequences (outlet_code, business_date, last_number) VALUES ($1, $2, 1)
+		ON CONFLICT (outlet_code, business_date) DO UPDATE SET last_number = receipt_sequences.last_number + 1
+		RETURNING last_number
+	`, cfg.OutletCode, day).Scan(&n)
+	if err != nil {
+		return "", err
+	}
+	return formatReceiptNumber(cfg, day, n), nil
+}
+
+// claimIdempotencyKey - daftarkan key di dalam transaksi checkout. Request duplikat yang datang
+// bersamaan akan menunggu di unique index sampai transaksi pertama commit/rollback.
+// Mengembalikan id transaksi lama jika key sudah selesai dipakai dengan payload yang sama.
+func claimIdempotencyKey(tx *sql.Tx, key, requestHash string) (int, error) {
+	result, err := tx.Exec(
+		"INSERT INTO idempotency_keys (key, request_hash) VALUES ($1, $2) ON CONFLICT (key) DO NOTHING",
+		key, requestHash,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rows == 1 {
+		return 0, nil
+	}
+
+	var storedHash string
+	var transactionID sql.NullInt64
+	err = tx.QueryRow("SELECT request_hash, transaction_id FROM idempotency_keys WHERE key = $1", key).
+		Scan(&storedHash, &transactionID)
+	if err != nil {
+		return 0, err
+	}
+
+	if storedHash != requestHash {
+		return 0, &models.ConflictError{Message: "Idempotency-Key sudah dipakai untuk request checkout yang berbeda"}
+	}
+
+	if !transactionID.Valid {
+		return 0, &models.ConflictError{Message: "request dengan Idempotency-Key ini masih diproses"}
+	}
+
+	return int(transactionID.Int64), nil
+}
+
+// sortable column yang boleh dipakai di ORDER BY
+var transactionSortColumns = map[string]string{
+	"id":           "t.id",
+	"created_at":   "t.created_at",
+	"total_amount": "t.total_amount",
+}
+
+// GetAll - daftar transaksi (tanpa detail) sesuai filter, beserta total data untuk paginasi
+func (repo *PostgresTransactionRepository) GetAll(filter models.TransactionFilter) (*models.TransactionList, error) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.StartDate != nil {
+		addCondition("DATE(t.created_at) >= $%d", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		addCondition("DATE(t.created_at) <= $%d", *filter.EndDate)
+	}
+	if filter.MinAmount != nil {
+		addCondition("t.total_amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		addCondition("t.total_amount <= $%d", *filter.MaxAmount)
+	}
+	if filter.ProductID != nil {
+		addCondition("EXISTS (SELECT 1 FROM transaction_details td WHERE td.transaction_id = t.id AND td.product_id = $%d)", *filter.ProductID)
+	}
+	if filter.CashierID != nil {
+		addCondition("t.cashier_id = $%d", *filter.CashierID)
+	}
+	if filter.TerminalID != nil {
+		addCondition("t.terminal_id = $%d", *filter.TerminalID)
+	}
+	if filter.ShiftID != nil {
+		addCondition("t.shift_id = $%d", *filter.ShiftID)
+	}
+	if filter.ReceiptNumber != "" {
+		addCondition("t.receipt_number ILIKE '%%' || $%d || '%%'", filter.ReceiptNumber)
+	}
+	if filter.Status != "" {
+		addCondition("t.status = $%d", filter.Status)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	list := &models.TransactionList{
+		Data:  []models.Transaction{},
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}
+
+	err := repo.db.QueryRow("SELECT COUNT(*) FROM transactions t"+where, args...).Scan(&list.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	column, ok := transactionSortColumns[filter.SortBy]
+	if !ok {
+		column = "t.created_at"
+	}
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+
+	query := `
+		SELECT t.id, COALESCE(t.receipt_number, ''), t.cashier_id, t.terminal_id, t.shift_id, t.subtotal_amount, t.discount_amount, t.price_includes_tax, t.tax_base, t.tax_amount,
+			t.service_charge, t.total_amount, t.paid_amount, t.change_amount, t.status,
+			COALESCE((SELECT SUM(r.total_amount) FROM refunds r WHERE r.transaction_id = t.id), 0),
+			t.created_at
+		FROM transactions t` + where +
+		fmt.Sprintf(" ORDER BY %s %s, t.id %s LIMIT $%d OFFSET $%d", column, direction, direction, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.Transaction
+		var cashierID, terminalID, shiftID sql.NullInt64
+		if err := rows.Scan(&t.ID, &t.ReceiptNumber, &cashierID, &terminalID, &shiftID, &t.SubtotalAmount, &t.DiscountAmount, &t.PriceIncludesTax, &t.TaxBase, &t.TaxAmount,
+			&t.ServiceCharge, &t.TotalAmount, &t.PaidAmount, &t.ChangeAmount, &t.Status, &t.RefundedAmount, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		t.CashierID = nullIntPtr(cashierID)
+		t.TerminalID = nullIntPtr(terminalID)
+		t.ShiftID = nullIntPtr(shiftID)
+		list.Data = append(list.Data, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// GetByID - ambil transaksi lengkap dengan detail item dan riwayat refund
+func (repo *PostgresTransactionRepository) GetByID(id int) (*models.Transaction, error) {
+	var t models.Transaction
+	var voidReason sql.NullString
+	var voidedAt sql.NullTime
+	var cashierID, terminalID, shiftID sql.NullInt64
+
+	err := repo.db.QueryRow(`
+		SELECT id, COALESCE(receipt_number, ''), cashier_id, terminal_id, shift_id, subtotal_amount, discount_amount, price_includes_tax, tax_base, tax_amount, service_charge,
+			total_amount, paid_amount, change_amount, status, void_reason, voided_at, created_at
+		FROM transactions
+		WHERE id = $1
+	`, id).Scan(&t.ID, &t.ReceiptNumber, &cashierID, &terminalID, &shiftID, &t.SubtotalAmount, &t.DiscountAmount, &t.PriceIncludesTax, &t.TaxBase, &t.TaxAmount, &t.ServiceCharge,
+		&t.TotalAmount, &t.PaidAmount, &t.ChangeAmount, &t.Status, &voidReason, &voidedAt, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, &models.NotFoundError{Message: "transaksi tidak ditemukan"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t.CashierID = nullIntPtr(cashierID)
+	t.TerminalID = nullIntPtr(terminalID)
+	t.ShiftID = nullIntPtr(shiftID)
+	t.VoidReason = voidReason.String
+	if voidedAt.Valid {
+		t.VoidedAt = &voidedAt.Time
+	}
+
+	t.Details, err = repo.getDetails(repo.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Promotions, err = repo.getAppliedPromotions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range t.Details {
+		t.Details[i].Promotions = promotionsForProduct(t.Promotions, t.Details[i].ProductID)
+	}
+
+	t.Payments, err = repo.getPayments(id)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Refunds, err = repo.getRefunds(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, refund := range t.Refunds {
+		t.RefundedAmount += refund.TotalAmount
+	}
+
+	return &t, nil
+}
+
+// queryer - *sql.DB atau *sql.Tx
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func (repo *PostgresTransactionRepository) getDetails(q queryer, transactionID int) ([]models.TransactionDetail, error) {
+	rows, err := q.Query(`
+		SELECT td.id, td.transaction_id, td.product_id, td.product_name, td.product_price, td.category_id, td.category_name,
+			td.parent_id, td.parent_name, td.quantity,
+			COALESCE((SELECT SUM(rd.quantity) FROM refund_details rd WHERE rd.transaction_detail_id = td.id), 0),
+			td.subtotal, td.discount_amount, td.tax_rate, td.tax_base, td.tax_amount, td.service_charge, td.total_amount
+		FROM transaction_details td
+		WHERE td.transaction_id = $1
+		ORDER BY td.id
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	details := make([]models.TransactionDetail, 0)
+	for rows.Next() {
+		var d models.TransactionDetail
+		var categoryID, parentID sql.NullInt64
+		err := rows.Scan(
+			&d.ID, &d.TransactionID, &d.ProductID, &d.ProductName, &d.ProductPrice, &categoryID, &d.CategoryName,
+			&parentID, &d.ParentName, &d.Quantity, &d.RefundedQuantity,
+			&d.Subtotal, &d.DiscountAmount, &d.TaxRate, &d.TaxBase, &d.TaxAmount, &d.ServiceCharge, &d.TotalAmount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		d.CategoryID = nullIntPtr(categoryID)
+		d.ParentID = nullIntPtr(parentID)
+		d.NetAmount = d.Subtotal - d.DiscountAmount
+		details = append(details, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	return details, fillDetailComponents(q, transactionID, details)
+}
+
+// fillDetailComponents - snapshot resep untuk baris produk komposit
+func fillDetailComponents(q queryer, transactionID int, details []models.TransactionDetail) error {
+	rows, err := q.Query(`
+		SELECT tdc.transaction_detail_id, tdc.component_id, tdc.component_name, tdc.quantity
+		FROM transaction_detail_components tdc
+		JOIN transaction_details td ON td.id = tdc.transaction_detail_id
+		WHERE td.transaction_id = $1
+		ORDER BY tdc.component_id
+	`, transactionID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var detailID int
+		var c models.RecipeItem
+		if err := rows.Scan(&detailID, &c.ComponentID, &c.ComponentName, &c.Quantity); err != nil {
+			return err
+		}
+		for i := range details {
+			if details[i].ID == detailID {
+				details[i].Components = append(details[i].Components, c)
+			}
+		}
+	}
+
+	return rows.Err()
+}
+
+func (repo *PostgresTransactionRepository) getAppliedPromotions(transactionID int) ([]models.AppliedPromotion, error) {
+	rows, err := repo.db.Query(`
+		SELECT promotion_id, promotion_name, product_id, discount_amount
+		FROM transaction_promotions
+		WHERE transaction_id = $1
+		ORDER BY id
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make([]models.AppliedPromotion, 0)
+	for rows.Next() {
+		var ap models.AppliedPromotion
+		var promotionID, productID sql.NullInt64
+		if err := rows.Scan(&promotionID, &ap.PromotionName, &productID, &ap.DiscountAmount); err != nil {
+			return nil, err
+		}
+		if promotionID.Valid {
+			id := int(promotionID.Int64)
+			ap.PromotionID = &id
+		}
+		if productID.Valid {
+			id := int(productID.Int64)
+			ap.ProductID = &id
+		}
+		applied = append(applied, ap)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+func (repo *PostgresTransactionRepository) getPayments(transactionID int) ([]models.Payment, error) {
+	rows, err := repo.db.Query(`
+		SELECT id, transaction_id, method, amount, tendered_amount, COALESCE(reference, '')
+		FROM payments
+		WHERE transaction_id = $1
+		ORDER BY id
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := make([]models.Payment, 0)
+	for rows.Next() {
+		var p models.Payment
+		if err := rows.Scan(&p.ID, &p.TransactionID, &p.Method, &p.Amount, &p.TenderedAmount, &p.Reference); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (repo *PostgresTransactionRepository) getRefunds(transactionID int) ([]models.Refund, error) {
+	rows, err := repo.db.Query(`
+		SELECT r.id, r.transaction_id, r.reason, r.total_amount, r.tax_amount, r.cash_amount, r.shift_id, r.created_at,
+			rd.id, rd.transaction_detail_id, rd.product_id, td.product_name, rd.quantity, rd.amount, rd.tax_amount
+		FROM refunds r
+		JOIN refund_details rd ON rd.refund_id = r.id
+		JOIN transaction_details td ON td.id = rd.transaction_detail_id
+		WHERE r.transaction_id = $1
+		ORDER BY r.id, rd.id
+	`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := make([]models.Refund, 0)
+	for rows.Next() {
+		var r models.Refund
+		var item models.RefundItem
+		var shiftID sql.NullInt64
+		err := rows.Scan(
+			&r.ID, &r.TransactionID, &r.Reason, &r.TotalAmount, &r.TaxAmount, &r.CashAmount, &shiftID, &r.CreatedAt,
+			&item.ID, &item.TransactionDetailID, &item.ProductID, &item.ProductName, &item.Quantity, &item.Amount, &item.TaxAmount,
+		)
 		if err != nil {
 			return nil, err
 		}
+
+		r.ShiftID = nullIntPtr(shiftID)
+		item.RefundID = r.ID
+		if n := len(refunds); n > 0 && refunds[n-1].ID == r.ID {
+			refunds[n-1].Items = append(refunds[n-1].Items, item)
+			continue
+		}
+
+		r.Items = []models.RefundItem{item}
+		refunds = append(refunds, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// Void - batalkan transaksi hari ini yang belum pernah direfund, seluruh stok dikembalikan
+func (repo *PostgresTransactionRepository) Void(id int, req models.VoidRequest) (*models.Transaction, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status, receiptNumber string
+	var createdDate time.Time
+	var isToday bool
+	var shiftID sql.NullInt64
+	err = tx.QueryRow(
+		"SELECT status, receipt_number, DATE(created_at), DATE(created_at) = CURRENT_DATE, shift_id FROM transactions WHERE id = $1 FOR UPDATE", id,
+	).Scan(&status, &receiptNumber, &createdDate, &isToday, &shiftID)
+	if err == sql.ErrNoRows {
+		return nil, &models.NotFoundError{Message: "transaksi tidak ditemukan"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// FOR SHARE: shift tidak bisa ditutup sampai void selesai
+	shiftClosed := false
+	if shiftID.Valid {
+		var shiftStatus string
+		if err := tx.QueryRow("SELECT status FROM shifts WHERE id = $1 FOR SHARE", shiftID.Int64).Scan(&shiftStatus); err != nil {
+			return nil, err
+		}
+		shiftClosed = shiftStatus != models.ShiftStatusOpen
+	}
+
+	if err := checkVoidable(status, isToday, shiftClosed); err != nil {
+		return nil, err
+	}
+
+	if err := lockOpenDay(tx, createdDate); err != nil {
+		return nil, err
+	}
+
+	details, err := repo.getDetails(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	returned := make(map[int]int)
+	for _, d := range details {
+		addReturnedStock(returned, d, d.Quantity)
+	}
+
+	if err := restoreStock(tx, returned, voidStockMovement(id, receiptNumber, req)); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE transactions SET status = $1, void_reason = $2, voided_at = CURRENT_TIMESTAMP WHERE id = $3",
+		models.TransactionStatusVoided, req.Reason, id,
+	)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
-	return &models.Transaction{
-		ID:          transactionID,
-		TotalAmount: totalAmount,
-		Details:     details,
-	}, nil
+	return repo.GetByID(id)
+}
+
+// Refund - kembalikan sebagian atau seluruh item transaksi dan tambahkan stoknya kembali
+func (repo *PostgresTransactionRepository) Refund(id int, req models.RefundRequest) (*models.Refund, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status, receiptNumber string
+	var terminalID sql.NullInt64
+	err = tx.QueryRow(
+		"SELECT status, receipt_number, terminal_id FROM transactions WHERE id = $1 FOR UPDATE", id,
+	).Scan(&status, &receiptNumber, &terminalID)
+	if err == sql.ErrNoRows {
+		return nil, &models.NotFoundError{Message: "transaksi tidak ditemukan"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status == models.TransactionStatusVoided {
+		return nil, &models.ConflictError{Message: "transaksi sudah di-void"}
+	}
+
+	// refund dihitung di laporan tanggal refund dibuat, jadi hari ini harus belum ditutup
+	today, err := currentDate(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockOpenDay(tx, today); err != nil {
+		return nil, err
+	}
+
+	details, err := repo.getDetails(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	refund, returned, newStatus, err := buildRefund(id, req, details)
+	if err != nil {
+		return nil, err
+	}
+
+	var cashPaid, cashRefunded int
+	err = tx.QueryRow(`
+		SELECT
+			COALESCE((SELECT SUM(amount) FROM payments WHERE transaction_id = $1 AND method = $2), 0),
+			COALESCE((SELECT SUM(cash_amount) FROM refunds WHERE transaction_id = $1), 0)
+	`, id, models.PaymentMethodCash).Scan(&cashPaid, &cashRefunded)
+	if err != nil {
+		return nil, err
+	}
+
+	refund.CashAmount = refundCashAmount(refund.TotalAmount, cashPaid, cashRefunded)
+	if refund.CashAmount > 0 {
+		// uang tunai keluar dari laci terminal tempat refund dilakukan
+		refundTerminal := req.TerminalID
+		if refundTerminal == 0 && terminalID.Valid {
+			refundTerminal = int(terminalID.Int64)
+		}
+		shiftID, err := openShiftID(tx, refundTerminal)
+		if err == errNoOpenShift {
+			return nil, errRefundNoShift
+		}
+		if err != nil {
+			return nil, err
+		}
+		refund.ShiftID = &shiftID
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO refunds (transaction_id, reason, total_amount, tax_amount, cash_amount, shift_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at",
+		id, refund.Reason, refund.TotalAmount, refund.TaxAmount, refund.CashAmount, refund.ShiftID,
+	).Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range refund.Items {
+		item := &refund.Items[i]
+		item.RefundID = refund.ID
+		err := tx.QueryRow(
+			"INSERT INTO refund_details (refund_id, transaction_detail_id, product_id, quantity, amount, tax_amount) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+			refund.ID, item.TransactionDetailID, item.ProductID, item.Quantity, item.Amount, item.TaxAmount,
+		).Scan(&item.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := restoreStock(tx, returned, refundStockMovement(refund, receiptNumber, req)); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("UPDATE transactions SET status = $1 WHERE id = $2", newStatus, id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// restoreStock - tambah stok kembali, urut berdasarkan id produk seperti saat checkout, dan catat
+// di ledger memakai tipe / referensi dari movement. Produk yang sudah dihapus dilewati.
+func restoreStock(tx *sql.Tx, quantities map[int]int, movement models.StockMovement) error {
+	productIDs := make([]int, 0, len(quantities))
+	for id := range quantities {
+		productIDs = append(productIDs, id)
+	}
+	sort.Ints(productIDs)
+
+	for _, id := range productIDs {
+		m := movement
+		m.ProductID, m.Quantity = id, quantities[id]
+		err := tx.QueryRow("UPDATE products SET stock = stock + $1 WHERE id = $2 RETURNING stock", m.Quantity, id).Scan(&m.Balance)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := insertStockMovement(tx, &m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadProducts - ambil name, price, stock untuk semua produk di keranjang, urut berdasarkan id
+func (repo *PostgresTransactionRepository) loadProducts(tx *sql.Tx, productIDs []int, forUpdate bool) (map[int]lockedProduct, error) {
+	ids := make([]int64, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int64(id)
+	}
+
+	query := `
+		SELECT p.id, p.name, p.price, p.stock, p.category_id, COALESCE(c.name, ''), p.parent_id, COALESCE(pp.name, ''),
+			COALESCE(p.tax_rate, c.tax_rate), p.tax_exempt, p.active,
+			EXISTS (SELECT 1 FROM products v WHERE v.parent_id = p.id AND v.active)
+		FROM products p
+		LEFT JOIN product_categories c ON c.id = p.category_id
+		LEFT JOIN products pp ON pp.id = p.parent_id
+		WHERE p.id = ANY($1)
+		ORDER BY p.id
+	`
+	if forUpdate {
+		query += " FOR UPDATE OF p"
+	}
+
+	rows, err := tx.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make(map[int]lockedProduct)
+	for rows.Next() {
+		var id int
+		var p lockedProduct
+		var categoryID, parentID sql.NullInt64
+		var taxRate sql.NullFloat64
+		if err := rows.Scan(&id, &p.name, &p.price, &p.stock, &categoryID, &p.category, &parentID, &p.parentName, &taxRate, &p.taxExempt, &p.active, &p.hasVariants); err != nil {
+			return nil, err
+		}
+		if taxRate.Valid {
+			p.taxRate = &taxRate.Float64
+		}
+		if categoryID.Valid {
+			cid := int(categoryID.Int64)
+			p.categoryID = &cid
+		}
+		p.parentID = nullIntPtr(parentID)
+		products[id] = p
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// stockConflict - baca ulang stok terbaru dan susun error untuk semua produk yang kurang.
+// productIDs[:failed] sudah dikurangi di transaksi ini, jadi dikembalikan dulu sebelum dicek.
+func (repo *PostgresTransactionRepository) stockConflict(tx *sql.Tx, productIDs []int, requested map[int]int, failed int) error {
+	products, err := repo.loadProducts(tx, productIDs, false)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range productIDs[:failed] {
+		p := products[id]
+		p.stock += requested[id]
+		products[id] = p
+	}
+
+	failedID := productIDs[failed]
+
+	shortages := stockShortages(productIDs, products, requested)
+	if len(shortages) == 0 {
+		p := products[failedID]
+		shortages = append(shortages, models.StockShortage{
+			ProductID:   failedID,
+			ProductName: p.name,
+			Requested:   requested[failedID],
+			Available:   p.stock,
+		})
+	}
+
+	return &models.InsufficientStockError{Items: shortages}
+}
+
+func nullIntPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
 }
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"aplikasi-kasir/models"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+var (
+	errUserNotFound  = &models.NotFoundError{Message: "user tidak ditemukan"}
+	errUsernameTaken = &models.ConflictError{Message: "username sudah dipakai"}
+)
+
+type PostgresUserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *PostgresUserRepository {
+	return &PostgresUserRepository{db: db}
+}
+
+const userColumns = "id, username, name, role, active, password_hash, pin_hash, permissions, created_at"
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var u models.User
+	var permissions pq.StringArray
+	err := row.Scan(&u.ID, &u.Username, &u.Name, &u.Role, &u.Active, &u.PasswordHash, &u.PINHash, &permissions, &u.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	u.HasPIN = u.PINHash != ""
+	u.Permissions = []string(permissions)
+	return &u, nil
+}
+
+func (repo *PostgresUserRepository) GetAll() ([]models.User, error) {
+	rows, err := repo.db.Query("SELECT " + userColumns + " FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (repo *PostgresUserRepository) GetByID(id int) (*models.User, error) {
+	u, err := scanUser(repo.db.QueryRow("SELECT "+userColumns+" FROM users WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, errUserNotFound
+	}
+	return u, err
+}
+
+// GetByUsername - username tidak case-sensitive
+func (repo *PostgresUserRepository) GetByUsername(username string) (*models.User, error) {
+	u, err := scanUser(repo.db.QueryRow("SELECT "+userColumns+" FROM users WHERE LOWER(username) = LOWER($1)", username))
+	if err == sql.ErrNoRows {
+		return nil, errUserNotFound
+	}
+	return u, err
+}
+
+func (repo *PostgresUserRepository) Count() (int, error) {
+	var count int
+	err := repo.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+	return count, err
+}
+
+func (repo *PostgresUserRepository) Create(user *models.User) error {
+	err := repo.db.QueryRow(
+		`INSERT INTO users (username, name, role, active, password_hash, pin_hash, permissions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`,
+		user.Username, user.Name, user.Role, user.Active, user.PasswordHash, user.PINHash, pq.StringArray(user.Permissions),
+	).Scan(&user.ID, &user.CreatedAt)
+	if isUniqueViolation(err) {
+		return errUsernameTaken
+	}
+	if isForeignKeyViolation(err) {
+		return errInvalidRole
+	}
+	user.HasPIN = user.PINHash != ""
+	return err
+}
+
+func (repo *PostgresUserRepository) Update(user *models.User) error {
+	err := repo.db.QueryRow(
+		`UPDATE users SET username = $1, name = $2, role = $3, active = $4, password_hash = $5, pin_hash = $6, permissions = $7
+		WHERE id = $8 RETURNING created_at`,
+		user.Username, user.Name, user.Role, user.Active, user.PasswordHash, user.PINHash, pq.StringArray(user.Permissions), user.ID,
+	).Scan(&user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return errUserNotFound
+	}
+	if isUniqueViolation(err) {
+		return errUsernameTaken
+	}
+	if isForeignKeyViolation(err) {
+		return errInvalidRole
+	}
+	user.HasPIN = user.PINHash != ""
+	return err
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ipAttemptMultiplier - satu IP (tablet kasir bersama) boleh gagal lebih banyak dari satu username
+const ipAttemptMultiplier = 4
+
+// dummyHash - dibandingkan saat username tidak ada supaya waktu respon sama dengan password salah
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+type AuthService struct {
+	users    repositories.UserRepository
+	sessions repositories.SessionRepository
+	roles    repositories.RoleRepository
+	limiter  *LoginLimiter
+	config   models.AuthConfig
+
+	// now - waktu UTC, karena kolom TIMESTAMP di Postgres disimpan tanpa zona waktu
+	now func() time.Time
+}
+
+func NewAuthService(users repositories.UserRepository, sessions repositories.SessionRepository, roles repositories.RoleRepository, config models.AuthConfig) *AuthService {
+	return &AuthService{
+		users:    users,
+		sessions: sessions,
+		roles:    roles,
+		limiter:  NewLoginLimiter(config.LoginWindow),
+		config:   config,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Login - username + password, atau username + PIN. clientIP dipakai untuk rate limit.
+func (s *AuthService) Login(req models.LoginRequest, clientIP string) (*models.AuthTokens, error) {
+	req.Username = strings.TrimSpace(req.Username)
+
+	var errs []models.FieldError
+	if req.Username == "" {
+		errs = append(errs, models.FieldError{Field: "username", Message: "username wajib diisi"})
+	}
+	if (req.Password == "") == (req.PIN == "") {
+		errs = append(errs, models.FieldError{Field: "password", Message: "isi salah satu dari password atau pin"})
+	}
+	if len(errs) > 0 {
+		return nil, &models.ValidationError{Errors: errs}
+	}
+
+	now := s.now()
+	userKey := "user:" + strings.ToLower(req.Username)
+	ipKey := "ip:" + clientIP
+
+	// percobaan dicatat sebagai gagal sebelum bcrypt, dibatalkan lagi jika ternyata bukan salah
+	// password/PIN
+	if err := s.reserveLoginAttempt(userKey, ipKey, now); err != nil {
+		return nil, err
+	}
+	release := func() {
+		s.limiter.Release(userKey)
+		s.limiter.Release(ipKey)
+	}
+
+	user, err := s.users.GetByUsername(req.Username)
+	if err != nil && !isNotFound(err) {
+		release()
+		return nil, err
+	}
+
+	if !checkCredentials(user, req) {
+		return nil, &models.UnauthorizedError{Message: "username atau password/PIN salah"}
+	}
+
+	if !user.Active {
+		release()
+		return nil, &models.UnauthorizedError{Message: "akun tidak aktif"}
+	}
+
+	s.limiter.Reset(userKey)
+	s.limiter.Release(ipKey)
+
+	if err := s.loadPermissions(user); err != nil {
+		return nil, err
+	}
+
+	session := &models.Session{UserID: user.ID}
+	tokens, err := s.issueTokens(session, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.Create(session); err != nil {
+		return nil, err
+	}
+
+	tokens.User = *user
+	return tokens, nil
+}
+
+// reserveLoginAttempt - catat percobaan untuk username dan IP, tolak jika salah satunya sudah
+// mencapai limit
+func (s *AuthService) reserveLoginAttempt(userKey, ipKey string, now time.Time) error {
+	retryAfter := s.limiter.Reserve(userKey, s.config.MaxLoginAttempts, now)
+	if retryAfter == 0 {
+		retryAfter = s.limiter.Reserve(ipKey, s.config.MaxLoginAttempts*ipAttemptMultiplier, now)
+		if retryAfter > 0 {
+			s.limiter.Release(userKey)
+		}
+	}
+	if retryAfter > 0 {
+		return &models.TooManyRequestsError{
+			Message:    fmt.Sprintf("terlalu banyak percobaan login, coba lagi dalam %d detik", int(retryAfter.Seconds())+1),
+			RetryAfter: retryAfter,
+		}
+	}
+	return nil
+}
+
+// checkCredentials - bandingkan password/PIN dengan hash. Jika user tidak ada atau tidak punya
+// PIN/password, bcrypt tetap dijalankan dengan dummyHash supaya waktu respon tidak membocorkannya.
+func checkCredentials(user *models.User, req models.LoginRequest) bool {
+	secret, hash := req.Password, ""
+	if user != nil {
+		hash = user.PasswordHash
+	}
+	if req.PIN != "" {
+		secret = req.PIN
+		if user != nil {
+			hash = user.PINHash
+		}
+	}
+
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(secret))
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(secret)) == nil
+}
+
+// Authenticate - cek access token dari header Authorization
+func (s *AuthService) Authenticate(accessToken string) (*models.User, *models.Session, error) {
+	if accessToken == "" {
+		return nil, nil, &models.UnauthorizedError{Message: "token tidak ada"}
+	}
+
+	session, err := s.sessions.GetByTokenHash(hashToken(accessToken))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !s.now().Before(session.ExpiresAt) {
+		return nil, nil, &models.UnauthorizedError{Message: "token kedaluwarsa"}
+	}
+
+	user, err := s.users.GetByID(session.UserID)
+	if isNotFound(err) || (err == nil && !user.Active) {
+		return nil, nil, &models.UnauthorizedError{Message: "akun tidak aktif"}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.loadPermissions(user); err != nil {
+		return nil, nil, err
+	}
+
+	return user, session, nil
+}
+
+// Refresh - tukar refresh token dengan pasangan token baru. Refresh token lama tidak berlaku lagi.
+func (s *AuthService) Refresh(req models.RefreshRequest) (*models.AuthTokens, error) {
+	if req.RefreshToken == "" {
+		return nil, models.NewValidationError("refresh_token", "refresh_token wajib diisi")
+	}
+
+	oldHash := hashToken(req.RefreshToken)
+	session, err := s.sessions.GetByRefreshTokenHash(oldHash)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if !now.Before(session.RefreshExpiresAt) {
+		return nil, &models.UnauthorizedError{Message: "refresh token kedaluwarsa, silakan login ulang"}
+	}
+
+	user, err := s.users.GetByID(session.UserID)
+	if isNotFound(err) || (err == nil && !user.Active) {
+		return nil, &models.UnauthorizedError{Message: "akun tidak aktif"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadPermissions(user); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.issueTokens(session, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.Rotate(session, oldHash); err != nil {
+		return nil, err
+	}
+
+	tokens.User = *user
+	return tokens, nil
+}
+
+func (s *AuthService) Logout(session *models.Session) error {
+	return s.sessions.Revoke(session.ID, s.now())
+}
+
+// loadPermissions - isi user.EffectivePermissions dari role dan izin tambahan user
+func (s *AuthService) loadPermissions(user *models.User) error {
+	role, err := s.roles.GetByName(user.Role)
+	if err != nil {
+		return err
+	}
+
+	user.EffectivePermissions = effectivePermissions(role, user)
+	return nil
+}
+
+// issueTokens - buat access + refresh token baru dan simpan hash-nya di session
+func (s *AuthService) issueTokens(session *models.Session, now time.Time) (*models.AuthTokens, error) {
+	accessToken, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session.TokenHash = hashToken(accessToken)
+	session.RefreshTokenHash = hashToken(refreshToken)
+	session.ExpiresAt = now.Add(s.config.AccessTokenTTL)
+	session.RefreshExpiresAt = now.Add(s.config.RefreshTokenTTL)
+
+	return &models.AuthTokens{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        session.ExpiresAt,
+		RefreshExpiresAt: session.RefreshExpiresAt,
+	}, nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken - token acak 256 bit cukup di-hash SHA-256 (tidak perlu bcrypt)
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// authEnv - user service + auth service di atas store in-memory dengan satu kasir (PIN 1234)
+// dan satu owner (password "rahasia123"). Waktu auth service bisa dimajukan lewat env.now.
+type authEnv struct {
+	users   *UserService
+	roles   *RoleService
+	auth    *AuthService
+	now     time.Time
+	cashier *models.User
+	owner   *models.User
+}
+
+func newAuthEnv(t *testing.T) *authEnv {
+	t.Helper()
+
+	cost := bcryptCost
+	bcryptCost = bcrypt.MinCost
+	t.Cleanup(func() { bcryptCost = cost })
+
+	store := repositories.NewMemoryStore()
+	userRepo := repositories.NewMemoryUserRepository(store)
+	sessionRepo := repositories.NewMemorySessionRepository(store)
+	roleRepo := repositories.NewMemoryRoleRepository(store)
+
+	env := &authEnv{
+		users: NewUserService(userRepo, sessionRepo),
+		roles: NewRoleService(roleRepo),
+		auth: NewAuthService(userRepo, sessionRepo, roleRepo, models.AuthConfig{
+			AccessTokenTTL:   time.Hour,
+			RefreshTokenTTL:  24 * time.Hour,
+			MaxLoginAttempts: 3,
+			LoginWindow:      15 * time.Minute,
+		}),
+		now: time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC),
+	}
+	env.auth.now = func() time.Time { return env.now }
+
+	var err error
+	env.cashier, err = env.users.Create(models.UserInput{Username: "kasir1", Name: "Kasir Satu", Role: models.RoleCashier, PIN: "1234"})
+	if err != nil {
+		t.Fatalf("create cashier: %v", err)
+	}
+	env.owner, err = env.users.Create(models.UserInput{Username: "owner", Name: "Pemilik", Role: models.RoleOwner, Password: "rahasia123"})
+	if err != nil {
+		t.Fatalf("create owner: %v", err)
+	}
+
+	return env
+}
+
+func TestUserServiceCreateValidation(t *testing.T) {
+	env := newAuthEnv(t)
+
+	tests := []struct {
+		name  string
+		input models.UserInput
+		want  error
+	}{
+		{name: "kasir dengan PIN", input: models.UserInput{Username: "kasir2", Name: "Kasir Dua", Role: models.RoleCashier, PIN: "987654"}},
+		{name: "username terlalu pendek", input: models.UserInput{Username: "ab", Name: "A", Role: models.RoleCashier, PIN: "1234"}, want: models.ErrValidation},
+		{name: "role tidak dikenal", input: models.UserInput{Username: "budi", Name: "Budi", Role: "admin", PIN: "1234"}, want: models.ErrValidation},
+		{name: "izin tidak dikenal", input: models.UserInput{Username: "budi", Name: "Budi", Role: models.RoleCashier, PIN: "1234", Permissions: []string{"products:fly"}}, want: models.ErrValidation},
+		{name: "PIN bukan angka", input: models.UserInput{Username: "budi", Name: "Budi", Role: models.RoleCashier, PIN: "12ab"}, want: models.ErrValidation},
+		{name: "password terlalu pendek", input: models.UserInput{Username: "budi", Name: "Budi", Role: models.RoleOwner, Password: "1234567"}, want: models.ErrValidation},
+		{name: "supervisor tanpa password", input: models.UserInput{Username: "spv", Name: "Spv", Role: models.RoleSupervisor, PIN: "1234"}, want: models.ErrValidation},
+		{name: "kasir tanpa password dan PIN", input: models.UserInput{Username: "budi", Name: "Budi", Role: models.RoleCashier}, want: models.ErrValidation},
+		{name: "username sudah dipakai", input: models.UserInput{Username: "KASIR1", Name: "Lain", Role: models.RoleCashier, PIN: "1234"}, want: models.ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := env.users.Create(tt.input)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && (!user.Active || !user.HasPIN) {
+				t.Errorf("Create() = %+v, want active user with PIN", user)
+			}
+		})
+	}
+}
+
+func TestAuthServiceLogin(t *testing.T) {
+	env := newAuthEnv(t)
+
+	tests := []struct {
+		name string
+		req  models.LoginRequest
+		want error
+	}{
+		{name: "kasir dengan PIN", req: models.LoginRequest{Username: "kasir1", PIN: "1234"}},
+		{name: "owner dengan password, username beda huruf besar", req: models.LoginRequest{Username: "Owner", Password: "rahasia123"}},
+		{name: "PIN salah", req: models.LoginRequest{Username: "kasir1", PIN: "0000"}, want: models.ErrUnauthorized},
+		{name: "owner tidak punya PIN", req: models.LoginRequest{Username: "owner", PIN: "1234"}, want: models.ErrUnauthorized},
+		{name: "user tidak ada", req: models.LoginRequest{Username: "siapa", Password: "rahasia123"}, want: models.ErrUnauthorized},
+		{name: "password dan PIN diisi dua-duanya", req: models.LoginRequest{Username: "owner", Password: "rahasia123", PIN: "1234"}, want: models.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := env.auth.Login(tt.req, "10.0.0.1")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Login() error = %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+
+			user, _, err := env.auth.Authenticate(tokens.AccessToken)
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if user.ID != tokens.User.ID {
+				t.Errorf("Authenticate() user = %d, want %d", user.ID, tokens.User.ID)
+			}
+		})
+	}
+}
+
+func TestAuthServiceRateLimit(t *testing.T) {
+	env := newAuthEnv(t)
+	wrong := models.LoginRequest{Username: "kasir1", PIN: "0000"}
+
+	for i := 0; i < 3; i++ {
+		if _, err := env.auth.Login(wrong, "10.0.0.1"); !errors.Is(err, models.ErrUnauthorized) {
+			t.Fatalf("attempt %d: error = %v, want unauthorized", i+1, err)
+		}
+	}
+
+	// PIN benar pun ditolak selama diblokir
+	_, err := env.auth.Login(models.LoginRequest{Username: "kasir1", PIN: "1234"}, "10.0.0.2")
+	var rateErr *models.TooManyRequestsError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("Login() error = %v, want TooManyRequestsError", err)
+	}
+	if rateErr.RetryAfter != 15*time.Minute {
+		t.Errorf("RetryAfter = %v, want 15m", rateErr.RetryAfter)
+	}
+
+	// username lain dari IP yang sama belum kena limit IP
+	if _, err := env.auth.Login(models.LoginRequest{Username: "owner", Password: "rahasia123"}, "10.0.0.1"); err != nil {
+		t.Fatalf("owner login: %v", err)
+	}
+
+	env.now = env.now.Add(15 * time.Minute)
+	if _, err := env.auth.Login(models.LoginRequest{Username: "kasir1", PIN: "1234"}, "10.0.0.1"); err != nil {
+		t.Fatalf("login after window: %v", err)
+	}
+}
+
+func TestAuthServiceRateLimitConcurrent(t *testing.T) {
+	env := newAuthEnv(t)
+	wrong := models.LoginRequest{Username: "kasir1", PIN: "0000"}
+
+	// tebakan paralel tidak boleh lolos lebih dari MaxLoginAttempts sebelum kegagalan tercatat
+	const attempts = 20
+	errs := make(chan error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := env.auth.Login(wrong, "10.0.0.1")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	checked, limited := 0, 0
+	for err := range errs {
+		var rateErr *models.TooManyRequestsError
+		switch {
+		case errors.Is(err, models.ErrUnauthorized):
+			checked++
+		case errors.As(err, &rateErr):
+			limited++
+		default:
+			t.Errorf("Login() error = %v", err)
+		}
+	}
+	if checked != 3 || limited != attempts-3 {
+		t.Errorf("PIN dicek %d kali, ditolak limit %d kali, want 3 dan %d", checked, limited, attempts-3)
+	}
+}
+
+func TestAuthServiceSessions(t *testing.T) {
+	env := newAuthEnv(t)
+
+	login := func() *models.AuthTokens {
+		t.Helper()
+		tokens, err := env.auth.Login(models.LoginRequest{Username: "kasir1", PIN: "1234"}, "10.0.0.1")
+		if err != nil {
+			t.Fatalf("Login() error = %v", err)
+		}
+		return tokens
+	}
+
+	t.Run("refresh merotasi token", func(t *testing.T) {
+		old := login()
+		tokens, err := env.auth.Refresh(models.RefreshRequest{RefreshToken: old.RefreshToken})
+		if err != nil {
+			t.Fatalf("Refresh() error = %v", err)
+		}
+		if _, _, err := env.auth.Authenticate(tokens.AccessToken); err != nil {
+			t.Errorf("Authenticate(new) error = %v", err)
+		}
+		if _, _, err := env.auth.Authenticate(old.AccessToken); !errors.Is(err, models.ErrUnauthorized) {
+			t.Errorf("Authenticate(old) error = %v, want unauthorized", err)
+		}
+		if _, err := env.auth.Refresh(models.RefreshRequest{RefreshToken: old.RefreshToken}); !errors.Is(err, models.ErrUnauthorized) {
+			t.Errorf("Refresh(old) error = %v, want unauthorized", err)
+		}
+	})
+
+	t.Run("access token kedaluwarsa", func(t *testing.T) {
+		tokens := login()
+		env.now = env.now.Add(time.Hour)
+		defer func() { env.now = env.now.Add(-time.Hour) }()
+
+		if _, _, err := env.auth.Authenticate(tokens.AccessToken); !errors.Is(err, models.ErrUnauthorized) {
+			t.Fatalf("Authenticate() error = %v, want unauthorized", err)
+		}
+		if _, err := env.auth.Refresh(models.RefreshRequest{RefreshToken: tokens.RefreshToken}); err != nil {
+			t.Fatalf("Refresh() error = %v", err)
+		}
+	})
+
+	t.Run("logout", func(t *testing.T) {
+		tokens := login()
+		_, session, err := env.auth.Authenticate(tokens.AccessToken)
+		if err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+		if err := env.auth.Logout(session); err != nil {
+			t.Fatalf("Logout() error = %v", err)
+		}
+		if _, _, err := env.auth.Authenticate(tokens.AccessToken); !errors.Is(err, models.ErrUnauthorized) {
+			t.Errorf("Authenticate() after logout error = %v, want unauthorized", err)
+		}
+	})
+
+	t.Run("user dinonaktifkan", func(t *testing.T) {
+		tokens := login()
+		inactive := false
+		_, err := env.users.Update(env.cashier.ID, models.UserInput{
+			Username: "kasir1", Name: "Kasir Satu", Role: models.RoleCashier, Active: &inactive,
+		})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		if _, _, err := env.auth.Authenticate(tokens.AccessToken); !errors.Is(err, models.ErrUnauthorized) {
+			t.Errorf("Authenticate() error = %v, want unauthorized", err)
+		}
+		if _, err := env.auth.Login(models.LoginRequest{Username: "kasir1", PIN: "1234"}, "10.0.0.1"); !errors.Is(err, models.ErrUnauthorized) {
+			t.Errorf("Login() error = %v, want unauthorized", err)
+		}
+	})
+}
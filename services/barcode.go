@@ -0,0 +1,190 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxProductCodeLength - panjang maksimal SKU / barcode, sama dengan kolom VARCHAR(50)
+const maxProductCodeLength = 50
+
+// normalizeBarcode - barcode hasil scan / input manual tanpa spasi di ujung
+func normalizeBarcode(barcode string) string {
+	return strings.TrimSpace(barcode)
+}
+
+// normalizeSKU - SKU tidak membedakan huruf besar kecil, disimpan uppercase
+func normalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+// validProductCode - SKU / barcode tidak boleh kosong, berisi spasi, atau lebih dari 50 karakter
+func validProductCode(code string) bool {
+	return code != "" && len(code) <= maxProductCodeLength && !strings.ContainsFunc(code, unicode.IsSpace)
+}
+
+// validGTIN - barcode numerik sepanjang EAN-8, UPC-A, EAN-13 atau GTIN-14 harus lolos cek
+// digit terakhir. Barcode lain (Code 128 internal, dst.) tidak dicek.
+func validGTIN(barcode string) bool {
+	switch len(barcode) {
+	case 8, 12, 13, 14:
+	default:
+		return true
+	}
+	for _, c := range barcode {
+		if c < '0' || c > '9' {
+			return true
+		}
+	}
+	return gtinCheckDigit(barcode[:len(barcode)-1]) == barcode[len(barcode)-1]
+}
+
+// gtinCheckDigit - digit cek GS1 (modulo 10): dari kanan, digit berbobot 3 dan 1 bergantian
+func gtinCheckDigit(digits string) byte {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// internalBarcodePrefix - prefix GS1 20-29 dicadangkan untuk barcode internal toko (restricted
+// circulation), tidak akan bentrok dengan barcode pabrik
+const internalBarcodePrefix = "200"
+
+// maxInternalBarcodeID - id produk terbesar yang muat di 9 digit barcode internal
+const maxInternalBarcodeID = 999_999_999
+
+// internalEAN13 - barcode EAN-13 internal dari id produk: 200 + id 9 digit + digit cek
+func internalEAN13(productID int) (string, error) {
+	if productID < 1 || productID > maxInternalBarcodeID {
+		return "", &models.ConflictError{Message: fmt.Sprintf("id produk %d tidak muat di barcode internal (maksimal %d)", productID, maxInternalBarcodeID)}
+	}
+	code := fmt.Sprintf("%s%09d", internalBarcodePrefix, productID)
+	return code + string(gtinCheckDigit(code)), nil
+}
+
+// isEAN13 - 13 digit dengan digit cek yang benar
+func isEAN13(code string) bool {
+	if len(code) != 13 {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return validGTIN(code)
+}
+
+// barcodeModules - pola modul (true = bar) termasuk quiet zone. EAN-13 yang valid dicetak
+// sebagai EAN-13, kode lain sebagai Code 128 set B.
+func barcodeModules(code string) ([]bool, error) {
+	if isEAN13(code) {
+		return ean13Modules(code), nil
+	}
+	return code128Modules(code)
+}
+
+var (
+	// ean13LCodes - pola digit sisi kiri paritas ganjil, R = kebalikan L, G = R dibalik urutannya
+	ean13LCodes = [10]string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	// ean13Parity - paritas 6 digit kiri ditentukan digit pertama, yang tidak ikut dicetak sebagai bar
+	ean13Parity = [10]string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL"}
+)
+
+func ean13Modules(code string) []bool {
+	var b strings.Builder
+	b.WriteString(strings.Repeat("0", 11)) // quiet zone kiri
+	b.WriteString("101")
+	parity := ean13Parity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		pattern := ean13LCodes[code[i]-'0']
+		if parity[i-1] == 'G' {
+			pattern = reverseString(invertModules(pattern))
+		}
+		b.WriteString(pattern)
+	}
+	b.WriteString("01010")
+	for i := 7; i <= 12; i++ {
+		b.WriteString(invertModules(ean13LCodes[code[i]-'0']))
+	}
+	b.WriteString("101")
+	b.WriteString(strings.Repeat("0", 7)) // quiet zone kanan
+	return moduleBits(b.String())
+}
+
+// code128Patterns - lebar bar / spasi bergantian untuk nilai 0-106 (103-105 start A/B/C, 106 stop)
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB = 104
+	code128Stop   = 106
+)
+
+func code128Modules(text string) ([]bool, error) {
+	values := []int{code128StartB}
+	checksum := code128StartB
+	for i, c := range text {
+		if c < 32 || c > 126 {
+			return nil, models.NewValidationError("barcode", "barcode hanya boleh berisi karakter ASCII")
+		}
+		values = append(values, int(c)-32)
+		checksum += (int(c) - 32) * (i + 1)
+	}
+	values = append(values, checksum%103, code128Stop)
+
+	modules := make([]bool, 10) // quiet zone kiri
+	for _, v := range values {
+		bar := true
+		for _, width := range code128Patterns[v] {
+			for range width - '0' {
+				modules = append(modules, bar)
+			}
+			bar = !bar
+		}
+	}
+	return append(modules, make([]bool, 10)...), nil
+}
+
+func moduleBits(pattern string) []bool {
+	modules := make([]bool, len(pattern))
+	for i, c := range pattern {
+		modules[i] = c == '1'
+	}
+	return modules
+}
+
+func invertModules(pattern string) string {
+	return strings.Map(func(c rune) rune {
+		if c == '1' {
+			return '0'
+		}
+		return '1'
+	}, pattern)
+}
+
+func reverseString(s string) string {
+	b := []byte(s)
+	slices.Reverse(b)
+	return string(b)
+}
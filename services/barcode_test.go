@@ -0,0 +1,109 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func modulesString(modules []bool) string {
+	var b strings.Builder
+	for _, m := range modules {
+		if m {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+	return b.String()
+}
+
+func TestEAN13Modules(t *testing.T) {
+	got := modulesString(ean13Modules("4006381333931"))
+
+	if len(got) != 11+95+7 {
+		t.Fatalf("len = %d, want %d", len(got), 11+95+7)
+	}
+	checks := []struct {
+		name  string
+		start int
+		want  string
+	}{
+		{name: "quiet zone + guard kiri", start: 0, want: "00000000000101"},
+		{name: "digit 0 paritas L", start: 14, want: "0001101"},
+		{name: "digit 0 paritas G", start: 21, want: "0100111"},
+		{name: "guard tengah", start: 56, want: "01010"},
+		{name: "digit 3 sisi kanan", start: 61, want: "1000010"},
+		{name: "guard kanan + quiet zone", start: 103, want: "1010000000"},
+	}
+	for _, c := range checks {
+		if part := got[c.start : c.start+len(c.want)]; part != c.want {
+			t.Errorf("%s = %s, want %s", c.name, part, c.want)
+		}
+	}
+}
+
+func TestCode128Modules(t *testing.T) {
+	for v, pattern := range code128Patterns {
+		want := 11
+		if v == code128Stop {
+			want = 13
+		}
+		sum := 0
+		for _, c := range pattern {
+			sum += int(c - '0')
+		}
+		if sum != want {
+			t.Errorf("pola %d %s = %d modul, want %d", v, pattern, sum, want)
+		}
+	}
+
+	// start B, "A" (33), checksum (104 + 33) % 103 = 34, stop
+	modules, err := code128Modules("A")
+	if err != nil {
+		t.Fatalf("code128Modules: %v", err)
+	}
+	want := strings.Repeat("0", 10) + "11010010000" + "10100011000" + "10001011000" + "1100011101011" + strings.Repeat("0", 10)
+	if got := modulesString(modules); got != want {
+		t.Errorf("modules =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := code128Modules("ROTI-é"); err == nil {
+		t.Error("karakter non-ASCII: expected error")
+	}
+}
+
+func TestInternalEAN13(t *testing.T) {
+	tests := []struct {
+		id      int
+		want    string
+		wantErr bool
+	}{
+		{id: 42, want: "2000000000428"},
+		{id: maxInternalBarcodeID, want: "2009999999997"},
+		{id: maxInternalBarcodeID + 1, wantErr: true},
+		{id: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		code, err := internalEAN13(tt.id)
+		if tt.wantErr {
+			if !errors.Is(err, models.ErrConflict) {
+				t.Errorf("internalEAN13(%d) = %q, %v, want %v", tt.id, code, err, models.ErrConflict)
+			}
+			continue
+		}
+		if err != nil || code != tt.want || !isEAN13(code) {
+			t.Errorf("internalEAN13(%d) = %q, %v, want %s", tt.id, code, err, tt.want)
+		}
+	}
+}
+
+func mustInternalEAN13(t *testing.T, productID int) string {
+	t.Helper()
+	code, err := internalEAN13(productID)
+	if err != nil {
+		t.Fatalf("internalEAN13(%d): %v", productID, err)
+	}
+	return code
+}
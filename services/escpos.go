@@ -0,0 +1,113 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"bytes"
+	"net"
+	"time"
+)
+
+// Perintah ESC/POS yang didukung printer thermal 58/80 mm pada umumnya (Epson TM, Xprinter, dst.)
+var (
+	escposInit       = []byte{0x1B, 0x40}                   // ESC @
+	escposAlignLeft  = []byte{0x1B, 0x61, 0x00}             // ESC a 0
+	escposAlignMid   = []byte{0x1B, 0x61, 0x01}             // ESC a 1
+	escposBoldOn     = []byte{0x1B, 0x45, 0x01}             // ESC E 1
+	escposBoldOff    = []byte{0x1B, 0x45, 0x00}             // ESC E 0
+	escposDrawerKick = []byte{0x1B, 0x70, 0x00, 0x19, 0xFA} // ESC p, pin 2, nyala 50 ms, mati 500 ms
+	escposFeedCut    = []byte{0x1D, 0x56, 0x42, 0x03}       // GS V 66 n: feed lalu partial cut
+)
+
+// renderReceiptESCPOS - byte stream ESC/POS dengan layout yang sama dengan struk teks, ditambah
+// QR code nomor struk. openDrawer menambahkan pulsa buka laci kas di awal.
+func renderReceiptESCPOS(r *models.Receipt, width int, openDrawer bool) []byte {
+	var buf bytes.Buffer
+	buf.Write(escposInit)
+	if openDrawer {
+		buf.Write(escposDrawerKick)
+	}
+
+	buf.Write(escposAlignLeft)
+	bold := false
+	for _, line := range receiptTextLines(r, width) {
+		if line.bold != bold {
+			bold = line.bold
+			if bold {
+				buf.Write(escposBoldOn)
+			} else {
+				buf.Write(escposBoldOff)
+			}
+		}
+		buf.WriteString(asciiText(line.text))
+		buf.WriteByte('\n')
+	}
+	if bold {
+		buf.Write(escposBoldOff)
+	}
+
+	if r.ReceiptNumber != "" {
+		moduleSize := byte(6)
+		if width == models.ReceiptWidth58 {
+			moduleSize = 4
+		}
+		buf.WriteByte('\n')
+		buf.Write(escposAlignMid)
+		writeESCPOSQRCode(&buf, r.ReceiptNumber, moduleSize)
+		buf.Write(escposAlignLeft)
+	}
+
+	buf.Write(escposFeedCut)
+	return buf.Bytes()
+}
+
+// writeESCPOSQRCode - GS ( k: model 2, ukuran modul, koreksi error M, simpan data lalu cetak
+func writeESCPOSQRCode(buf *bytes.Buffer, data string, moduleSize byte) {
+	stored := len(data) + 3
+	buf.Write([]byte{0x1D, 0x28, 0x6B, 0x04, 0x00, 0x31, 0x41, 0x32, 0x00})
+	buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x43, moduleSize})
+	buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x45, 0x31})
+	buf.Write([]byte{0x1D, 0x28, 0x6B, byte(stored % 256), byte(stored / 256), 0x31, 0x50, 0x30})
+	buf.WriteString(asciiText(data))
+	buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x51, 0x30})
+}
+
+// asciiText - karakter di luar ASCII cetak diganti "?" karena code page printer berbeda-beda
+func asciiText(text string) string {
+	b := []byte(nil)
+	for _, c := range text {
+		if c < 32 || c > 126 {
+			c = '?'
+		}
+		b = append(b, byte(c))
+	}
+	return string(b)
+}
+
+// ReceiptPrinter - tujuan kirim byte ESC/POS, address berasal dari printer_address terminal
+type ReceiptPrinter interface {
+	Print(address string, data []byte) error
+}
+
+// NetworkPrinter - printer thermal dengan port raw TCP (umumnya 9100)
+type NetworkPrinter struct {
+	Timeout time.Duration // batas waktu connect dan kirim
+}
+
+func (p NetworkPrinter) Print(address string, data []byte) error {
+	conn, err := net.DialTimeout("tcp", address, p.Timeout)
+	if err != nil {
+		return &models.PrinterError{Address: address, Err: err}
+	}
+
+	conn.SetDeadline(time.Now().Add(p.Timeout))
+	_, err = conn.Write(data)
+	if closeErr := conn.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return &models.PrinterError{Address: address, Err: err}
+	}
+	return nil
+}
+
+var errNoPrinter = &models.ConflictError{Message: "terminal transaksi belum diatur printer-nya"}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+)
+
+// Ukuran label dalam mm. Label tunggal (SVG / PNG) pas untuk printer label thermal 50 x 30,
+// lembar A4 memakai kertas label 3 x 8 (70 x 37 mm) tanpa margin halaman.
+const (
+	labelWidthMM  = 50.0
+	labelHeightMM = 30.0
+
+	sheetWidthMM    = 210.0
+	sheetHeightMM   = 297.0
+	sheetColumns    = 3
+	sheetRows       = 8
+	sheetCellWidth  = sheetWidthMM / sheetColumns
+	sheetCellHeight = 37.0
+
+	// labelDotsPerMM - resolusi PNG (203 dpi, printer label thermal). Lebar modul barcode
+	// dibulatkan ke kelipatan dot supaya bar tetap tajam dan terbaca scanner.
+	labelDotsPerMM = 8
+)
+
+// labelRect / labelText - elemen gambar label dalam mm dari kiri atas
+type labelRect struct {
+	x, y, width, height float64
+}
+
+type labelText struct {
+	text     string
+	x, y     float64 // x tengah teks, y baseline
+	size     float64 // ukuran font
+	maxWidth float64 // teks dipotong jika lebih lebar
+}
+
+type labelDrawing struct {
+	width, height float64
+	rects         []labelRect
+	texts         []labelText
+}
+
+// productLabelCode - barcode pertama produk, SKU jika belum punya barcode
+func productLabelCode(p *models.Product) (string, error) {
+	if len(p.Barcodes) > 0 {
+		return p.Barcodes[0], nil
+	}
+	if p.SKU != "" {
+		return p.SKU, nil
+	}
+	return "", &models.ConflictError{Message: fmt.Sprintf("produk %s belum punya barcode atau SKU", p.Name)}
+}
+
+// layoutLabel - nama produk di atas, barcode di tengah, kode dan harga di bawah
+func layoutLabel(p *models.Product, width, height float64) (*labelDrawing, error) {
+	code, err := productLabelCode(p)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := barcodeModules(code)
+	if err != nil {
+		return nil, err
+	}
+
+	const margin = 2.0
+	const nameSize, codeSize, priceSize = 3.0, 2.5, 3.5
+	d := &labelDrawing{width: width, height: height}
+	center := width / 2
+	textWidth := width - 2*margin
+
+	priceY := height - margin
+	codeY := priceY - priceSize - 1
+	d.texts = append(d.texts,
+		labelText{text: p.Name, x: center, y: margin + nameSize, size: nameSize, maxWidth: textWidth},
+		labelText{text: code, x: center, y: codeY, size: codeSize, maxWidth: textWidth},
+		labelText{text: "Rp " + formatRupiah(p.Price), x: center, y: priceY, size: priceSize, maxWidth: textWidth},
+	)
+
+	module := math.Floor(textWidth*labelDotsPerMM/float64(len(modules))) / labelDotsPerMM
+	if module == 0 {
+		return nil, models.NewValidationError("barcode", "barcode terlalu panjang untuk ukuran label")
+	}
+	module = min(module, 0.5)
+	barTop := margin + nameSize + 1
+	barHeight := codeY - codeSize - 0.5 - barTop
+	x := center - module*float64(len(modules))/2
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		start := i
+		for i < len(modules) && modules[i] {
+			i++
+		}
+		d.rects = append(d.rects, labelRect{x: x + module*float64(start), y: barTop, width: module * float64(i-start), height: barHeight})
+	}
+
+	return d, nil
+}
+
+// fitText - potong teks supaya muat maxWidth dengan lebar huruf charWidth
+func fitText(text string, maxWidth, charWidth float64) string {
+	return truncateText(text, int(maxWidth/charWidth))
+}
+
+// courierCharWidth - lebar satu huruf font monospace (Courier) relatif terhadap ukuran font
+const courierCharWidth = 0.6
+
+func renderLabelSVG(d *labelDrawing) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%gmm" height="%gmm" viewBox="0 0 %g %g">`+"\n", d.width, d.height, d.width, d.height)
+	fmt.Fprintf(&b, `<rect width="%g" height="%g" fill="#fff"/>`+"\n", d.width, d.height)
+	for _, r := range d.rects {
+		fmt.Fprintf(&b, `<rect x="%.3f" y="%.3f" width="%.3f" height="%.3f"/>`+"\n", r.x, r.y, r.width, r.height)
+	}
+	for _, t := range d.texts {
+		text := fitText(t.text, t.maxWidth, t.size*courierCharWidth)
+		fmt.Fprintf(&b, `<text x="%.3f" y="%.3f" font-family="monospace" font-size="%g" text-anchor="middle">%s</text>`+"\n", t.x, t.y, t.size, html.EscapeString(text))
+	}
+	b.WriteString("</svg>\n")
+	return b.Bytes()
+}
+
+func renderLabelPNG(d *labelDrawing) ([]byte, error) {
+	dots := func(mm float64) int { return int(math.Round(mm * labelDotsPerMM)) }
+
+	img := image.NewGray(image.Rect(0, 0, dots(d.width), dots(d.height)))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	fill := func(x0, y0, x1, y1 int) {
+		for y := max(y0, 0); y < min(y1, img.Rect.Max.Y); y++ {
+			for x := max(x0, 0); x < min(x1, img.Rect.Max.X); x++ {
+				img.SetGray(x, y, color.Gray{})
+			}
+		}
+	}
+
+	for _, r := range d.rects {
+		fill(dots(r.x), dots(r.y), dots(r.x+r.width), dots(r.y+r.height))
+	}
+
+	for _, t := range d.texts {
+		scale := max(dots(t.size)/labelFontHeight, 1)
+		charWidth := (labelFontWidth + 1) * scale
+		text := fitText(strings.ToUpper(t.text), float64(dots(t.maxWidth)), float64(charWidth))
+		x := dots(t.x) - (len([]rune(text))*charWidth-scale)/2
+		top := dots(t.y) - labelFontHeight*scale
+		for _, c := range text {
+			glyph := labelGlyph(c)
+			for row, line := range glyph {
+				for col, pixel := range line {
+					if pixel == '#' {
+						fill(x+col*scale, top+row*scale, x+(col+1)*scale, top+(row+1)*scale)
+					}
+				}
+			}
+			x += charWidth
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderLabelSheetPDF - label berurutan mengisi kertas A4 3 x 8 dari kiri atas, halaman baru
+// setiap 24 label
+func renderLabelSheetPDF(labels []*labelDrawing) []byte {
+	pageWidth, pageHeight := sheetWidthMM*pointsPerMM, sheetHeightMM*pointsPerMM
+	perPage := sheetColumns * sheetRows
+
+	doc := newPDFDocument()
+	for start := 0; start < len(labels); start += perPage {
+		var content strings.Builder
+		for i, d := range labels[start:min(start+perPage, len(labels))] {
+			left := float64(i%sheetColumns) * sheetCellWidth
+			top := float64(i/sheetColumns) * sheetCellHeight
+			// koordinat PDF dari kiri bawah, satuan point
+			x := func(mm float64) float64 { return (left + mm) * pointsPerMM }
+			y := func(mm float64) float64 { return pageHeight - (top+mm)*pointsPerMM }
+
+			for _, r := range d.rects {
+				content.WriteString(pdfRect(x(r.x), y(r.y+r.height), r.width*pointsPerMM, r.height*pointsPerMM))
+			}
+			for _, t := range d.texts {
+				size := t.size * pointsPerMM
+				text := fitText(t.text, t.maxWidth, t.size*courierCharWidth)
+				textWidth := float64(len([]rune(text))) * size * courierCharWidth
+				content.WriteString(pdfText(x(t.x)-textWidth/2, y(t.y), size, text))
+			}
+		}
+		doc.addPage(pageWidth, pageHeight, content.String())
+	}
+	return doc.bytes()
+}
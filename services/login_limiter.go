@@ -0,0 +1,73 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// LoginLimiter - hitung login gagal per key (username atau IP) dalam satu window waktu.
+// Setelah limit tercapai key diblokir sampai window percobaan pertama berakhir, sehingga
+// PIN 4-6 digit tidak bisa ditebak dengan brute force. Disimpan di memori proses.
+type LoginLimiter struct {
+	mu       sync.Mutex
+	window   time.Duration
+	failures map[string]*loginFailures
+}
+
+const maxTrackedLoginKeys = 10000
+
+type loginFailures struct {
+	count int
+	since time.Time
+}
+
+func NewLoginLimiter(window time.Duration) *LoginLimiter {
+	return &LoginLimiter{window: window, failures: make(map[string]*loginFailures)}
+}
+
+// Reserve - catat satu percobaan login sebelum password/PIN dicek, dalam satu lock dengan
+// pengecekan limit supaya percobaan paralel tidak lolos bersamaan selama bcrypt berjalan.
+// Mengembalikan 0 jika percobaan boleh dilanjutkan, selain itu sisa waktu blokir (tidak dicatat).
+// Percobaan yang ternyata berhasil dibatalkan lewat Release atau Reset.
+func (l *LoginLimiter) Reserve(key string, limit int, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// buang entri kedaluwarsa supaya map tidak terus membesar
+	if len(l.failures) >= maxTrackedLoginKeys {
+		for k, f := range l.failures {
+			if now.Sub(f.since) >= l.window {
+				delete(l.failures, k)
+			}
+		}
+	}
+
+	f, ok := l.failures[key]
+	if !ok || now.Sub(f.since) >= l.window {
+		f = &loginFailures{since: now}
+		l.failures[key] = f
+	}
+
+	if f.count >= limit {
+		return f.since.Add(l.window).Sub(now)
+	}
+	f.count++
+	return 0
+}
+
+// Release - batalkan satu percobaan dari Reserve
+func (l *LoginLimiter) Release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if f, ok := l.failures[key]; ok && f.count > 0 {
+		f.count--
+	}
+}
+
+func (l *LoginLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, key)
+}
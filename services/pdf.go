@@ -0,0 +1,112 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Penulis PDF minimal (PDF 1.4, font standar Courier, tanpa kompresi) supaya tidak perlu
+// dependency tambahan hanya untuk struk dan label.
+
+const pointsPerMM = 72 / 25.4
+
+// pdfDocument - kumpulan object PDF, object 1 selalu catalog dan object 2 pages
+type pdfDocument struct {
+	objects []string
+	pages   []int
+}
+
+func newPDFDocument() *pdfDocument {
+	d := &pdfDocument{}
+	d.add("") // catalog, diisi saat bytes()
+	d.add("") // pages, diisi saat bytes()
+	d.add("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	return d
+}
+
+// pdfFontRef - nama font Courier di resource setiap halaman
+const pdfFontRef = "/F1"
+
+func (d *pdfDocument) add(object string) int {
+	d.objects = append(d.objects, object)
+	return len(d.objects)
+}
+
+// addPage - halaman berukuran width x height point dengan content stream apa adanya
+func (d *pdfDocument) addPage(width, height float64, content string) {
+	stream := d.add(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	page := d.add(fmt.Sprintf(
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << %s 3 0 R >> >> /Contents %d 0 R >>",
+		width, height, pdfFontRef, stream,
+	))
+	d.pages = append(d.pages, page)
+}
+
+func (d *pdfDocument) bytes() []byte {
+	kids := make([]string, len(d.pages))
+	for i, p := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", p)
+	}
+	d.objects[0] = "<< /Type /Catalog /Pages 2 0 R >>"
+	d.objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(d.objects))
+	for i, object := range d.objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.objects)+1, xref)
+	return buf.Bytes()
+}
+
+// pdfText - perintah content stream untuk menulis text di posisi (x, y) point dari kiri bawah
+func pdfText(x, y, size float64, text string) string {
+	return fmt.Sprintf("BT %s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", pdfFontRef, size, x, y, pdfEscape(text))
+}
+
+// pdfRect - persegi panjang terisi hitam, (x, y) sudut kiri bawah
+func pdfRect(x, y, width, height float64) string {
+	return fmt.Sprintf("%.2f %.2f %.2f %.2f re f\n", x, y, width, height)
+}
+
+// pdfEscape - string literal PDF, karakter di luar ASCII diganti "?" karena font standar
+// hanya memakai WinAnsiEncoding
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, c := range asciiText(text) {
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// textPDF - satu halaman selebar widthMM berisi baris teks monospace, ukuran font dipilih
+// supaya cols karakter pas selebar halaman. Tinggi halaman mengikuti jumlah baris (kertas roll).
+func textPDF(lines []string, widthMM float64, cols int) []byte {
+	const margin = 8.0
+	width := widthMM * pointsPerMM
+	size := (width - 2*margin) / (float64(cols) * 0.6) // lebar karakter Courier = 0.6 em
+	leading := size * 1.2
+	height := 2*margin + leading*float64(len(lines))
+
+	var content strings.Builder
+	for i, line := range lines {
+		y := height - margin - leading*float64(i+1) + (leading - size)
+		content.WriteString(pdfText(margin, y, size, line))
+	}
+
+	d := newPDFDocument()
+	d.addPage(width, height, content.String())
+	return d.bytes()
+}
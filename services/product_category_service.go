@@ -3,13 +3,14 @@ package services
 import (
 	"aplikasi-kasir/models"
 	"aplikasi-kasir/repositories"
+	"strings"
 )
 
 type ProductCategoryService struct {
-	repo *repositories.ProductCategoryRepository
+	repo repositories.ProductCategoryRepository
 }
 
-func NewProductCategoryService(repo *repositories.ProductCategoryRepository) *ProductCategoryService {
+func NewProductCategoryService(repo repositories.ProductCategoryRepository) *ProductCategoryService {
 	return &ProductCategoryService{repo: repo}
 }
 
@@ -18,6 +19,9 @@ func (s *ProductCategoryService) GetAll() ([]models.ProductCategory, error) {
 }
 
 func (s *ProductCategoryService) Create(data *models.ProductCategory) error {
+	if err := validateProductCategory(data); err != nil {
+		return err
+	}
 	return s.repo.Create(data)
 }
 
@@ -26,9 +30,29 @@ func (s *ProductCategoryService) GetByID(id int) (*models.ProductCategory, error
 }
 
 func (s *ProductCategoryService) Update(productCategory *models.ProductCategory) error {
+	if err := validateProductCategory(productCategory); err != nil {
+		return err
+	}
 	return s.repo.Update(productCategory)
 }
 
 func (s *ProductCategoryService) Delete(id int) error {
 	return s.repo.Delete(id)
 }
+
+func validateProductCategory(c *models.ProductCategory) error {
+	var errs []models.FieldError
+
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		errs = append(errs, models.FieldError{Field: "name", Message: "nama kategori wajib diisi"})
+	}
+	if c.TaxRate != nil && !validTaxRate(*c.TaxRate) {
+		errs = append(errs, models.FieldError{Field: "tax_rate", Message: "tarif pajak harus antara 0 dan 100"})
+	}
+
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
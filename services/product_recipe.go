@@ -0,0 +1,66 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// MaxRecipeItems - jumlah komponen maksimal dalam satu resep
+const MaxRecipeItems = 20
+
+// prepareRecipe - validasi resep produk komposit. Komponen harus produk yang punya stok sendiri:
+// bukan produk komposit lain (resep hanya satu tingkat) dan bukan induk varian. Stok produk
+// komposit selalu 0 karena yang dikurangi saat checkout adalah stok komponennya.
+func (s *ProductService) prepareRecipe(p *models.Product) error {
+	if len(p.Recipe) == 0 {
+		p.Recipe = []models.RecipeItem{}
+		return nil
+	}
+	if len(p.Recipe) > MaxRecipeItems {
+		return models.NewValidationError("recipe", fmt.Sprintf("maksimal %d komponen", MaxRecipeItems))
+	}
+
+	var errs []models.FieldError
+	var seen []int
+	for i, item := range p.Recipe {
+		field := fmt.Sprintf("recipe[%d]", i)
+		if item.Quantity <= 0 {
+			errs = append(errs, models.FieldError{Field: field + ".quantity", Message: "quantity harus lebih dari 0"})
+		}
+		if slices.Contains(seen, item.ComponentID) {
+			errs = append(errs, models.FieldError{Field: field + ".component_id", Message: "komponen tidak boleh dobel"})
+			continue
+		}
+		seen = append(seen, item.ComponentID)
+
+		if p.ID != 0 && item.ComponentID == p.ID {
+			errs = append(errs, models.FieldError{Field: field + ".component_id", Message: "produk tidak bisa menjadi komponen dirinya sendiri"})
+			continue
+		}
+		component, err := s.repo.GetByID(item.ComponentID)
+		if errors.Is(err, models.ErrNotFound) {
+			errs = append(errs, models.FieldError{Field: field + ".component_id", Message: "komponen tidak ditemukan"})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(component.Recipe) > 0:
+			errs = append(errs, models.FieldError{Field: field + ".component_id", Message: fmt.Sprintf("%s adalah produk komposit, tidak bisa menjadi komponen", component.Name)})
+		case len(component.Variants) > 0:
+			errs = append(errs, models.FieldError{Field: field + ".component_id", Message: fmt.Sprintf("%s punya varian, pilih salah satu varian", component.Name)})
+		}
+	}
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+
+	for i := range p.Recipe {
+		p.Recipe[i].ComponentName = ""
+	}
+	p.Stock = 0
+	return nil
+}
@@ -3,13 +3,19 @@ package services
 import (
 	"aplikasi-kasir/models"
 	"aplikasi-kasir/repositories"
+	"fmt"
+	"slices"
+	"strings"
 )
 
+// MaxSheetLabels - jumlah label maksimal per PDF (10 lembar A4)
+const MaxSheetLabels = 240
+
 type ProductService struct {
-	repo *repositories.ProductRepository
+	repo repositories.ProductRepository
 }
 
-func NewProductService(repo *repositories.ProductRepository) *ProductService {
+func NewProductService(repo repositories.ProductRepository) *ProductService {
 	return &ProductService{repo: repo}
 }
 
@@ -18,6 +24,15 @@ func (s *ProductService) GetAll(name string) ([]models.Product, error) {
 }
 
 func (s *ProductService) Create(data *models.Product) error {
+	if err := s.prepareOptions(data, nil); err != nil {
+		return err
+	}
+	if err := s.prepareRecipe(data); err != nil {
+		return err
+	}
+	if err := validateProduct(data); err != nil {
+		return err
+	}
 	return s.repo.Create(data)
 }
 
@@ -25,10 +40,204 @@ func (s *ProductService) GetByID(id int) (*models.Product, error) {
 	return s.repo.GetByID(id)
 }
 
+// Lookup - cari produk hasil scan barcode atau ketik SKU di kasir, salah satu wajib diisi
+func (s *ProductService) Lookup(barcode, sku string) (*models.Product, error) {
+	barcode, sku = normalizeBarcode(barcode), normalizeSKU(sku)
+	switch {
+	case barcode != "" && sku != "":
+		return nil, models.NewValidationError("barcode", "isi salah satu dari barcode atau sku")
+	case barcode != "":
+		return s.repo.GetByBarcode(barcode)
+	case sku != "":
+		return s.repo.GetBySKU(sku)
+	}
+	return nil, models.NewValidationError("barcode", "barcode atau sku wajib diisi")
+}
+
+// GenerateBarcodes - beri barcode EAN-13 internal untuk produk yang belum punya barcode (roti,
+// barang repack). productIDs kosong = semua produk, produk induk yang punya varian dilewati
+// karena yang dijual variannya. Mengembalikan produk yang diberi barcode.
+func (s *ProductService) GenerateBarcodes(productIDs []int) ([]models.Product, error) {
+	var products []models.Product
+	if len(productIDs) == 0 {
+		all, err := s.repo.GetAll("")
+		if err != nil {
+			return nil, err
+		}
+		parents := make(map[int]bool)
+		for _, p := range all {
+			if p.ParentID != nil {
+				parents[*p.ParentID] = true
+			}
+		}
+		for _, p := range all {
+			if !parents[p.ID] {
+				products = append(products, p)
+			}
+		}
+	}
+	for _, id := range productIDs {
+		p, err := s.repo.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if len(p.Variants) > 0 {
+			return nil, &models.ConflictError{Message: fmt.Sprintf("produk %s punya varian, beri barcode pada variannya", p.Name)}
+		}
+		products = append(products, *p)
+	}
+
+	generated := make([]models.Product, 0)
+	barcodes := make(map[int]string)
+	for _, p := range products {
+		if len(p.Barcodes) > 0 {
+			continue
+		}
+		barcode, err := internalEAN13(p.ID)
+		if err != nil {
+			return nil, err
+		}
+		p.Barcodes = []string{barcode}
+		barcodes[p.ID] = barcode
+		generated = append(generated, p)
+	}
+	if len(barcodes) == 0 {
+		return generated, nil
+	}
+
+	if err := s.repo.AddBarcodes(barcodes); err != nil {
+		return nil, err
+	}
+	return generated, nil
+}
+
+// Label - label satu produk (barcode, nama, harga) 50 x 30 mm dalam format svg / png.
+// Mengembalikan isi dan Content-Type.
+func (s *ProductService) Label(id int, format string) ([]byte, string, error) {
+	if format != "" && format != models.LabelFormatSVG && format != models.LabelFormatPNG {
+		return nil, "", models.NewValidationError("format", "format harus svg atau png")
+	}
+
+	p, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, "", err
+	}
+	d, err := layoutLabel(p, labelWidthMM, labelHeightMM)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if format == models.LabelFormatPNG {
+		content, err := renderLabelPNG(d)
+		return content, "image/png", err
+	}
+	return renderLabelSVG(d), "image/svg+xml", nil
+}
+
+// LabelSheet - PDF A4 berisi label produk sesuai urutan productIDs. id yang sama boleh diulang
+// untuk mencetak beberapa label produk yang sama.
+func (s *ProductService) LabelSheet(productIDs []int) ([]byte, error) {
+	if len(productIDs) == 0 {
+		return nil, models.NewValidationError("ids", "pilih minimal satu produk")
+	}
+	if len(productIDs) > MaxSheetLabels {
+		return nil, models.NewValidationError("ids", fmt.Sprintf("maksimal %d label per cetak", MaxSheetLabels))
+	}
+
+	labels := make([]*labelDrawing, len(productIDs))
+	cache := make(map[int]*labelDrawing)
+	for i, id := range productIDs {
+		if d, ok := cache[id]; ok {
+			labels[i] = d
+			continue
+		}
+		p, err := s.repo.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		d, err := layoutLabel(p, sheetCellWidth, sheetCellHeight)
+		if err != nil {
+			return nil, err
+		}
+		cache[id], labels[i] = d, d
+	}
+
+	return renderLabelSheetPDF(labels), nil
+}
+
 func (s *ProductService) Update(product *models.Product) error {
+	existing, err := s.repo.GetByID(product.ID)
+	if err != nil {
+		return err
+	}
+
+	// varian tidak bisa dipindah ke induk lain, buat varian baru saja
+	product.ParentID = existing.ParentID
+	if err := s.prepareOptions(product, existing); err != nil {
+		return err
+	}
+	// stok hanya berubah lewat ledger (stock-movements) dan active lewat Delete, nilai dari body diabaikan
+	product.Stock, product.Active = existing.Stock, existing.Active
+	if len(product.Recipe) > 0 && existing.Stock != 0 {
+		return &models.ConflictError{Message: "produk masih punya stok, nolkan lewat stock-movements sebelum diberi resep"}
+	}
+	if err := s.prepareRecipe(product); err != nil {
+		return err
+	}
+	if err := validateProduct(product); err != nil {
+		return err
+	}
 	return s.repo.Update(product)
 }
 
 func (s *ProductService) Delete(id int) error {
 	return s.repo.Delete(id)
 }
+
+func validateProduct(p *models.Product) error {
+	var errs []models.FieldError
+
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		errs = append(errs, models.FieldError{Field: "name", Message: "nama produk wajib diisi"})
+	}
+	if p.Price < 0 {
+		errs = append(errs, models.FieldError{Field: "price", Message: "harga tidak boleh negatif"})
+	}
+	if p.Stock < 0 {
+		errs = append(errs, models.FieldError{Field: "stock", Message: "stok tidak boleh negatif"})
+	}
+	if p.TaxRate != nil && !validTaxRate(*p.TaxRate) {
+		errs = append(errs, models.FieldError{Field: "tax_rate", Message: "tarif pajak harus antara 0 dan 100"})
+	}
+
+	p.SKU = normalizeSKU(p.SKU)
+	if p.SKU != "" && !validProductCode(p.SKU) {
+		errs = append(errs, models.FieldError{Field: "sku", Message: fmt.Sprintf("SKU tanpa spasi, maksimal %d karakter", maxProductCodeLength)})
+	}
+
+	// barcode duplikat dalam satu produk dibuang, disimpan terurut
+	barcodes := make([]string, 0, len(p.Barcodes))
+	for i, barcode := range p.Barcodes {
+		barcode = normalizeBarcode(barcode)
+		switch {
+		case !validProductCode(barcode):
+			errs = append(errs, models.FieldError{Field: fmt.Sprintf("barcodes[%d]", i), Message: fmt.Sprintf("barcode tanpa spasi, maksimal %d karakter", maxProductCodeLength)})
+		case !validGTIN(barcode):
+			errs = append(errs, models.FieldError{Field: fmt.Sprintf("barcodes[%d]", i), Message: "digit cek barcode salah"})
+		case !slices.Contains(barcodes, barcode):
+			barcodes = append(barcodes, barcode)
+		}
+	}
+	slices.Sort(barcodes)
+	p.Barcodes = barcodes
+
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func validTaxRate(rate float64) bool {
+	return rate >= 0 && rate <= 100
+}
@@ -0,0 +1,659 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"bytes"
+	"errors"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProductServiceCRUD(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	categories := NewProductCategoryService(repositories.NewMemoryProductCategoryRepository(store))
+	products := NewProductService(repositories.NewMemoryProductRepository(store))
+
+	drinks := &models.ProductCategory{Name: "Minuman"}
+	if err := categories.Create(drinks); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	tea := &models.Product{Name: "Es Teh", Price: 5000, Stock: 10, CategoryID: &drinks.ID}
+	coffee := &models.Product{Name: "Kopi Susu", Price: 18000, CategoryID: &drinks.ID}
+	for _, p := range []*models.Product{tea, coffee} {
+		if err := products.Create(p); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+
+	if tea.ID == 0 || coffee.ID == tea.ID {
+		t.Fatalf("ids not assigned: %d, %d", tea.ID, coffee.ID)
+	}
+
+	tests := []struct {
+		name   string
+		search string
+		want   int
+	}{
+		{name: "semua produk", search: "", want: 2},
+		{name: "cari case-insensitive", search: "KOPI", want: 1},
+		{name: "tidak ada yang cocok", search: "roti", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := products.GetAll(tt.search)
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("GetAll(%q) = %d products, want %d", tt.search, len(got), tt.want)
+			}
+		})
+	}
+
+	got, err := products.GetByID(tea.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Category == nil || got.Category.Name != "Minuman" {
+		t.Errorf("category = %+v, want Minuman", got.Category)
+	}
+
+	tea.Price = 6000
+	if err := products.Update(tea); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, _ := products.GetByID(tea.ID); got.Price != 6000 {
+		t.Errorf("price after update = %d, want 6000", got.Price)
+	}
+
+	if err := products.Update(&models.Product{ID: 999, Name: "x"}); err == nil {
+		t.Error("Update unknown product: expected error")
+	}
+
+	if err := products.Delete(coffee.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, err := products.GetByID(coffee.ID); err != nil || got.Active {
+		t.Errorf("GetByID after delete = %+v, %v, want active false", got, err)
+	}
+	if got, _ := products.GetAll(""); len(got) != 1 || got[0].ID != tea.ID {
+		t.Errorf("GetAll after delete = %+v, want only tea", got)
+	}
+	if err := products.Delete(coffee.ID); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("Delete twice: err = %v, want %v", err, models.ErrConflict)
+	}
+	if err := products.Delete(999); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Delete unknown product: err = %v, want %v", err, models.ErrNotFound)
+	}
+
+	// hapus kategori -> category_id produk jadi null
+	if err := categories.Delete(drinks.ID); err != nil {
+		t.Fatalf("delete category: %v", err)
+	}
+	if got, _ := products.GetByID(tea.ID); got.CategoryID != nil || got.Category != nil {
+		t.Errorf("category after delete = %v, want nil", got.CategoryID)
+	}
+}
+
+func TestProductSoftDelete(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	env.tea.SKU, env.tea.Barcodes = "TEH-01", []string{"8991234567891"}
+	if err := env.products.Update(env.tea); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	sale, _, err := env.checkout(models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 2}},
+		Payments: cash(10000),
+	}, true)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	// produk dengan riwayat stok dan transaksi tetap bisa dihapus, datanya hanya dinonaktifkan
+	if err := env.products.Delete(env.tea.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{name: "lookup barcode", call: func() error {
+			_, err := env.products.Lookup("8991234567891", "")
+			return err
+		}, want: models.ErrNotFound},
+		{name: "lookup sku", call: func() error {
+			_, err := env.products.Lookup("", "TEH-01")
+			return err
+		}, want: models.ErrNotFound},
+		{name: "checkout product_id", call: func() error {
+			_, _, err := env.checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}}, Payments: cash(5000)}, true)
+			return err
+		}, want: models.ErrValidation},
+		{name: "checkout barcode", call: func() error {
+			_, _, err := env.checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{Barcode: "8991234567891", Quantity: 1}}, Payments: cash(5000)}, false)
+			return err
+		}, want: models.ErrValidation},
+		{name: "barcode masih tersimpan di produk yang dihapus", call: func() error {
+			return env.products.Create(&models.Product{Name: "Teh Baru", Barcodes: []string{"8991234567891"}})
+		}, want: models.ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	all, err := env.products.GetAll("")
+	if err != nil || len(all) != 1 || all[0].ID != env.bread.ID {
+		t.Errorf("GetAll = %+v, %v, want only bread", all, err)
+	}
+
+	// riwayat tetap bisa dibaca
+	if _, err := env.transactions.GetByID(sale.ID); err != nil {
+		t.Errorf("GetByID transaction: %v", err)
+	}
+	movements, err := env.ledger.Movements(env.tea.ID, models.StockMovementFilter{})
+	if err != nil || movements.Total != 2 {
+		t.Errorf("Movements = %+v, %v, want initial + sale", movements, err)
+	}
+	if got := env.stock(t, env.tea.ID); got != 8 {
+		t.Errorf("stok = %d, want 8", got)
+	}
+
+	// produk yang dihapus tidak berubah aktif lewat update
+	env.tea.Active, env.tea.Barcodes = true, nil
+	if err := env.products.Update(env.tea); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, _ := env.products.GetByID(env.tea.ID); got.Active {
+		t.Error("produk aktif lagi setelah update")
+	}
+}
+
+func TestProductServiceValidation(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	products := NewProductService(repositories.NewMemoryProductRepository(store))
+	missingCategory := 42
+	rate := 150.0
+
+	tests := []struct {
+		name    string
+		product models.Product
+		want    error
+	}{
+		{name: "nama kosong", product: models.Product{Name: "  ", Price: 1000}, want: models.ErrValidation},
+		{name: "harga dan stok negatif", product: models.Product{Name: "Teh", Price: -1, Stock: -1}, want: models.ErrValidation},
+		{name: "tarif pajak di luar batas", product: models.Product{Name: "Teh", TaxRate: &rate}, want: models.ErrValidation},
+		{name: "kategori tidak ada", product: models.Product{Name: "Teh", CategoryID: &missingCategory}, want: models.ErrValidation},
+		{name: "valid", product: models.Product{Name: "Teh", Price: 1000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := products.Create(&tt.product)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if err := products.Update(&models.Product{ID: 999, Name: "x"}); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("update unknown product: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestProductBarcodes(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	products := NewProductService(repositories.NewMemoryProductRepository(store))
+
+	tea := &models.Product{Name: "Es Teh", Price: 5000, SKU: " teh-01 ", Barcodes: []string{"8991234567907", " 8991234567891", "8991234567907"}}
+	if err := products.Create(tea); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	if tea.SKU != "TEH-01" || len(tea.Barcodes) != 2 || tea.Barcodes[0] != "8991234567891" {
+		t.Errorf("sku = %q, barcodes = %v, want TEH-01 dan 2 barcode terurut", tea.SKU, tea.Barcodes)
+	}
+	if err := products.Create(&models.Product{Name: "Roti", Price: 12000}); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	createTests := []struct {
+		name    string
+		product models.Product
+		want    error
+	}{
+		{name: "sku sudah dipakai", product: models.Product{Name: "Teh", SKU: "TEH-01"}, want: models.ErrConflict},
+		{name: "barcode sudah dipakai", product: models.Product{Name: "Teh", Barcodes: []string{"8991234567891"}}, want: models.ErrConflict},
+		{name: "digit cek salah", product: models.Product{Name: "Teh", Barcodes: []string{"8991234567890"}}, want: models.ErrValidation},
+		{name: "barcode kosong", product: models.Product{Name: "Teh", Barcodes: []string{" "}}, want: models.ErrValidation},
+		{name: "sku berisi spasi", product: models.Product{Name: "Teh", SKU: "TEH 02"}, want: models.ErrValidation},
+		{name: "barcode non-numerik", product: models.Product{Name: "Teh Botol", Barcodes: []string{"TOKO-0001"}}},
+	}
+	for _, tt := range createTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := products.Create(&tt.product); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	lookupTests := []struct {
+		name    string
+		barcode string
+		sku     string
+		wantID  int
+		want    error
+	}{
+		{name: "barcode", barcode: "8991234567907", wantID: tea.ID},
+		{name: "sku case-insensitive", sku: "Teh-01", wantID: tea.ID},
+		{name: "barcode tidak dikenal", barcode: "4006381333931", want: models.ErrNotFound},
+		{name: "sku tidak dikenal", sku: "KOPI-01", want: models.ErrNotFound},
+		{name: "kosong", want: models.ErrValidation},
+		{name: "barcode dan sku", barcode: "8991234567907", sku: "TEH-01", want: models.ErrValidation},
+	}
+	for _, tt := range lookupTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := products.Lookup(tt.barcode, tt.sku)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && got.ID != tt.wantID {
+				t.Errorf("product = %d, want %d", got.ID, tt.wantID)
+			}
+		})
+	}
+
+	// update mengganti seluruh barcode, barcode lama bisa dipakai produk lain
+	tea.Barcodes = []string{"8991234567891"}
+	if err := products.Update(tea); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := products.Lookup("8991234567907", ""); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("barcode yang dihapus: err = %v, want %v", err, models.ErrNotFound)
+	}
+	if err := products.Create(&models.Product{Name: "Teh Pucuk", Barcodes: []string{"8991234567907"}}); err != nil {
+		t.Errorf("barcode bekas produk lain: %v", err)
+	}
+}
+
+func TestValidGTIN(t *testing.T) {
+	tests := []struct {
+		barcode string
+		want    bool
+	}{
+		{"4006381333931", true},  // EAN-13
+		{"4006381333932", false}, // digit cek salah
+		{"036000291452", true},   // UPC-A
+		{"96385074", true},       // EAN-8
+		{"96385075", false},
+		{"ABC-123", true}, // bukan GTIN, tidak dicek
+		{"12345", true},
+	}
+
+	for _, tt := range tests {
+		if got := validGTIN(tt.barcode); got != tt.want {
+			t.Errorf("validGTIN(%q) = %v, want %v", tt.barcode, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateBarcodes(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	products := NewProductService(repositories.NewMemoryProductRepository(store))
+
+	bread := &models.Product{Name: "Roti Sobek", Price: 15000}
+	tea := &models.Product{Name: "Es Teh", Price: 5000, Barcodes: []string{"8991234567891"}}
+	sugar := &models.Product{Name: "Gula Repack 1kg", Price: 17000}
+	for _, p := range []*models.Product{bread, tea, sugar} {
+		if err := products.Create(p); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+
+	generated, err := products.GenerateBarcodes([]int{bread.ID, tea.ID})
+	if err != nil {
+		t.Fatalf("GenerateBarcodes: %v", err)
+	}
+	if len(generated) != 1 || generated[0].ID != bread.ID || generated[0].Barcodes[0] != mustInternalEAN13(t, bread.ID) {
+		t.Errorf("generated = %+v, want hanya %s dengan %s", generated, bread.Name, mustInternalEAN13(t, bread.ID))
+	}
+	if got, err := products.Lookup(mustInternalEAN13(t, bread.ID), ""); err != nil || got.ID != bread.ID {
+		t.Errorf("Lookup barcode internal: product = %v, err = %v", got, err)
+	}
+
+	// tanpa product_ids: semua produk yang belum punya barcode
+	generated, err = products.GenerateBarcodes(nil)
+	if err != nil {
+		t.Fatalf("GenerateBarcodes: %v", err)
+	}
+	if len(generated) != 1 || generated[0].ID != sugar.ID {
+		t.Errorf("generated = %+v, want hanya %s", generated, sugar.Name)
+	}
+
+	if _, err := products.GenerateBarcodes([]int{99}); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("produk tidak ada: err = %v, want %v", err, models.ErrNotFound)
+	}
+
+	// produk induk tidak diberi barcode, variannya yang dijual
+	coffee := &models.Product{Name: "Kopi", Options: []models.ProductOption{{Name: "Ukuran", Values: []string{"S"}}}}
+	if err := products.Create(coffee); err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	small := &models.Product{ParentID: &coffee.ID, OptionValues: []string{"S"}, Price: 8000}
+	if err := products.Create(small); err != nil {
+		t.Fatalf("create variant: %v", err)
+	}
+	if _, err := products.GenerateBarcodes([]int{coffee.ID}); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("produk induk: err = %v, want %v", err, models.ErrConflict)
+	}
+	generated, err = products.GenerateBarcodes(nil)
+	if err != nil {
+		t.Fatalf("GenerateBarcodes: %v", err)
+	}
+	if len(generated) != 1 || generated[0].ID != small.ID {
+		t.Errorf("generated = %+v, want hanya varian %d", generated, small.ID)
+	}
+
+	// satu barcode bentrok, tidak ada produk lain yang sempat diberi barcode
+	milk := &models.Product{Name: "Susu Repack", Price: 9000}
+	salt := &models.Product{Name: "Garam Repack", Price: 4000}
+	for _, p := range []*models.Product{milk, salt} {
+		if err := products.Create(p); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+	tea.Barcodes = append(tea.Barcodes, mustInternalEAN13(t, salt.ID))
+	if err := products.Update(tea); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := products.GenerateBarcodes([]int{milk.ID, salt.ID}); !errors.Is(err, models.ErrConflict) {
+		t.Fatalf("barcode bentrok: err = %v, want %v", err, models.ErrConflict)
+	}
+	if got, _ := products.GetByID(milk.ID); len(got.Barcodes) != 0 {
+		t.Errorf("barcodes %s = %v, want kosong", milk.Name, got.Barcodes)
+	}
+}
+
+func TestProductLabels(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	products := NewProductService(repositories.NewMemoryProductRepository(store))
+
+	bread := &models.Product{Name: "Roti <Sobek>", Price: 15000, Barcodes: []string{"2000000000015"}}
+	sugar := &models.Product{Name: "Gula Repack", Price: 17000, SKU: "GULA-1KG"}
+	plain := &models.Product{Name: "Tanpa Kode", Price: 1000}
+	for _, p := range []*models.Product{bread, sugar, plain} {
+		if err := products.Create(p); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+
+	svg, contentType, err := products.Label(bread.ID, models.LabelFormatSVG)
+	if err != nil {
+		t.Fatalf("Label svg: %v", err)
+	}
+	for _, want := range []string{`width="50mm" height="30mm"`, "Roti &lt;Sobek&gt;", "2000000000015", "Rp 15.000"} {
+		if !strings.Contains(string(svg), want) {
+			t.Errorf("svg tidak mengandung %q", want)
+		}
+	}
+	if contentType != "image/svg+xml" {
+		t.Errorf("content type = %q", contentType)
+	}
+
+	// SKU dicetak sebagai Code 128
+	content, contentType, err := products.Label(sugar.ID, models.LabelFormatPNG)
+	if err != nil {
+		t.Fatalf("Label png: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 400 || b.Dy() != 240 || contentType != "image/png" {
+		t.Errorf("png %dx%d %s, want 400x240 image/png", b.Dx(), b.Dy(), contentType)
+	}
+
+	ids := make([]int, 25)
+	for i := range ids {
+		ids[i] = []int{bread.ID, sugar.ID}[i%2]
+	}
+	pdf, err := products.LabelSheet(ids)
+	if err != nil {
+		t.Fatalf("LabelSheet: %v", err)
+	}
+	for _, want := range []string{"/Count 2", "/MediaBox [0 0 595.28 841.89]", "(Rp 17.000)", " re f\n"} {
+		if !strings.Contains(string(pdf), want) {
+			t.Errorf("pdf tidak mengandung %q", want)
+		}
+	}
+
+	errorTests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "produk tanpa barcode dan SKU", err: labelErr(products.Label(plain.ID, "")), want: models.ErrConflict},
+		{name: "format tidak dikenal", err: labelErr(products.Label(bread.ID, "jpg")), want: models.ErrValidation},
+		{name: "produk tidak ada", err: labelErr(products.Label(99, "")), want: models.ErrNotFound},
+		{name: "lembar tanpa produk", err: sheetErr(products.LabelSheet(nil)), want: models.ErrValidation},
+		{name: "lembar dengan produk tanpa kode", err: sheetErr(products.LabelSheet([]int{bread.ID, plain.ID})), want: models.ErrConflict},
+	}
+	for _, tt := range errorTests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, tt.err, tt.want)
+		}
+	}
+}
+
+func labelErr(_ []byte, _ string, err error) error { return err }
+
+func sheetErr(_ []byte, err error) error { return err }
+
+func TestProductVariants(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	products := NewProductService(repositories.NewMemoryProductRepository(store))
+
+	drinks := &models.ProductCategory{Name: "Minuman"}
+	if err := NewProductCategoryService(repositories.NewMemoryProductCategoryRepository(store)).Create(drinks); err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	tea := &models.Product{Name: "Es Teh", Price: 5000, CategoryID: &drinks.ID, Options: []models.ProductOption{
+		{Name: " Ukuran ", Values: []string{"S", "M", "L"}},
+	}}
+	if err := products.Create(tea); err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	if tea.Options[0].Name != "Ukuran" {
+		t.Errorf("option name = %q, want Ukuran", tea.Options[0].Name)
+	}
+	bread := &models.Product{Name: "Roti", Price: 12000}
+	if err := products.Create(bread); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	missing := 999
+	small := &models.Product{ParentID: &tea.ID, OptionValues: []string{"s"}, SKU: "TEH-S", Price: 5000, Stock: 10}
+	large := &models.Product{ParentID: &tea.ID, OptionValues: []string{"L"}, Name: "Es Teh Jumbo", Price: 8000}
+	for _, p := range []*models.Product{small, large} {
+		if err := products.Create(p); err != nil {
+			t.Fatalf("create variant: %v", err)
+		}
+	}
+	if small.Name != "Es Teh S" || small.OptionValues[0] != "S" {
+		t.Errorf("variant = %q %v, want Es Teh S [S]", small.Name, small.OptionValues)
+	}
+	if small.CategoryID == nil || *small.CategoryID != drinks.ID {
+		t.Errorf("variant category = %v, want %d", small.CategoryID, drinks.ID)
+	}
+
+	createTests := []struct {
+		name    string
+		product models.Product
+		want    error
+	}{
+		{name: "kombinasi sudah ada", product: models.Product{ParentID: &tea.ID, OptionValues: []string{"S"}}, want: models.ErrConflict},
+		{name: "nilai tidak ada di induk", product: models.Product{ParentID: &tea.ID, OptionValues: []string{"XL"}}, want: models.ErrValidation},
+		{name: "jumlah nilai salah", product: models.Product{ParentID: &tea.ID, OptionValues: []string{"M", "Dingin"}}, want: models.ErrValidation},
+		{name: "induk tidak ada", product: models.Product{ParentID: &missing, OptionValues: []string{"M"}}, want: models.ErrValidation},
+		{name: "induk tanpa options", product: models.Product{ParentID: &bread.ID, OptionValues: []string{"M"}}, want: models.ErrValidation},
+		{name: "varian dari varian", product: models.Product{ParentID: &small.ID, OptionValues: []string{"S"}}, want: models.ErrValidation},
+		{name: "option_values tanpa induk", product: models.Product{Name: "Teh", OptionValues: []string{"S"}}, want: models.ErrValidation},
+		{name: "option dobel", product: models.Product{Name: "Kaos", Options: []models.ProductOption{
+			{Name: "Ukuran", Values: []string{"S"}}, {Name: "ukuran", Values: []string{"M"}},
+		}}, want: models.ErrValidation},
+		{name: "nilai option dobel", product: models.Product{Name: "Kaos", Options: []models.ProductOption{
+			{Name: "Warna", Values: []string{"Merah", "merah"}},
+		}}, want: models.ErrValidation},
+		{name: "option tanpa nilai", product: models.Product{Name: "Kaos", Options: []models.ProductOption{{Name: "Warna"}}}, want: models.ErrValidation},
+		{name: "varian medium", product: models.Product{ParentID: &tea.ID, OptionValues: []string{" m "}, Price: 6500}},
+	}
+	for _, tt := range createTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := products.Create(&tt.product); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	got, err := products.GetByID(tea.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	var names []string
+	for _, v := range got.Variants {
+		names = append(names, v.Name)
+	}
+	if strings.Join(names, ",") != "Es Teh S,Es Teh Jumbo,Es Teh M" {
+		t.Errorf("variants = %v", names)
+	}
+	if v, _ := products.GetByID(small.ID); v.ParentID == nil || *v.ParentID != tea.ID || len(v.Variants) != 0 {
+		t.Errorf("variant parent = %v, variants = %d", v.ParentID, len(v.Variants))
+	}
+
+	updateTests := []struct {
+		name    string
+		options []models.ProductOption
+		want    error
+	}{
+		{name: "nilai masih dipakai varian", options: []models.ProductOption{{Name: "Ukuran", Values: []string{"S", "M"}}}, want: models.ErrConflict},
+		{name: "tambah dimensi", options: []models.ProductOption{
+			{Name: "Ukuran", Values: []string{"S", "M", "L"}}, {Name: "Suhu", Values: []string{"Dingin"}},
+		}, want: models.ErrConflict},
+		{name: "tambah nilai", options: []models.ProductOption{{Name: "Ukuran", Values: []string{"S", "M", "L", "XL"}}}},
+	}
+	for _, tt := range updateTests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := *got
+			update.Options = tt.options
+			if err := products.Update(&update); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// parent_id tidak bisa diubah lewat update
+	small.ParentID = &bread.ID
+	small.Price = 5500
+	if err := products.Update(small); err != nil {
+		t.Fatalf("update variant: %v", err)
+	}
+	if small.ParentID == nil || *small.ParentID != tea.ID {
+		t.Errorf("parent after update = %v, want %d", small.ParentID, tea.ID)
+	}
+
+	if err := products.Delete(tea.ID); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("delete parent with variants: err = %v, want %v", err, models.ErrConflict)
+	}
+	if err := products.Delete(large.ID); err != nil {
+		t.Errorf("delete variant: %v", err)
+	}
+	// varian yang dihapus tidak ikut di induk, kombinasinya bisa dipakai varian baru
+	got, _ = products.GetByID(tea.ID)
+	if slices.ContainsFunc(got.Variants, func(v models.Product) bool { return v.ID == large.ID }) {
+		t.Errorf("variants after delete = %+v, want without %d", got.Variants, large.ID)
+	}
+	if err := products.Create(&models.Product{ParentID: &tea.ID, OptionValues: []string{"L"}}); err != nil {
+		t.Errorf("recreate deleted variant: %v", err)
+	}
+}
+
+func TestProductRecipe(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	products := NewProductService(repositories.NewMemoryProductRepository(store))
+
+	milk := &models.Product{Name: "Susu", Stock: 1000}
+	cup := &models.Product{Name: "Cup"}
+	tea := &models.Product{Name: "Teh", Options: []models.ProductOption{{Name: "Ukuran", Values: []string{"S"}}}}
+	for _, p := range []*models.Product{milk, cup, tea} {
+		if err := products.Create(p); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+	if err := products.Create(&models.Product{ParentID: &tea.ID, OptionValues: []string{"S"}}); err != nil {
+		t.Fatalf("create variant: %v", err)
+	}
+
+	latte := &models.Product{Name: "Es Kopi Susu", Price: 20000, Stock: 5, Recipe: []models.RecipeItem{
+		{ComponentID: milk.ID, Quantity: 150}, {ComponentID: cup.ID, Quantity: 1},
+	}}
+	if err := products.Create(latte); err != nil {
+		t.Fatalf("create composite: %v", err)
+	}
+	if latte.Stock != 0 {
+		t.Errorf("composite stock = %d, want 0", latte.Stock)
+	}
+	got, err := products.GetByID(latte.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if len(got.Recipe) != 2 || got.Recipe[0].ComponentName != "Susu" || got.Recipe[0].Quantity != 150 {
+		t.Errorf("recipe = %+v", got.Recipe)
+	}
+
+	recipeTests := []struct {
+		name   string
+		recipe []models.RecipeItem
+		want   error
+	}{
+		{name: "komponen tidak ada", recipe: []models.RecipeItem{{ComponentID: 999, Quantity: 1}}, want: models.ErrValidation},
+		{name: "quantity nol", recipe: []models.RecipeItem{{ComponentID: milk.ID}}, want: models.ErrValidation},
+		{name: "komponen dobel", recipe: []models.RecipeItem{{ComponentID: cup.ID, Quantity: 1}, {ComponentID: cup.ID, Quantity: 1}}, want: models.ErrValidation},
+		{name: "komponen komposit", recipe: []models.RecipeItem{{ComponentID: latte.ID, Quantity: 1}}, want: models.ErrValidation},
+		{name: "komponen induk varian", recipe: []models.RecipeItem{{ComponentID: tea.ID, Quantity: 1}}, want: models.ErrValidation},
+		{name: "resep valid", recipe: []models.RecipeItem{{ComponentID: milk.ID, Quantity: 200}}},
+	}
+	for _, tt := range recipeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := products.Create(&models.Product{Name: "Susu Segar", Recipe: tt.recipe}); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// resep hanya satu tingkat, komponen tidak bisa dihapus selama dipakai resep
+	cup.Recipe = []models.RecipeItem{{ComponentID: milk.ID, Quantity: 1}}
+	if err := products.Update(cup); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("recipe on component: err = %v, want %v", err, models.ErrConflict)
+	}
+	if err := products.Delete(cup.ID); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("delete component: err = %v, want %v", err, models.ErrConflict)
+	}
+
+	latte.Recipe = nil
+	if err := products.Update(latte); err != nil {
+		t.Fatalf("remove recipe: %v", err)
+	}
+	if err := products.Delete(cup.ID); err != nil {
+		t.Errorf("delete unused component: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// MaxProductOptions - jumlah dimensi varian maksimal (mis. ukuran x warna x bahan)
+	MaxProductOptions = 3
+	// MaxOptionValues - jumlah nilai maksimal per dimensi
+	MaxOptionValues = 50
+)
+
+// prepareOptions - validasi options produk induk atau option_values varian. Varian tanpa nama
+// diberi nama induk + nilai option, varian tanpa kategori ikut kategori induk supaya laporan
+// per kategori tetap menyatu. existing diisi saat update.
+func (s *ProductService) prepareOptions(p *models.Product, existing *models.Product) error {
+	if p.ParentID == nil {
+		if len(p.OptionValues) > 0 {
+			return models.NewValidationError("option_values", "option_values hanya untuk varian (parent_id diisi)")
+		}
+		p.OptionValues = []string{}
+
+		var errs []models.FieldError
+		p.Options, errs = normalizeOptions(p.Options)
+		if len(errs) > 0 {
+			return &models.ValidationError{Errors: errs}
+		}
+		if existing != nil {
+			return checkVariantsStillValid(p.Options, existing.Variants)
+		}
+		return nil
+	}
+
+	if len(p.Options) > 0 {
+		return models.NewValidationError("options", "varian tidak bisa punya options sendiri")
+	}
+	p.Options = []models.ProductOption{}
+
+	parent, err := s.repo.GetByID(*p.ParentID)
+	if errors.Is(err, models.ErrNotFound) {
+		return models.NewValidationError("parent_id", "produk induk tidak ditemukan")
+	}
+	if err != nil {
+		return err
+	}
+	if parent.ParentID != nil {
+		return models.NewValidationError("parent_id", "varian tidak bisa menjadi produk induk")
+	}
+	if !parent.Active {
+		return models.NewValidationError("parent_id", "produk induk sudah dihapus")
+	}
+	if len(parent.Options) == 0 {
+		return models.NewValidationError("parent_id", "produk induk belum punya options")
+	}
+
+	values, err := matchOptionValues(parent.Options, p.OptionValues)
+	if err != nil {
+		return err
+	}
+	p.OptionValues = values
+
+	if strings.TrimSpace(p.Name) == "" {
+		p.Name = parent.Name + " " + strings.Join(values, " / ")
+	}
+	if p.CategoryID == nil {
+		p.CategoryID = parent.CategoryID
+	}
+	return nil
+}
+
+// normalizeOptions - nama dimensi dan nilai tidak boleh kosong atau dobel (tanpa membedakan
+// huruf besar kecil)
+func normalizeOptions(options []models.ProductOption) ([]models.ProductOption, []models.FieldError) {
+	var errs []models.FieldError
+	if len(options) > MaxProductOptions {
+		return nil, []models.FieldError{{Field: "options", Message: fmt.Sprintf("maksimal %d dimensi varian", MaxProductOptions)}}
+	}
+
+	normalized := make([]models.ProductOption, 0, len(options))
+	var names []string
+	for i, o := range options {
+		o.Name = strings.TrimSpace(o.Name)
+		if o.Name == "" || slices.Contains(names, strings.ToLower(o.Name)) {
+			errs = append(errs, models.FieldError{Field: fmt.Sprintf("options[%d].name", i), Message: "nama option wajib diisi dan tidak boleh dobel"})
+		}
+		names = append(names, strings.ToLower(o.Name))
+
+		if len(o.Values) == 0 || len(o.Values) > MaxOptionValues {
+			errs = append(errs, models.FieldError{Field: fmt.Sprintf("options[%d].values", i), Message: fmt.Sprintf("isi 1 sampai %d nilai", MaxOptionValues)})
+		}
+		values := make([]string, 0, len(o.Values))
+		for j, v := range o.Values {
+			v = strings.TrimSpace(v)
+			if v == "" || slices.ContainsFunc(values, func(existing string) bool { return strings.EqualFold(existing, v) }) {
+				errs = append(errs, models.FieldError{Field: fmt.Sprintf("options[%d].values[%d]", i, j), Message: "nilai option wajib diisi dan tidak boleh dobel"})
+			}
+			values = append(values, v)
+		}
+		normalized = append(normalized, models.ProductOption{Name: o.Name, Values: values})
+	}
+	return normalized, errs
+}
+
+// matchOptionValues - satu nilai per dimensi induk, dikembalikan dengan penulisan dari induk
+func matchOptionValues(options []models.ProductOption, values []string) ([]string, error) {
+	if len(values) != len(options) {
+		names := make([]string, len(options))
+		for i, o := range options {
+			names[i] = o.Name
+		}
+		return nil, models.NewValidationError("option_values", "isi satu nilai untuk setiap option: "+strings.Join(names, ", "))
+	}
+
+	matched := make([]string, len(values))
+	for i, v := range values {
+		idx := slices.IndexFunc(options[i].Values, func(allowed string) bool { return strings.EqualFold(allowed, strings.TrimSpace(v)) })
+		if idx < 0 {
+			return nil, models.NewValidationError(
+				fmt.Sprintf("option_values[%d]", i),
+				fmt.Sprintf("%s harus salah satu dari %s", options[i].Name, strings.Join(options[i].Values, ", ")),
+			)
+		}
+		matched[i] = options[i].Values[idx]
+	}
+	return matched, nil
+}
+
+// checkVariantsStillValid - options induk boleh ditambah nilainya, tapi nilai yang masih dipakai
+// varian tidak boleh dihapus dan jumlah dimensi tidak boleh berubah selama masih ada varian
+func checkVariantsStillValid(options []models.ProductOption, variants []models.Product) error {
+	for _, v := range variants {
+		if len(v.OptionValues) != len(options) {
+			return &models.ConflictError{Message: "jumlah option tidak bisa diubah selama produk masih punya varian"}
+		}
+		for i, value := range v.OptionValues {
+			if !slices.Contains(options[i].Values, value) {
+				return &models.ConflictError{Message: fmt.Sprintf("nilai %s %s masih dipakai varian %s", options[i].Name, value, v.Name)}
+			}
+		}
+	}
+	return nil
+}
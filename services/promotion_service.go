@@ -0,0 +1,125 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type PromotionService struct {
+	repo repositories.PromotionRepository
+}
+
+func NewPromotionService(repo repositories.PromotionRepository) *PromotionService {
+	return &PromotionService{repo: repo}
+}
+
+func (s *PromotionService) GetAll() ([]models.Promotion, error) {
+	return s.repo.GetAll()
+}
+
+func (s *PromotionService) Create(promotion *models.Promotion) error {
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+	return s.repo.Create(promotion)
+}
+
+func (s *PromotionService) GetByID(id int) (*models.Promotion, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *PromotionService) Update(promotion *models.Promotion) error {
+	if err := validatePromotion(promotion); err != nil {
+		return err
+	}
+	return s.repo.Update(promotion)
+}
+
+func (s *PromotionService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
+
+// validatePromotion - cek field wajib sesuai tipe promo
+func validatePromotion(p *models.Promotion) error {
+	var errs []models.FieldError
+	add := func(field, message string) {
+		errs = append(errs, models.FieldError{Field: field, Message: message})
+	}
+
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		add("name", "nama promo wajib diisi")
+	}
+
+	if p.MinSpend < 0 {
+		add("min_spend", "tidak boleh negatif")
+	}
+	if p.MaxDiscount < 0 {
+		add("max_discount", "tidak boleh negatif")
+	}
+
+	switch p.Type {
+	case models.PromotionTypePercentage, models.PromotionTypeCartPercentage:
+		if p.Value < 1 || p.Value > 100 {
+			add("value", "persentase harus 1-100")
+		}
+	case models.PromotionTypeFixed, models.PromotionTypeCartFixed:
+		if p.Value <= 0 {
+			add("value", "potongan harus lebih dari 0")
+		}
+	case models.PromotionTypeBuyXGetY:
+		if p.BuyQuantity <= 0 {
+			add("buy_quantity", "harus lebih dari 0")
+		}
+		if p.GetQuantity <= 0 {
+			add("get_quantity", "harus lebih dari 0")
+		}
+		if len(p.ProductIDs) == 0 {
+			add("product_ids", "minimal satu produk")
+		}
+	case models.PromotionTypeBundle:
+		if len(p.BundleItems) == 0 {
+			add("bundle_items", "minimal satu produk dalam paket")
+		}
+		seen := make(map[int]bool)
+		for i, item := range p.BundleItems {
+			if item.ProductID <= 0 {
+				add(fmt.Sprintf("bundle_items[%d].product_id", i), "product_id wajib diisi")
+			} else if seen[item.ProductID] {
+				add(fmt.Sprintf("bundle_items[%d].product_id", i), "produk duplikat dalam paket")
+			}
+			seen[item.ProductID] = true
+			if item.Quantity <= 0 {
+				add(fmt.Sprintf("bundle_items[%d].quantity", i), "quantity harus lebih dari 0")
+			}
+		}
+		if p.BundlePrice <= 0 {
+			add("bundle_price", "harga paket harus lebih dari 0")
+		}
+	default:
+		add("type", "tipe harus salah satu dari "+strings.Join(models.PromotionTypes, ", "))
+	}
+
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		add("ends_at", "harus setelah starts_at")
+	}
+
+	if p.ProductIDs == nil {
+		p.ProductIDs = []int{}
+	}
+	slices.Sort(p.ProductIDs)
+	p.ProductIDs = slices.Compact(p.ProductIDs)
+
+	if p.BundleItems == nil {
+		p.BundleItems = []models.PromotionBundleItem{}
+	}
+
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+
+	return nil
+}
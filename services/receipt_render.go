@@ -0,0 +1,306 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"bytes"
+	"html/template"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// receiptColumns - jumlah karakter per baris printer thermal dengan font standar
+func receiptColumns(width int) int {
+	if width == models.ReceiptWidth58 {
+		return 32
+	}
+	return 48
+}
+
+// receiptLine - satu baris struk monospace, bold hanya dipakai output ESC/POS
+type receiptLine struct {
+	text string
+	bold bool
+}
+
+// receiptTextLines - layout struk teks monospace, dipakai juga untuk pdf dan ESC/POS
+func receiptTextLines(r *models.Receipt, width int) []receiptLine {
+	cols := receiptColumns(width)
+	separator := strings.Repeat("-", cols)
+	var lines []receiptLine
+	add := func(bold bool, texts ...string) {
+		for _, text := range texts {
+			lines = append(lines, receiptLine{text: text, bold: bold})
+		}
+	}
+
+	for i, line := range r.Header {
+		add(i == 0, centerText(line, cols)...) // baris pertama biasanya nama toko
+	}
+	if r.Status == models.TransactionStatusVoided {
+		add(true, centerText("*** VOID ***", cols)...)
+	}
+	add(false, separator)
+
+	// label pendek supaya nomor struk standar (25 karakter) muat satu baris di kertas 58 mm
+	info := [][2]string{
+		{"No", r.ReceiptNumber},
+		{"Tgl", r.CreatedAt.Format("02-01-2006 15:04")},
+		{"Kasir", r.CashierName},
+		{"POS", r.TerminalName},
+	}
+	for _, kv := range info {
+		if kv[1] != "" {
+			add(false, prefixedText(padRight(kv[0], 5)+": ", kv[1], cols)...)
+		}
+	}
+	add(false, separator)
+
+	for _, item := range r.Items {
+		add(false, wrapText(item.Name, cols)...)
+		add(false, amountLine("  "+strconv.Itoa(item.Quantity)+" x "+formatRupiah(item.Price), item.Subtotal, cols))
+		for _, d := range item.Discounts {
+			add(false, amountLine("  "+d.Name, -d.Amount, cols))
+		}
+	}
+	add(false, separator)
+
+	add(false, amountLine("Subtotal", r.Subtotal, cols))
+	for _, d := range r.Discounts {
+		add(false, amountLine(d.Name, -d.Amount, cols))
+	}
+	if r.DiscountAmount > 0 {
+		add(false, amountLine("Total Diskon", -r.DiscountAmount, cols))
+	}
+	if r.TaxAmount > 0 {
+		add(false, amountLine("DPP", r.TaxBase, cols))
+		if r.PriceIncludesTax {
+			add(false, amountLine("PPN (termasuk)", r.TaxAmount, cols))
+		} else {
+			add(false, amountLine("PPN", r.TaxAmount, cols))
+		}
+	}
+	if r.ServiceCharge > 0 {
+		add(false, amountLine("Service", r.ServiceCharge, cols))
+	}
+	add(true, amountLine("TOTAL", r.TotalAmount, cols))
+
+	for _, p := range r.Payments {
+		add(false, amountLine(p.Method, p.Amount, cols))
+		if p.Reference != "" {
+			add(false, prefixedText("  Ref ", p.Reference, cols)...)
+		}
+	}
+	add(false, amountLine("Kembali", r.ChangeAmount, cols))
+	if r.RefundedAmount > 0 {
+		add(false, amountLine("Refund", -r.RefundedAmount, cols))
+	}
+
+	if len(r.Footer) > 0 {
+		add(false, separator)
+		for _, line := range r.Footer {
+			add(false, centerText(line, cols)...)
+		}
+	}
+
+	return lines
+}
+
+func receiptPlainLines(r *models.Receipt, width int) []string {
+	lines := receiptTextLines(r, width)
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.text
+	}
+	return texts
+}
+
+func renderReceiptText(r *models.Receipt, width int) string {
+	return strings.Join(receiptPlainLines(r, width), "\n") + "\n"
+}
+
+func renderReceiptPDF(r *models.Receipt, width int) []byte {
+	return textPDF(receiptPlainLines(r, width), float64(width), receiptColumns(width))
+}
+
+var receiptHTMLTemplate = template.Must(template.New("receipt").Funcs(template.FuncMap{
+	"rupiah": formatRupiah,
+	"neg":    func(n int) int { return -n },
+	"date":   func(r *models.Receipt) string { return r.CreatedAt.Format("02-01-2006 15:04") },
+	"voided": func(status string) bool { return status == models.TransactionStatusVoided },
+}).Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<title>{{if .Receipt.ReceiptNumber}}{{.Receipt.ReceiptNumber}}{{else}}Struk {{.Receipt.TransactionID}}{{end}}</title>
+<style>
+body { margin: 0; }
+.receipt { width: {{.Width}}mm; padding: 2mm; box-sizing: border-box; font-family: monospace; font-size: 12px; }
+.center { text-align: center; }
+.void { text-align: center; font-weight: bold; }
+hr { border: 0; border-top: 1px dashed #000; }
+table { width: 100%; border-collapse: collapse; }
+td { padding: 0; vertical-align: top; }
+td.amount { text-align: right; white-space: nowrap; }
+.indent { padding-left: 2mm; }
+.total td { font-weight: bold; }
+</style>
+</head>
+<body>
+<div class="receipt">
+{{- with .Receipt}}
+{{range .Header}}<div class="center">{{.}}</div>
+{{end}}
+{{- if voided .Status}}<div class="void">*** VOID ***</div>
+{{end -}}
+<hr>
+<table>
+{{if .ReceiptNumber}}<tr><td>No</td><td>{{.ReceiptNumber}}</td></tr>
+{{end -}}
+<tr><td>Tanggal</td><td>{{date .}}</td></tr>
+{{if .CashierName}}<tr><td>Kasir</td><td>{{.CashierName}}</td></tr>
+{{end -}}
+{{if .TerminalName}}<tr><td>Terminal</td><td>{{.TerminalName}}</td></tr>
+{{end -}}
+</table>
+<hr>
+<table>
+{{range .Items -}}
+<tr><td colspan="2">{{.Name}}</td></tr>
+<tr><td class="indent">{{.Quantity}} x {{rupiah .Price}}</td><td class="amount">{{rupiah .Subtotal}}</td></tr>
+{{range .Discounts}}<tr><td class="indent">{{.Name}}</td><td class="amount">{{rupiah (neg .Amount)}}</td></tr>
+{{end -}}
+{{end -}}
+</table>
+<hr>
+<table>
+<tr><td>Subtotal</td><td class="amount">{{rupiah .Subtotal}}</td></tr>
+{{range .Discounts}}<tr><td>{{.Name}}</td><td class="amount">{{rupiah (neg .Amount)}}</td></tr>
+{{end -}}
+{{if .DiscountAmount}}<tr><td>Total Diskon</td><td class="amount">{{rupiah (neg .DiscountAmount)}}</td></tr>
+{{end -}}
+{{if .TaxAmount}}<tr><td>DPP</td><td class="amount">{{rupiah .TaxBase}}</td></tr>
+<tr><td>PPN{{if .PriceIncludesTax}} (termasuk){{end}}</td><td class="amount">{{rupiah .TaxAmount}}</td></tr>
+{{end -}}
+{{if .ServiceCharge}}<tr><td>Service</td><td class="amount">{{rupiah .ServiceCharge}}</td></tr>
+{{end -}}
+<tr class="total"><td>TOTAL</td><td class="amount">{{rupiah .TotalAmount}}</td></tr>
+{{range .Payments}}<tr><td>{{.Method}}{{if .Reference}} ({{.Reference}}){{end}}</td><td class="amount">{{rupiah .Amount}}</td></tr>
+{{end -}}
+<tr><td>Kembali</td><td class="amount">{{rupiah .ChangeAmount}}</td></tr>
+{{if .RefundedAmount}}<tr><td>Refund</td><td class="amount">{{rupiah (neg .RefundedAmount)}}</td></tr>
+{{end -}}
+</table>
+{{if .Footer}}<hr>
+{{range .Footer}}<div class="center">{{.}}</div>
+{{end}}{{end -}}
+{{end -}}
+</div>
+</body>
+</html>
+`))
+
+func renderReceiptHTML(r *models.Receipt, width int) ([]byte, error) {
+	var buf bytes.Buffer
+	err := receiptHTMLTemplate.Execute(&buf, struct {
+		Receipt *models.Receipt
+		Width   int
+	}{r, width})
+	return buf.Bytes(), err
+}
+
+// formatRupiah - 1234567 -> "1.234.567", -5000 -> "-5.000"
+func formatRupiah(n int) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+
+	digits := strconv.Itoa(n)
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String()
+}
+
+// amountLine - label rata kiri dan nominal rata kanan dalam satu baris. Label yang terlalu
+// panjang dipotong supaya nominal tetap terbaca.
+func amountLine(label string, amount, cols int) string {
+	value := formatRupiah(amount)
+	room := cols - utf8.RuneCountInString(value) - 1
+	if room < 0 {
+		room = 0
+	}
+	label = truncateText(label, room)
+	return padRight(label, cols-utf8.RuneCountInString(value)) + value
+}
+
+// wrapText - pecah teks per kata supaya muat cols karakter, kata yang lebih panjang dipotong paksa
+func wrapText(text string, cols int) []string {
+	var lines []string
+	current := ""
+	for word := range strings.FieldsSeq(text) {
+		for utf8.RuneCountInString(word) > cols {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:cols]))
+			word = string(runes[cols:])
+		}
+		switch {
+		case current == "":
+			current = word
+		case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= cols:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" || len(lines) == 0 {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// prefixedText - prefix di baris pertama, baris lanjutan menjorok selebar prefix
+func prefixedText(prefix, text string, cols int) []string {
+	indent := utf8.RuneCountInString(prefix)
+	lines := wrapText(text, max(cols-indent, 1))
+	for i, line := range lines {
+		if i == 0 {
+			lines[i] = prefix + line
+		} else {
+			lines[i] = strings.Repeat(" ", indent) + line
+		}
+	}
+	return lines
+}
+
+func centerText(text string, cols int) []string {
+	lines := wrapText(text, cols)
+	for i, line := range lines {
+		lines[i] = strings.Repeat(" ", (cols-utf8.RuneCountInString(line))/2) + line
+	}
+	return lines
+}
+
+func padRight(text string, cols int) string {
+	if n := utf8.RuneCountInString(text); n < cols {
+		return text + strings.Repeat(" ", cols-n)
+	}
+	return text
+}
+
+func truncateText(text string, cols int) string {
+	if runes := []rune(text); len(runes) > cols {
+		return string(runes[:cols])
+	}
+	return text
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"errors"
+	"strings"
+	"text/template"
+)
+
+type ReceiptService struct {
+	transactions repositories.TransactionRepository
+	users        repositories.UserRepository
+	terminals    repositories.TerminalRepository
+	config       models.ReceiptStoreConfig
+	printer      ReceiptPrinter
+}
+
+func NewReceiptService(transactions repositories.TransactionRepository, users repositories.UserRepository, terminals repositories.TerminalRepository, config models.ReceiptStoreConfig, printer ReceiptPrinter) *ReceiptService {
+	return &ReceiptService{transactions: transactions, users: users, terminals: terminals, config: config, printer: printer}
+}
+
+// Render - struk transaksi dalam format txt / html / pdf / escpos. width (58 / 80 mm) dipakai
+// layout txt, pdf dan escpos, 0 = 80 mm. Mengembalikan isi dan Content-Type.
+func (s *ReceiptService) Render(transactionID int, format string, width int) ([]byte, string, error) {
+	if width == 0 {
+		width = models.ReceiptWidth80
+	}
+	if width != models.ReceiptWidth58 && width != models.ReceiptWidth80 {
+		return nil, "", models.NewValidationError("width", "width harus 58 atau 80")
+	}
+
+	switch format {
+	case "", models.ReceiptFormatText, models.ReceiptFormatHTML, models.ReceiptFormatPDF, models.ReceiptFormatESCPOS:
+	default:
+		return nil, "", models.NewValidationError("format", "format harus txt, html, pdf atau escpos")
+	}
+
+	receipt, err := s.GetReceipt(transactionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch format {
+	case models.ReceiptFormatHTML:
+		content, err := renderReceiptHTML(receipt, width)
+		return content, "text/html; charset=utf-8", err
+	case models.ReceiptFormatPDF:
+		return renderReceiptPDF(receipt, width), "application/pdf", nil
+	case models.ReceiptFormatESCPOS:
+		return renderReceiptESCPOS(receipt, width, false), "application/octet-stream", nil
+	default:
+		return []byte(renderReceiptText(receipt, width)), "text/plain; charset=utf-8", nil
+	}
+}
+
+// Print - cetak ulang struk ke printer terminal transaksi. openDrawer ikut membuka laci kas.
+func (s *ReceiptService) Print(transactionID int, openDrawer bool) error {
+	t, err := s.transactions.GetByID(transactionID)
+	if err != nil {
+		return err
+	}
+
+	terminal, err := s.transactionTerminal(t)
+	if err != nil {
+		return err
+	}
+	if terminal == nil || terminal.PrinterAddress == "" {
+		return errNoPrinter
+	}
+
+	return s.print(t, terminal, openDrawer)
+}
+
+// AutoPrint - cetak struk setelah checkout berhasil jika terminal punya printer. Laci kas dibuka
+// jika ada pembayaran tunai.
+func (s *ReceiptService) AutoPrint(t *models.Transaction) error {
+	terminal, err := s.transactionTerminal(t)
+	if err != nil || terminal == nil || terminal.PrinterAddress == "" {
+		return err
+	}
+
+	openDrawer := false
+	for _, p := range t.Payments {
+		if p.Method == models.PaymentMethodCash {
+			openDrawer = true
+		}
+	}
+	return s.print(t, terminal, openDrawer)
+}
+
+func (s *ReceiptService) print(t *models.Transaction, terminal *models.Terminal, openDrawer bool) error {
+	receipt, err := s.receiptFor(t)
+	if err != nil {
+		return err
+	}
+	return s.printer.Print(terminal.PrinterAddress, renderReceiptESCPOS(receipt, terminal.PrinterWidth, openDrawer))
+}
+
+// transactionTerminal - nil jika transaksi tanpa terminal atau terminalnya sudah tidak ada
+func (s *ReceiptService) transactionTerminal(t *models.Transaction) (*models.Terminal, error) {
+	if t.TerminalID == nil {
+		return nil, nil
+	}
+	terminal, err := s.terminals.GetByID(*t.TerminalID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, nil
+	}
+	return terminal, err
+}
+
+// GetReceipt - susun isi struk dari transaksi, nama kasir / terminal dan template toko
+func (s *ReceiptService) GetReceipt(transactionID int) (*models.Receipt, error) {
+	t, err := s.transactions.GetByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.receiptFor(t)
+}
+
+func (s *ReceiptService) receiptFor(t *models.Transaction) (*models.Receipt, error) {
+	var err error
+	receipt := &models.Receipt{
+		StoreName:        s.config.StoreName,
+		StoreAddress:     s.config.StoreAddress,
+		StorePhone:       s.config.StorePhone,
+		StoreNPWP:        s.config.StoreNPWP,
+		TransactionID:    t.ID,
+		ReceiptNumber:    t.ReceiptNumber,
+		CreatedAt:        t.CreatedAt,
+		Status:           t.Status,
+		Subtotal:         t.SubtotalAmount,
+		DiscountAmount:   t.DiscountAmount,
+		PriceIncludesTax: t.PriceIncludesTax,
+		TaxBase:          t.TaxBase,
+		TaxAmount:        t.TaxAmount,
+		ServiceCharge:    t.ServiceCharge,
+		TotalAmount:      t.TotalAmount,
+		ChangeAmount:     t.ChangeAmount,
+		RefundedAmount:   t.RefundedAmount,
+	}
+
+	// kasir / terminal yang sudah dihapus tetap bisa dicetak, hanya tanpa nama
+	if t.CashierID != nil {
+		user, err := s.users.GetByID(*t.CashierID)
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			return nil, err
+		}
+		if user != nil {
+			receipt.CashierName = user.Name
+		}
+	}
+	terminal, err := s.transactionTerminal(t)
+	if err != nil {
+		return nil, err
+	}
+	if terminal != nil {
+		receipt.TerminalName = terminal.Name
+	}
+
+	for _, d := range t.Details {
+		item := models.ReceiptItem{Name: d.ProductName, Quantity: d.Quantity, Price: d.ProductPrice, Subtotal: d.Subtotal}
+		for _, p := range d.Promotions {
+			item.Discounts = append(item.Discounts, models.ReceiptDiscount{Name: p.PromotionName, Amount: p.DiscountAmount})
+		}
+		receipt.Items = append(receipt.Items, item)
+	}
+	for _, p := range t.Promotions {
+		if p.ProductID == nil {
+			receipt.Discounts = append(receipt.Discounts, models.ReceiptDiscount{Name: p.PromotionName, Amount: p.DiscountAmount})
+		}
+	}
+
+	for _, p := range t.Payments {
+		payment := models.ReceiptPayment{Method: paymentMethodLabel(p.Method), Amount: p.Amount, Reference: p.Reference}
+		if p.Method == models.PaymentMethodCash {
+			payment.Amount = p.TenderedAmount
+		}
+		receipt.Payments = append(receipt.Payments, payment)
+	}
+
+	if receipt.Header, err = renderReceiptTemplate("header", s.config.HeaderTemplate, receipt); err != nil {
+		return nil, err
+	}
+	if receipt.Footer, err = renderReceiptTemplate("footer", s.config.FooterTemplate, receipt); err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+// renderReceiptTemplate - render template header / footer, baris kosong dibuang
+func renderReceiptTemplate(name, text string, receipt *models.Receipt) ([]string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, receipt); err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0)
+	for line := range strings.SplitSeq(b.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// ValidateReceiptTemplates - cek template header / footer saat start supaya salah ketik
+// tidak baru ketahuan ketika struk dicetak
+func ValidateReceiptTemplates(config models.ReceiptStoreConfig) error {
+	_, err := renderReceiptTemplate("header", config.HeaderTemplate, &models.Receipt{})
+	if err == nil {
+		_, err = renderReceiptTemplate("footer", config.FooterTemplate, &models.Receipt{})
+	}
+	return err
+}
+
+func paymentMethodLabel(method string) string {
+	switch method {
+	case models.PaymentMethodCash:
+		return "Tunai"
+	case models.PaymentMethodDebitCard:
+		return "Kartu Debit"
+	case models.PaymentMethodQRIS:
+		return "QRIS"
+	case models.PaymentMethodBankTransfer:
+		return "Transfer"
+	case models.PaymentMethodEWallet:
+		return "E-Wallet"
+	}
+	return method
+}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestReceiptRender(t *testing.T) {
+	env := newTestEnv(t, models.TaxConfig{Mode: models.TaxModeExclusive, DefaultRate: 11})
+
+	if err := env.promotions.Create(&models.Promotion{
+		Name:       "Diskon Teh 10%",
+		Type:       models.PromotionTypePercentage,
+		Value:      10,
+		ProductIDs: []int{env.tea.ID},
+		Active:     true,
+	}); err != nil {
+		t.Fatalf("create promotion: %v", err)
+	}
+
+	transaction, _, err := env.checkout(models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 2}, {ProductID: env.bread.ID, Quantity: 1}},
+		Payments: cash(50000),
+	}, true)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	receipts := NewReceiptService(
+		repositories.NewMemoryTransactionRepository(env.store, noTax, testReceiptConfig),
+		repositories.NewMemoryUserRepository(env.store),
+		repositories.NewMemoryTerminalRepository(env.store),
+		models.ReceiptStoreConfig{
+			StoreName:      "Toko <Maju> Jaya",
+			StoreAddress:   "Jl. Merdeka No. 1, Bandung",
+			StoreNPWP:      "01.234.567.8-901.000",
+			HeaderTemplate: models.DefaultReceiptHeaderTemplate,
+			FooterTemplate: "Terima kasih {{.CashierName}}",
+		},
+		NetworkPrinter{Timeout: time.Second},
+	)
+
+	want58 := `        Toko <Maju> Jaya
+   Jl. Merdeka No. 1, Bandung
+   NPWP 01.234.567.8-901.000
+--------------------------------
+No   : INV/OUTLET1/20261018/0001
+Tgl  : 18-10-2026 10:00
+Kasir: Kasir Satu
+POS  : Kasir depan
+--------------------------------
+Es Teh
+  2 x 5.000               10.000
+  Diskon Teh 10%          -1.000
+Roti
+  1 x 12.000              12.000
+--------------------------------
+Subtotal                  22.000
+Total Diskon              -1.000
+DPP                       21.000
+PPN                        2.310
+TOTAL                     23.310
+Tunai                     50.000
+Kembali                   26.690
+--------------------------------
+    Terima kasih Kasir Satu
+`
+
+	tests := []struct {
+		name        string
+		format      string
+		width       int
+		contentType string
+		check       func(t *testing.T, content string)
+	}{
+		{
+			name: "teks 58 mm", format: models.ReceiptFormatText, width: 58, contentType: "text/plain; charset=utf-8",
+			check: func(t *testing.T, content string) {
+				if content != want58 {
+					t.Errorf("struk =\n%s\nwant\n%s", content, want58)
+				}
+			},
+		},
+		{
+			name: "teks default 80 mm", contentType: "text/plain; charset=utf-8",
+			check: func(t *testing.T, content string) {
+				for line := range strings.SplitSeq(strings.TrimSuffix(content, "\n"), "\n") {
+					if n := utf8.RuneCountInString(line); n > 48 {
+						t.Errorf("baris %q %d karakter, maksimal 48", line, n)
+					}
+				}
+				if !strings.Contains(content, "TOTAL"+strings.Repeat(" ", 37)+"23.310\n") {
+					t.Errorf("baris TOTAL tidak rata kanan 48 kolom:\n%s", content)
+				}
+			},
+		},
+		{
+			name: "html", format: models.ReceiptFormatHTML, contentType: "text/html; charset=utf-8",
+			check: func(t *testing.T, content string) {
+				for _, want := range []string{"Toko &lt;Maju&gt; Jaya", "width: 80mm", "INV/OUTLET1/20261018/0001", "Diskon Teh 10%", "23.310"} {
+					if !strings.Contains(content, want) {
+						t.Errorf("html tidak mengandung %q", want)
+					}
+				}
+			},
+		},
+		{
+			name: "pdf", format: models.ReceiptFormatPDF, width: 58, contentType: "application/pdf",
+			check: func(t *testing.T, content string) {
+				if !strings.HasPrefix(content, "%PDF-1.4\n") || !strings.HasSuffix(content, "%%EOF\n") {
+					t.Errorf("bukan dokumen PDF: %.40q", content)
+				}
+				for _, want := range []string{"/BaseFont /Courier", "(No   : INV/OUTLET1/20261018/0001)", "(TOTAL                     23.310)"} {
+					if !strings.Contains(content, want) {
+						t.Errorf("pdf tidak mengandung %q", want)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, contentType, err := receipts.Render(transaction.ID, tt.format, tt.width)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if contentType != tt.contentType {
+				t.Errorf("content type = %q, want %q", contentType, tt.contentType)
+			}
+			tt.check(t, string(content))
+		})
+	}
+
+	t.Run("transaksi void", func(t *testing.T) {
+		if _, err := env.transactions.Void(transaction.ID, models.VoidRequest{Reason: "salah input"}); err != nil {
+			t.Fatalf("Void: %v", err)
+		}
+		content, _, err := receipts.Render(transaction.ID, models.ReceiptFormatText, 58)
+		if err != nil {
+			t.Fatalf("Render: %v", err)
+		}
+		if !strings.Contains(string(content), "          *** VOID ***\n") {
+			t.Errorf("struk void tanpa tanda VOID:\n%s", content)
+		}
+	})
+
+	errorTests := []struct {
+		name   string
+		id     int
+		format string
+		width  int
+		want   error
+	}{
+		{name: "format tidak dikenal", id: transaction.ID, format: "doc", want: models.ErrValidation},
+		{name: "lebar tidak didukung", id: transaction.ID, width: 76, want: models.ErrValidation},
+		{name: "transaksi tidak ada", id: 99, want: models.ErrNotFound},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := receipts.Render(tt.id, tt.format, tt.width); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReceiptTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  models.ReceiptStoreConfig
+		wantErr bool
+	}{
+		{name: "template bawaan", config: models.ReceiptStoreConfig{HeaderTemplate: models.DefaultReceiptHeaderTemplate, FooterTemplate: models.DefaultReceiptFooterTemplate}},
+		{name: "sintaks salah", config: models.ReceiptStoreConfig{HeaderTemplate: "{{.StoreName"}, wantErr: true},
+		{name: "field tidak ada", config: models.ReceiptStoreConfig{FooterTemplate: "{{.Alamat}}"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateReceiptTemplates(tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatRupiah(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0"},
+		{500, "500"},
+		{5000, "5.000"},
+		{1234567, "1.234.567"},
+		{-26690, "-26.690"},
+	}
+
+	for _, tt := range tests {
+		if got := formatRupiah(tt.n); got != tt.want {
+			t.Errorf("formatRupiah(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestReceiptESCPOS(t *testing.T) {
+	receipt := &models.Receipt{
+		Header:        []string{"Toko Maju"},
+		ReceiptNumber: "INV/OUTLET1/20261018/0001",
+		Status:        models.TransactionStatusCompleted,
+		Items:         []models.ReceiptItem{{Name: "Es Teh", Quantity: 1, Price: 5000, Subtotal: 5000}},
+		Subtotal:      5000,
+		TotalAmount:   5000,
+	}
+
+	tests := []struct {
+		name       string
+		width      int
+		openDrawer bool
+	}{
+		{name: "80 mm", width: 80},
+		{name: "58 mm buka laci", width: 58, openDrawer: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := renderReceiptESCPOS(receipt, tt.width, tt.openDrawer)
+
+			if !bytes.HasPrefix(data, escposInit) {
+				t.Errorf("tidak diawali ESC @: % x", data[:min(len(data), 8)])
+			}
+			if !bytes.HasSuffix(data, escposFeedCut) {
+				t.Errorf("tidak diakhiri feed dan cut")
+			}
+			if got := bytes.Contains(data, escposDrawerKick); got != tt.openDrawer {
+				t.Errorf("drawer kick = %v, want %v", got, tt.openDrawer)
+			}
+			if !bytes.Contains(data, append(append([]byte{}, escposBoldOn...), centerText("Toko Maju", receiptColumns(tt.width))[0]...)) {
+				t.Errorf("nama toko tidak dicetak bold")
+			}
+			if !bytes.Contains(data, append([]byte{0x31, 0x50, 0x30}, receipt.ReceiptNumber...)) {
+				t.Errorf("QR code tidak berisi nomor struk")
+			}
+		})
+	}
+}
+
+// recordingPrinter - printer jaringan palsu, menampung byte yang dikirim per alamat
+type recordingPrinter struct {
+	printed map[string][]byte
+}
+
+func (p *recordingPrinter) Print(address string, data []byte) error {
+	p.printed[address] = data
+	return nil
+}
+
+func TestReceiptPrint(t *testing.T) {
+	env := newTestEnv(t, noTax)
+	terminals := repositories.NewMemoryTerminalRepository(env.store)
+	printer := &recordingPrinter{printed: map[string][]byte{}}
+	receipts := NewReceiptService(
+		repositories.NewMemoryTransactionRepository(env.store, noTax, testReceiptConfig),
+		repositories.NewMemoryUserRepository(env.store),
+		terminals,
+		models.ReceiptStoreConfig{HeaderTemplate: models.DefaultReceiptHeaderTemplate},
+		printer,
+	)
+
+	transaction, _, err := env.checkout(models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}},
+		Payments: cash(5000),
+	}, true)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	// terminal belum punya printer: auto print dilewati, cetak ulang ditolak
+	if err := receipts.AutoPrint(transaction); err != nil || len(printer.printed) > 0 {
+		t.Fatalf("AutoPrint tanpa printer: err = %v, printed = %d", err, len(printer.printed))
+	}
+	if err := receipts.Print(transaction.ID, false); !errors.Is(err, models.ErrConflict) {
+		t.Fatalf("Print tanpa printer: err = %v, want %v", err, models.ErrConflict)
+	}
+
+	env.terminal.PrinterAddress = "192.168.1.50:9100"
+	env.terminal.PrinterWidth = models.ReceiptWidth58
+	if err := terminals.Update(env.terminal); err != nil {
+		t.Fatalf("update terminal: %v", err)
+	}
+
+	if err := receipts.AutoPrint(transaction); err != nil {
+		t.Fatalf("AutoPrint: %v", err)
+	}
+	data := printer.printed["192.168.1.50:9100"]
+	if !bytes.Contains(data, escposDrawerKick) {
+		t.Errorf("pembayaran tunai harus membuka laci kas")
+	}
+	if !bytes.Contains(data, []byte(strings.Repeat("-", 32)+"\n")) {
+		t.Errorf("layout bukan 58 mm")
+	}
+
+	if err := receipts.Print(transaction.ID, false); err != nil {
+		t.Fatalf("Print: %v", err)
+	}
+	if bytes.Contains(printer.printed["192.168.1.50:9100"], escposDrawerKick) {
+		t.Errorf("cetak ulang tanpa open_drawer membuka laci kas")
+	}
+	if err := receipts.Print(99, false); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Print transaksi tidak ada: err = %v, want %v", err, models.ErrNotFound)
+	}
+}
+
+func TestNetworkPrinter(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	printer := NetworkPrinter{Timeout: time.Second}
+	if err := printer.Print(listener.Addr().String(), []byte("struk")); err != nil {
+		t.Fatalf("Print: %v", err)
+	}
+	if got := string(<-received); got != "struk" {
+		t.Errorf("printer menerima %q, want %q", got, "struk")
+	}
+
+	// printer mati
+	address := listener.Addr().String()
+	listener.Close()
+	if err := printer.Print(address, []byte("struk")); !errors.Is(err, models.ErrPrinterUnavailable) {
+		t.Errorf("err = %v, want %v", err, models.ErrPrinterUnavailable)
+	}
+}
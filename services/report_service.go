@@ -7,10 +7,10 @@ import (
 )
 
 type ReportService struct {
-	reportRepo *repositories.ReportRepository
+	reportRepo repositories.ReportRepository
 }
 
-func NewReportService(reportRepo *repositories.ReportRepository) *ReportService {
+func NewReportService(reportRepo repositories.ReportRepository) *ReportService {
 	return &ReportService{reportRepo: reportRepo}
 }
 
@@ -19,5 +19,84 @@ func (service *ReportService) GetDailyReport() (*models.DailyReport, error) {
 }
 
 func (service *ReportService) GetReportByDateRange(startDate, endDate time.Time) (*models.DateRangeReport, error) {
+	if endDate.Before(startDate) {
+		return nil, models.NewValidationError("end_date", "end_date tidak boleh sebelum start_date")
+	}
 	return service.reportRepo.GetReportByDateRange(startDate, endDate)
 }
+
+// GetCashierReport - startDate dan endDate diisi keduanya, atau nil keduanya untuk hari ini
+func (service *ReportService) GetCashierReport(startDate, endDate *time.Time) ([]models.CashierSalesReport, error) {
+	if err := validateOptionalDateRange(startDate, endDate); err != nil {
+		return nil, err
+	}
+	return service.reportRepo.GetCashierReport(startDate, endDate)
+}
+
+// GetProductSalesReport - aturan tanggal sama dengan laporan kasir
+func (service *ReportService) GetProductSalesReport(startDate, endDate *time.Time) ([]models.ProductSalesTotal, error) {
+	if err := validateOptionalDateRange(startDate, endDate); err != nil {
+		return nil, err
+	}
+	return service.reportRepo.GetProductSalesReport(startDate, endDate)
+}
+
+// GetComponentConsumption - pemakaian komponen resep, aturan tanggal sama dengan laporan kasir
+func (service *ReportService) GetComponentConsumption(startDate, endDate *time.Time) ([]models.ComponentConsumption, error) {
+	if err := validateOptionalDateRange(startDate, endDate); err != nil {
+		return nil, err
+	}
+	return service.reportRepo.GetComponentConsumption(startDate, endDate)
+}
+
+// validateOptionalDateRange - startDate dan endDate diisi keduanya, atau nil keduanya untuk hari ini
+func validateOptionalDateRange(startDate, endDate *time.Time) error {
+	if (startDate == nil) != (endDate == nil) {
+		return models.NewValidationError("start_date", "start_date dan end_date harus diisi bersamaan")
+	}
+	if startDate != nil && endDate.Before(*startDate) {
+		return models.NewValidationError("end_date", "end_date tidak boleh sebelum start_date")
+	}
+	return nil
+}
+
+// CreateEndOfDayReport - X report (tengah hari, tidak menutup) atau Z report (tutup hari).
+// BusinessDate kosong = hari ini.
+func (service *ReportService) CreateEndOfDayReport(reportType string, req models.EndOfDayRequest) (*models.EndOfDayReport, error) {
+	if req.CreatedBy <= 0 {
+		return nil, &models.UnauthorizedError{Message: "user tidak diketahui, silakan login"}
+	}
+	if reportType != models.EndOfDayReportX && reportType != models.EndOfDayReportZ {
+		return nil, models.NewValidationError("type", "type harus X atau Z")
+	}
+
+	businessDate, err := parseBusinessDate(req.BusinessDate)
+	if err != nil {
+		return nil, err
+	}
+	return service.reportRepo.CreateEndOfDayReport(reportType, businessDate, req.CreatedBy)
+}
+
+// GetEndOfDayReports - reportType kosong = X dan Z, businessDate kosong = semua tanggal
+func (service *ReportService) GetEndOfDayReports(reportType, businessDate string) ([]models.EndOfDayReport, error) {
+	if reportType != "" && reportType != models.EndOfDayReportX && reportType != models.EndOfDayReportZ {
+		return nil, models.NewValidationError("type", "type harus X atau Z")
+	}
+
+	date, err := parseBusinessDate(businessDate)
+	if err != nil {
+		return nil, err
+	}
+	return service.reportRepo.GetEndOfDayReports(reportType, date)
+}
+
+func parseBusinessDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, models.NewValidationError("business_date", "format business_date harus YYYY-MM-DD")
+	}
+	return &date, nil
+}
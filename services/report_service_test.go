@@ -0,0 +1,519 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReports(t *testing.T) {
+	env := newTestEnv(t, models.TaxConfig{Mode: models.TaxModeExclusive, DefaultRate: 10})
+
+	checkout := func(items []models.CheckoutItem, payments []models.PaymentInput) *models.Transaction {
+		t.Helper()
+		transaction, _, err := env.checkout(models.CheckoutRequest{Items: items, Payments: payments}, true)
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		return transaction
+	}
+
+	// 3 teh = 15000 + PPN 1500
+	first := checkout([]models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 3}}, cash(20000))
+	// 1 roti = 12000 + PPN 1200, split QRIS + cash
+	checkout([]models.CheckoutItem{{ProductID: env.bread.ID, Quantity: 1}}, []models.PaymentInput{
+		{Method: models.PaymentMethodQRIS, Amount: 10000},
+		{Method: models.PaymentMethodCash, Amount: 5000},
+	})
+	// di-void, tidak dihitung
+	voided := checkout([]models.CheckoutItem{{ProductID: env.bread.ID, Quantity: 2}}, cash(30000))
+	if _, err := env.transactions.Void(voided.ID, models.VoidRequest{Reason: "salah input"}); err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+	// refund 1 teh = 5500
+	_, err := env.transactions.Refund(first.ID, models.RefundRequest{
+		Reason: "tumpah",
+		Items:  []models.RefundItemRequest{{TransactionDetailID: first.Details[0].ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+
+	today := env.store.Now()
+	yesterday := today.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name  string
+		get   func() (*models.DateRangeReport, error)
+		want  models.DateRangeReport
+		empty bool
+	}{
+		{
+			name: "laporan hari ini",
+			get: func() (*models.DateRangeReport, error) {
+				daily, err := env.reports.GetDailyReport()
+				if err != nil {
+					return nil, err
+				}
+				report := models.DateRangeReport(*daily)
+				return &report, nil
+			},
+			want: models.DateRangeReport{
+				GrossSales:     27000,
+				DPP:            27000,
+				TotalPajak:     2700,
+				TotalRefund:    5500,
+				PajakRefund:    500,
+				NetRevenue:     29700 - 5500,
+				TotalTransaksi: 2,
+				ProdukTerlaris: models.BestSellingProduct{Nama: "Es Teh", QtyTerjual: 3},
+			},
+		},
+		{
+			name: "rentang tanggal kemarin kosong",
+			get: func() (*models.DateRangeReport, error) {
+				return env.reports.GetReportByDateRange(yesterday, yesterday)
+			},
+			empty: true,
+		},
+		{
+			name: "rentang tanggal mencakup hari ini",
+			get: func() (*models.DateRangeReport, error) {
+				return env.reports.GetReportByDateRange(yesterday, today.Add(24*time.Hour))
+			},
+			want: models.DateRangeReport{
+				GrossSales:     27000,
+				DPP:            27000,
+				TotalPajak:     2700,
+				TotalRefund:    5500,
+				PajakRefund:    500,
+				NetRevenue:     29700 - 5500,
+				TotalTransaksi: 2,
+				ProdukTerlaris: models.BestSellingProduct{Nama: "Es Teh", QtyTerjual: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := tt.get()
+			if err != nil {
+				t.Fatalf("report: %v", err)
+			}
+
+			if tt.empty {
+				if report.TotalTransaksi != 0 || report.NetRevenue != 0 || len(report.Pembayaran) != 0 {
+					t.Errorf("report = %+v, want empty", report)
+				}
+				return
+			}
+
+			got := *report
+			got.Pembayaran, got.Promosi, got.Pajak = nil, nil, nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("report = %+v\nwant     %+v", got, tt.want)
+			}
+
+			wantPayments := []models.PaymentMethodTotal{
+				{Method: models.PaymentMethodCash, TotalTransaksi: 2, Amount: 16500 + 3200},
+				{Method: models.PaymentMethodQRIS, TotalTransaksi: 1, Amount: 10000},
+			}
+			if len(report.Pembayaran) != len(wantPayments) {
+				t.Fatalf("pembayaran = %+v", report.Pembayaran)
+			}
+			for i, want := range wantPayments {
+				if report.Pembayaran[i] != want {
+					t.Errorf("pembayaran[%d] = %+v, want %+v", i, report.Pembayaran[i], want)
+				}
+			}
+
+			if len(report.Pajak) != 1 || report.Pajak[0].Rate != 10 || report.Pajak[0].TaxAmount != 2700 {
+				t.Errorf("pajak = %+v", report.Pajak)
+			}
+		})
+	}
+}
+
+func TestBestSellerRenamedProduct(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	checkout := func(productID, quantity int) {
+		t.Helper()
+		_, _, err := env.checkout(models.CheckoutRequest{
+			Items:    []models.CheckoutItem{{ProductID: productID, Quantity: quantity}},
+			Payments: cash(100000),
+		}, true)
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+	}
+
+	// teh terjual 2 + 2 dengan dua nama berbeda, roti 3: teh tetap terlaris
+	checkout(env.tea.ID, 2)
+	env.tea.Name = "Teh Manis"
+	if err := env.products.Update(env.tea); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	checkout(env.tea.ID, 2)
+	checkout(env.bread.ID, 3)
+
+	daily, err := env.reports.GetDailyReport()
+	if err != nil {
+		t.Fatalf("GetDailyReport: %v", err)
+	}
+	want := models.BestSellingProduct{Nama: "Teh Manis", QtyTerjual: 4}
+	if daily.ProdukTerlaris != want {
+		t.Errorf("produk terlaris = %+v, want %+v", daily.ProdukTerlaris, want)
+	}
+}
+
+func TestCashierReport(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	second := &models.User{Username: "kasir2", Name: "Kasir Dua", Role: models.RoleCashier, Active: true}
+	if err := repositories.NewMemoryUserRepository(env.store).Create(second); err != nil {
+		t.Fatalf("create cashier: %v", err)
+	}
+
+	checkout := func(cashierID, qty int, payments []models.PaymentInput) *models.Transaction {
+		t.Helper()
+		transaction, _, err := env.checkout(models.CheckoutRequest{
+			CashierID: cashierID,
+			Items:     []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: qty}},
+			Payments:  payments,
+		}, true)
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		return transaction
+	}
+
+	checkout(env.cashier.ID, 2, cash(20000)) // kembalian 10000, kas 10000
+	voided := checkout(env.cashier.ID, 1, cash(5000))
+	if _, err := env.transactions.Void(voided.ID, models.VoidRequest{Reason: "salah input"}); err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+	refunded := checkout(second.ID, 3, []models.PaymentInput{{Method: models.PaymentMethodQRIS, Amount: 15000}})
+	if _, err := env.transactions.Refund(refunded.ID, models.RefundRequest{
+		Reason: "tumpah",
+		Items:  []models.RefundItemRequest{{TransactionDetailID: refunded.Details[0].ID, Quantity: 1}},
+	}); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+
+	report, err := env.reports.GetCashierReport(nil, nil)
+	if err != nil {
+		t.Fatalf("GetCashierReport: %v", err)
+	}
+
+	terminalID := env.terminal.ID
+	want := []models.CashierSalesReport{
+		{
+			CashierID: &env.cashier.ID, CashierName: "Kasir Satu", TerminalID: &terminalID, TerminalName: "Kasir depan",
+			TotalTransaksi: 1, GrossSales: 10000, NetSales: 10000, TotalVoid: 1, VoidAmount: 5000, CashAmount: 10000,
+		},
+		{
+			CashierID: &second.ID, CashierName: "Kasir Dua", TerminalID: &terminalID, TerminalName: "Kasir depan",
+			TotalTransaksi: 1, GrossSales: 15000, NetSales: 15000, TotalRefund: 5000,
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report = %+v\nwant     %+v", report, want)
+	}
+
+	yesterday := env.store.Now().AddDate(0, 0, -1)
+	if report, err := env.reports.GetCashierReport(&yesterday, &yesterday); err != nil || len(report) != 0 {
+		t.Errorf("report kemarin = %+v, %v, want empty", report, err)
+	}
+	if _, err := env.reports.GetCashierReport(&yesterday, nil); !errors.Is(err, models.ErrValidation) {
+		t.Errorf("hanya start_date: err = %v, want validation", err)
+	}
+}
+
+func TestEndOfDayReports(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	categories := repositories.NewMemoryProductCategoryRepository(env.store)
+	drinks, snacks := &models.ProductCategory{Name: "Minuman"}, &models.ProductCategory{Name: "Camilan"}
+	for _, c := range []*models.ProductCategory{drinks, snacks} {
+		if err := categories.Create(c); err != nil {
+			t.Fatalf("create category: %v", err)
+		}
+	}
+	coffee := &models.Product{Name: "Kopi", Price: 8000, Stock: 10, CategoryID: &drinks.ID}
+	if err := env.products.Create(coffee); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	checkout := func(items []models.CheckoutItem, payments []models.PaymentInput) *models.Transaction {
+		t.Helper()
+		transaction, _, err := env.checkout(models.CheckoutRequest{Items: items, Payments: payments}, true)
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		return transaction
+	}
+
+	first := checkout([]models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 2}}, cash(20000))
+	qris := checkout([]models.CheckoutItem{{ProductID: coffee.ID, Quantity: 1}}, []models.PaymentInput{{Method: models.PaymentMethodQRIS, Amount: 8000}})
+	voided := checkout([]models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}}, cash(5000))
+	if _, err := env.transactions.Void(voided.ID, models.VoidRequest{Reason: "salah input"}); err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+
+	create := func(reportType, date string) (*models.EndOfDayReport, error) {
+		return env.reports.CreateEndOfDayReport(reportType, models.EndOfDayRequest{BusinessDate: date, CreatedBy: env.cashier.ID})
+	}
+
+	x1, err := create(models.EndOfDayReportX, "")
+	if err != nil {
+		t.Fatalf("X report: %v", err)
+	}
+	if x1.Sequence != 1 || x1.NetSales != 18000 || x1.TotalTransaksi != 2 || x1.TotalVoid != 1 || x1.VoidAmount != 5000 ||
+		x1.FirstTransactionID == nil || *x1.FirstTransactionID != first.ID || x1.LastTransactionID == nil || *x1.LastTransactionID != voided.ID {
+		t.Errorf("X report = %+v", x1)
+	}
+	if x1.FirstReceiptNumber != "INV/OUTLET1/20261018/0001" || x1.LastReceiptNumber != "INV/OUTLET1/20261018/0003" {
+		t.Errorf("nomor struk = %q - %q", x1.FirstReceiptNumber, x1.LastReceiptNumber)
+	}
+	wantCategories := []models.CategorySalesTotal{
+		{Quantity: 2, GrossSales: 10000, NetSales: 10000},
+		{CategoryID: &drinks.ID, CategoryName: "Minuman", Quantity: 1, GrossSales: 8000, NetSales: 8000},
+	}
+	if !reflect.DeepEqual(x1.Kategori, wantCategories) {
+		t.Errorf("kategori = %+v\nwant       %+v", x1.Kategori, wantCategories)
+	}
+
+	// kategori diambil dari snapshot transaksi, bukan kategori produk saat ini
+	coffee.CategoryID = &snacks.ID
+	if err := env.products.Update(coffee); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	// X report tidak menutup hari, transaksi berikutnya masuk X report selanjutnya
+	if _, err := env.transactions.Refund(first.ID, models.RefundRequest{
+		Reason: "tumpah",
+		Items:  []models.RefundItemRequest{{TransactionDetailID: first.Details[0].ID, Quantity: 1}},
+	}); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	x2, err := create(models.EndOfDayReportX, "2026-10-18")
+	if err != nil {
+		t.Fatalf("X report: %v", err)
+	}
+	if x2.Sequence != 2 || x2.TotalRefund != 5000 || x2.NetRevenue != 13000 || x2.NetSales != 18000 {
+		t.Errorf("X report kedua = %+v", x2)
+	}
+	if !reflect.DeepEqual(x2.Kategori, wantCategories) {
+		t.Errorf("kategori setelah produk pindah = %+v\nwant                        %+v", x2.Kategori, wantCategories)
+	}
+
+	if _, err := create(models.EndOfDayReportZ, ""); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("Z dengan shift terbuka: err = %v, want conflict", err)
+	}
+
+	counted := 105000
+	if _, err := env.shifts.Close(env.shift.ID, models.CloseShiftRequest{CountedCash: &counted, ClosedBy: env.cashier.ID}); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	z, err := create(models.EndOfDayReportZ, "")
+	if err != nil {
+		t.Fatalf("Z report: %v", err)
+	}
+	if z.Type != models.EndOfDayReportZ || z.Sequence != 1 || z.NetRevenue != 13000 || !z.BusinessDate.Equal(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Z report = %+v", z)
+	}
+
+	reports, err := env.reports.GetEndOfDayReports("", "2026-10-18")
+	if err != nil {
+		t.Fatalf("GetEndOfDayReports: %v", err)
+	}
+	if len(reports) != 3 || reports[0].ID != x2.ID || reports[1].ID != x1.ID || reports[2].ID != z.ID {
+		t.Errorf("reports = %+v, want X2, X1, Z", reports)
+	}
+	if reports[1].TotalRefund != 0 {
+		t.Errorf("X report pertama berubah = %+v", reports[1])
+	}
+
+	t.Run("setelah Z report", func(t *testing.T) {
+		if _, err := env.shifts.Open(models.OpenShiftRequest{TerminalID: env.terminal.ID, CashierID: env.cashier.ID}); err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+
+		if _, err := create(models.EndOfDayReportZ, ""); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Z kedua: err = %v, want conflict", err)
+		}
+		if _, _, err := env.checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}}, Payments: cash(5000)}, true); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Checkout err = %v, want conflict", err)
+		}
+		if _, err := env.transactions.Refund(qris.ID, models.RefundRequest{Reason: "basi"}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Refund err = %v, want conflict", err)
+		}
+		if _, err := create(models.EndOfDayReportX, ""); err != nil {
+			t.Errorf("X setelah Z: %v", err)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		reportType string
+		req        models.EndOfDayRequest
+		want       error
+	}{
+		{name: "tanpa login", reportType: models.EndOfDayReportX, want: models.ErrUnauthorized},
+		{name: "type tidak dikenal", reportType: "Y", req: models.EndOfDayRequest{CreatedBy: env.cashier.ID}, want: models.ErrValidation},
+		{name: "format tanggal salah", reportType: models.EndOfDayReportX, req: models.EndOfDayRequest{BusinessDate: "18/10/2026", CreatedBy: env.cashier.ID}, want: models.ErrValidation},
+		{name: "tanggal besok", reportType: models.EndOfDayReportZ, req: models.EndOfDayRequest{BusinessDate: "2026-10-19", CreatedBy: env.cashier.ID}, want: models.ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := env.reports.CreateEndOfDayReport(tt.reportType, tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductSalesReport(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	env.tea.Options = []models.ProductOption{{Name: "Ukuran", Values: []string{"S", "L"}}}
+	if err := env.products.Update(env.tea); err != nil {
+		t.Fatalf("update parent: %v", err)
+	}
+	small := &models.Product{ParentID: &env.tea.ID, OptionValues: []string{"S"}, Price: 5000, Stock: 10}
+	large := &models.Product{ParentID: &env.tea.ID, OptionValues: []string{"L"}, Price: 8000, Stock: 10}
+	for _, p := range []*models.Product{small, large} {
+		if err := env.products.Create(p); err != nil {
+			t.Fatalf("create variant: %v", err)
+		}
+	}
+
+	// produk induk yang punya varian tidak bisa dijual langsung
+	_, _, err := env.checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}}, Payments: cash(5000)}, true)
+	if !errors.Is(err, models.ErrValidation) {
+		t.Fatalf("checkout parent: err = %v, want %v", err, models.ErrValidation)
+	}
+
+	checkouts := [][]models.CheckoutItem{
+		{{ProductID: small.ID, Quantity: 1}, {ProductID: large.ID, Quantity: 2}, {ProductID: env.bread.ID, Quantity: 2}},
+		{{ProductID: small.ID, Quantity: 1}},
+	}
+	for _, items := range checkouts {
+		if _, _, err := env.checkout(models.CheckoutRequest{Items: items, Payments: cash(100000)}, true); err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+	}
+	if got, _ := env.products.GetByID(small.ID); got.Stock != 8 {
+		t.Errorf("variant stock = %d, want 8", got.Stock)
+	}
+
+	report, err := env.reports.GetProductSalesReport(nil, nil)
+	if err != nil {
+		t.Fatalf("GetProductSalesReport: %v", err)
+	}
+	want := []models.ProductSalesTotal{
+		{ProductID: env.tea.ID, ProductName: "Es Teh", Quantity: 4, GrossSales: 26000, NetSales: 26000, Variants: []models.ProductSalesTotal{
+			{ProductID: small.ID, ProductName: "Es Teh S", Quantity: 2, GrossSales: 10000, NetSales: 10000},
+			{ProductID: large.ID, ProductName: "Es Teh L", Quantity: 2, GrossSales: 16000, NetSales: 16000},
+		}},
+		{ProductID: env.bread.ID, ProductName: "Roti", Quantity: 2, GrossSales: 24000, NetSales: 24000},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report = %+v\nwant %+v", report, want)
+	}
+
+	// produk terlaris dihitung per induk: 4 teh (2 S + 2 L) mengalahkan 2 roti
+	daily, err := env.reports.GetDailyReport()
+	if err != nil {
+		t.Fatalf("GetDailyReport: %v", err)
+	}
+	if daily.ProdukTerlaris != (models.BestSellingProduct{Nama: "Es Teh", QtyTerjual: 4}) {
+		t.Errorf("produk terlaris = %+v, want Es Teh 4", daily.ProdukTerlaris)
+	}
+
+	// nama dari snapshot transaksi: rename induk dan varian tidak mengubah laporan yang sudah lewat
+	env.tea.Name = "Teh Tarik"
+	small.Name = "Teh Tarik Kecil"
+	for _, p := range []*models.Product{env.tea, small} {
+		if err := env.products.Update(p); err != nil {
+			t.Fatalf("rename: %v", err)
+		}
+	}
+	if report, _ := env.reports.GetProductSalesReport(nil, nil); !reflect.DeepEqual(report, want) {
+		t.Errorf("report setelah rename = %+v\nwant %+v", report, want)
+	}
+	if daily, _ := env.reports.GetDailyReport(); daily.ProdukTerlaris.Nama != "Es Teh" {
+		t.Errorf("produk terlaris setelah rename = %+v, want Es Teh", daily.ProdukTerlaris)
+	}
+
+	yesterday := env.store.Now().AddDate(0, 0, -1)
+	if _, err := env.reports.GetProductSalesReport(&yesterday, nil); !errors.Is(err, models.ErrValidation) {
+		t.Errorf("hanya start_date: err = %v, want %v", err, models.ErrValidation)
+	}
+}
+
+func TestComponentConsumption(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	milk := &models.Product{Name: "Susu", Stock: 1000}
+	cup := &models.Product{Name: "Cup", Stock: 20}
+	for _, p := range []*models.Product{milk, cup} {
+		if err := env.products.Create(p); err != nil {
+			t.Fatalf("create component: %v", err)
+		}
+	}
+	latte := &models.Product{Name: "Es Kopi Susu", Price: 20000, Recipe: []models.RecipeItem{
+		{ComponentID: milk.ID, Quantity: 150}, {ComponentID: cup.ID, Quantity: 1},
+	}}
+	if err := env.products.Create(latte); err != nil {
+		t.Fatalf("create composite: %v", err)
+	}
+
+	checkout := func(quantity int) *models.Transaction {
+		t.Helper()
+		transaction, _, err := env.checkout(models.CheckoutRequest{
+			Items:    []models.CheckoutItem{{ProductID: latte.ID, Quantity: quantity}, {ProductID: env.tea.ID, Quantity: 1}},
+			Payments: cash(100000),
+		}, true)
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		return transaction
+	}
+
+	// 3 + 2 latte, yang kedua di-void; 1 dari transaksi pertama direfund
+	first := checkout(3)
+	voided := checkout(2)
+	if _, err := env.transactions.Void(voided.ID, models.VoidRequest{Reason: "batal"}); err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+	_, err := env.transactions.Refund(first.ID, models.RefundRequest{
+		Reason: "tumpah",
+		Items:  []models.RefundItemRequest{{TransactionDetailID: first.Details[0].ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+
+	report, err := env.reports.GetComponentConsumption(nil, nil)
+	if err != nil {
+		t.Fatalf("GetComponentConsumption: %v", err)
+	}
+	want := []models.ComponentConsumption{
+		{ComponentID: milk.ID, ComponentName: "Susu", Quantity: 450, Returned: 150, NetQuantity: 300, Stock: 700},
+		{ComponentID: cup.ID, ComponentName: "Cup", Quantity: 3, Returned: 1, NetQuantity: 2, Stock: 18},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report = %+v\nwant %+v", report, want)
+	}
+
+	yesterday := env.store.Now().AddDate(0, 0, -1)
+	report, err = env.reports.GetComponentConsumption(&yesterday, &yesterday)
+	if err != nil || len(report) != 0 {
+		t.Errorf("kemarin = %+v, %v, want kosong", report, err)
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z0-9_-]{2,20}$`)
+
+type RoleService struct {
+	repo repositories.RoleRepository
+}
+
+func NewRoleService(repo repositories.RoleRepository) *RoleService {
+	return &RoleService{repo: repo}
+}
+
+func (s *RoleService) GetAll() ([]models.Role, error) {
+	roles, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range roles {
+		normalizeOwnerRole(&roles[i])
+	}
+	return roles, nil
+}
+
+func (s *RoleService) GetByName(name string) (*models.Role, error) {
+	role, err := s.repo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizeOwnerRole(role)
+	return role, nil
+}
+
+func (s *RoleService) Create(role *models.Role) error {
+	role.Name = strings.ToLower(strings.TrimSpace(role.Name))
+	if err := validateRole(role); err != nil {
+		return err
+	}
+
+	return s.repo.Create(role)
+}
+
+// Update - ubah deskripsi dan izin role. Role owner tidak bisa diubah.
+func (s *RoleService) Update(role *models.Role) error {
+	if role.Name == models.RoleOwner {
+		return &models.ConflictError{Message: "role owner selalu punya semua izin, tidak bisa diubah"}
+	}
+	if err := validateRole(role); err != nil {
+		return err
+	}
+
+	return s.repo.Update(role)
+}
+
+func (s *RoleService) Delete(name string) error {
+	role, err := s.repo.GetByName(name)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return &models.ConflictError{Message: "role bawaan tidak bisa dihapus"}
+	}
+
+	return s.repo.Delete(name)
+}
+
+func validateRole(role *models.Role) error {
+	var errs []models.FieldError
+
+	role.Description = strings.TrimSpace(role.Description)
+
+	if !roleNamePattern.MatchString(role.Name) {
+		errs = append(errs, models.FieldError{Field: "name", Message: "2-20 karakter huruf kecil, angka, garis bawah atau strip"})
+	}
+	if len(role.Description) > 200 {
+		errs = append(errs, models.FieldError{Field: "description", Message: "deskripsi maksimal 200 karakter"})
+	}
+	for _, p := range role.Permissions {
+		if !models.IsValidPermission(p) {
+			errs = append(errs, models.FieldError{Field: "permissions", Message: "izin tidak dikenal: " + p})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+
+	role.Permissions = normalizePermissions(role.Permissions)
+	return nil
+}
+
+// normalizeOwnerRole - owner selalu punya semua izin, termasuk izin baru yang belum ada di database
+func normalizeOwnerRole(role *models.Role) {
+	if role.Name == models.RoleOwner {
+		role.Permissions = slices.Clone(models.Permissions)
+	}
+}
+
+// effectivePermissions - izin role ditambah izin khusus user, urut dan tanpa duplikat
+func effectivePermissions(role *models.Role, user *models.User) []string {
+	normalizeOwnerRole(role)
+	return normalizePermissions(append(slices.Clone(role.Permissions), user.Permissions...))
+}
+
+func normalizePermissions(permissions []string) []string {
+	result := slices.Clone(permissions)
+	if result == nil {
+		result = []string{}
+	}
+	slices.Sort(result)
+	return slices.Compact(result)
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"errors"
+	"testing"
+)
+
+func TestEffectivePermissions(t *testing.T) {
+	env := newAuthEnv(t)
+
+	cashier, _, err := env.authenticate(t, models.LoginRequest{Username: "kasir1", PIN: "1234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cashier.Can(models.PermissionCheckout) || !cashier.Can(models.PermissionProductRead) {
+		t.Errorf("cashier permissions = %v, want checkout and products:read", cashier.EffectivePermissions)
+	}
+	for _, p := range []string{models.PermissionProductWrite, models.PermissionProductDelete, models.PermissionReportRead} {
+		if cashier.Can(p) {
+			t.Errorf("cashier can %s, want forbidden", p)
+		}
+	}
+
+	owner, _, err := env.authenticate(t, models.LoginRequest{Username: "owner", Password: "rahasia123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(owner.EffectivePermissions) != len(models.Permissions) {
+		t.Errorf("owner permissions = %v, want all", owner.EffectivePermissions)
+	}
+
+	// izin tambahan per user
+	_, err = env.users.Update(env.cashier.ID, models.UserInput{
+		Username: "kasir1", Name: "Kasir Satu", Role: models.RoleCashier,
+		Permissions: []string{models.PermissionReportRead},
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	cashier, _, err = env.authenticate(t, models.LoginRequest{Username: "kasir1", PIN: "1234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cashier.Can(models.PermissionReportRead) || cashier.Can(models.PermissionProductDelete) {
+		t.Errorf("cashier permissions = %v, want reports:read granted", cashier.EffectivePermissions)
+	}
+}
+
+func TestRoleService(t *testing.T) {
+	env := newAuthEnv(t)
+
+	gudang := &models.Role{Name: "Gudang", Description: "Staf gudang", Permissions: []string{models.PermissionProductWrite, models.PermissionProductRead, models.PermissionProductRead}}
+	if err := env.roles.Create(gudang); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if gudang.Name != "gudang" || len(gudang.Permissions) != 2 {
+		t.Errorf("Create() = %+v, want lowercase name and unique permissions", gudang)
+	}
+
+	if _, err := env.users.Create(models.UserInput{Username: "staf1", Name: "Staf", Role: "gudang", Password: "rahasia123"}); err != nil {
+		t.Fatalf("create user with custom role: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		run  func() error
+		want error
+	}{
+		{name: "nama role tidak valid", run: func() error { return env.roles.Create(&models.Role{Name: "a b"}) }, want: models.ErrValidation},
+		{name: "izin tidak dikenal", run: func() error { return env.roles.Create(&models.Role{Name: "x", Permissions: []string{"fly"}}) }, want: models.ErrValidation},
+		{name: "role sudah ada", run: func() error { return env.roles.Create(&models.Role{Name: "gudang"}) }, want: models.ErrConflict},
+		{name: "ubah owner", run: func() error { return env.roles.Update(&models.Role{Name: models.RoleOwner}) }, want: models.ErrConflict},
+		{name: "ubah role tidak ada", run: func() error { return env.roles.Update(&models.Role{Name: "tidakada"}) }, want: models.ErrNotFound},
+		{name: "hapus role bawaan", run: func() error { return env.roles.Delete(models.RoleCashier) }, want: models.ErrConflict},
+		{name: "hapus role yang dipakai", run: func() error { return env.roles.Delete("gudang") }, want: models.ErrConflict},
+		{name: "ubah izin supervisor", run: func() error {
+			return env.roles.Update(&models.Role{Name: models.RoleSupervisor, Permissions: []string{models.PermissionCheckout}})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// authenticate - login lalu autentikasi access token-nya, seperti middleware
+func (env *authEnv) authenticate(t *testing.T, req models.LoginRequest) (*models.User, *models.Session, error) {
+	t.Helper()
+	tokens, err := env.auth.Login(req, "10.0.0.1")
+	if err != nil {
+		return nil, nil, err
+	}
+	return env.auth.Authenticate(tokens.AccessToken)
+}
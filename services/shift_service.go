@@ -0,0 +1,97 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"strings"
+)
+
+type ShiftService struct {
+	repo repositories.ShiftRepository
+}
+
+func NewShiftService(repo repositories.ShiftRepository) *ShiftService {
+	return &ShiftService{repo: repo}
+}
+
+func (s *ShiftService) GetAll(filter models.ShiftFilter) ([]models.Shift, error) {
+	switch filter.Status {
+	case "", models.ShiftStatusOpen, models.ShiftStatusClosed:
+	default:
+		return nil, models.NewValidationError("status", "status harus open atau closed")
+	}
+	return s.repo.GetAll(filter)
+}
+
+func (s *ShiftService) GetByID(id int) (*models.Shift, error) {
+	return s.repo.GetByID(id)
+}
+
+// GetOpenByTerminal - shift yang sedang terbuka, dipakai aplikasi kasir untuk cek sebelum checkout
+func (s *ShiftService) GetOpenByTerminal(terminalID int) (*models.Shift, error) {
+	return s.repo.GetOpenByTerminal(terminalID)
+}
+
+// Open - buka shift baru dengan modal awal laci. Satu terminal hanya boleh punya satu shift terbuka.
+func (s *ShiftService) Open(req models.OpenShiftRequest) (*models.Shift, error) {
+	if req.CashierID <= 0 {
+		return nil, &models.UnauthorizedError{Message: "kasir tidak diketahui, silakan login"}
+	}
+
+	var errs []models.FieldError
+	if req.TerminalID <= 0 {
+		errs = append(errs, models.FieldError{Field: "terminal_id", Message: "terminal_id wajib diisi"})
+	}
+	if req.OpeningFloat < 0 {
+		errs = append(errs, models.FieldError{Field: "opening_float", Message: "modal awal tidak boleh minus"})
+	}
+	if len(errs) > 0 {
+		return nil, &models.ValidationError{Errors: errs}
+	}
+
+	return s.repo.Open(req)
+}
+
+// AddCashMovement - catat paid-in / paid-out selama shift terbuka
+func (s *ShiftService) AddCashMovement(shiftID int, req models.CashMovementRequest) (*models.CashMovement, error) {
+	if req.CreatedBy <= 0 {
+		return nil, &models.UnauthorizedError{Message: "user tidak diketahui, silakan login"}
+	}
+
+	var errs []models.FieldError
+
+	req.Type = strings.ToLower(strings.TrimSpace(req.Type))
+	req.Reason = strings.TrimSpace(req.Reason)
+
+	if req.Type != models.CashMovementPaidIn && req.Type != models.CashMovementPaidOut {
+		errs = append(errs, models.FieldError{Field: "type", Message: "type harus paid_in atau paid_out"})
+	}
+	if req.Amount <= 0 {
+		errs = append(errs, models.FieldError{Field: "amount", Message: "amount harus lebih dari 0"})
+	}
+	if req.Reason == "" {
+		errs = append(errs, models.FieldError{Field: "reason", Message: "alasan wajib diisi"})
+	}
+	if len(errs) > 0 {
+		return nil, &models.ValidationError{Errors: errs}
+	}
+
+	return s.repo.AddCashMovement(shiftID, req)
+}
+
+// Close - tutup shift dengan jumlah uang hasil hitung laci, selisih dihitung repository
+func (s *ShiftService) Close(id int, req models.CloseShiftRequest) (*models.Shift, error) {
+	if req.ClosedBy <= 0 {
+		return nil, &models.UnauthorizedError{Message: "user tidak diketahui, silakan login"}
+	}
+
+	if req.CountedCash == nil {
+		return nil, models.NewValidationError("counted_cash", "hasil hitung laci wajib diisi")
+	}
+	if *req.CountedCash < 0 {
+		return nil, models.NewValidationError("counted_cash", "hasil hitung laci tidak boleh minus")
+	}
+
+	req.Note = strings.TrimSpace(req.Note)
+	return s.repo.Close(id, req)
+}
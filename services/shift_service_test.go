@@ -0,0 +1,189 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"errors"
+	"testing"
+)
+
+func TestShiftServiceValidation(t *testing.T) {
+	env := newTestEnv(t, noTax)
+	negative := -1
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{name: "buka tanpa login", call: func() error {
+			_, err := env.shifts.Open(models.OpenShiftRequest{TerminalID: env.terminal.ID})
+			return err
+		}, want: models.ErrUnauthorized},
+		{name: "buka dengan modal minus", call: func() error {
+			_, err := env.shifts.Open(models.OpenShiftRequest{TerminalID: env.terminal.ID, OpeningFloat: -1, CashierID: env.cashier.ID})
+			return err
+		}, want: models.ErrValidation},
+		{name: "terminal masih punya shift terbuka", call: func() error {
+			_, err := env.shifts.Open(models.OpenShiftRequest{TerminalID: env.terminal.ID, CashierID: env.cashier.ID})
+			return err
+		}, want: models.ErrConflict},
+		{name: "terminal tidak ada", call: func() error {
+			_, err := env.shifts.Open(models.OpenShiftRequest{TerminalID: 99, CashierID: env.cashier.ID})
+			return err
+		}, want: models.ErrValidation},
+		{name: "type cash movement tidak dikenal", call: func() error {
+			_, err := env.shifts.AddCashMovement(env.shift.ID, models.CashMovementRequest{Type: "drop", Amount: 1000, Reason: "x", CreatedBy: env.cashier.ID})
+			return err
+		}, want: models.ErrValidation},
+		{name: "cash movement tanpa alasan", call: func() error {
+			_, err := env.shifts.AddCashMovement(env.shift.ID, models.CashMovementRequest{Type: models.CashMovementPaidOut, Amount: 1000, CreatedBy: env.cashier.ID})
+			return err
+		}, want: models.ErrValidation},
+		{name: "cash movement shift tidak ada", call: func() error {
+			_, err := env.shifts.AddCashMovement(99, models.CashMovementRequest{Type: models.CashMovementPaidIn, Amount: 1000, Reason: "x", CreatedBy: env.cashier.ID})
+			return err
+		}, want: models.ErrNotFound},
+		{name: "tutup tanpa hasil hitung", call: func() error {
+			_, err := env.shifts.Close(env.shift.ID, models.CloseShiftRequest{ClosedBy: env.cashier.ID})
+			return err
+		}, want: models.ErrValidation},
+		{name: "tutup dengan hasil hitung minus", call: func() error {
+			_, err := env.shifts.Close(env.shift.ID, models.CloseShiftRequest{CountedCash: &negative, ClosedBy: env.cashier.ID})
+			return err
+		}, want: models.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestShiftCashDrawer(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	checkout := func(items []models.CheckoutItem, payments []models.PaymentInput) *models.Transaction {
+		t.Helper()
+		transaction, _, err := env.checkout(models.CheckoutRequest{Items: items, Payments: payments}, true)
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		if transaction.ShiftID == nil || *transaction.ShiftID != env.shift.ID {
+			t.Fatalf("shift_id = %v, want %d", transaction.ShiftID, env.shift.ID)
+		}
+		return transaction
+	}
+
+	// 2 teh dibayar 20000, kembalian 10000 -> kas bertambah 10000
+	first := checkout([]models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 2}}, cash(20000))
+	// roti dengan QRIS tidak menambah kas
+	qris := checkout([]models.CheckoutItem{{ProductID: env.bread.ID, Quantity: 1}}, []models.PaymentInput{{Method: models.PaymentMethodQRIS, Amount: 12000}})
+	// di-void, tidak dihitung
+	voided := checkout([]models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}}, cash(5000))
+	if _, err := env.transactions.Void(voided.ID, models.VoidRequest{Reason: "salah input"}); err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+
+	for _, req := range []models.CashMovementRequest{
+		{Type: models.CashMovementPaidIn, Amount: 50000, Reason: "tambah uang kecil"},
+		{Type: models.CashMovementPaidOut, Amount: 20000, Reason: "bayar galon"},
+	} {
+		req.CreatedBy = env.cashier.ID
+		if _, err := env.shifts.AddCashMovement(env.shift.ID, req); err != nil {
+			t.Fatalf("AddCashMovement: %v", err)
+		}
+	}
+
+	cashRefund, err := env.transactions.Refund(first.ID, models.RefundRequest{
+		Reason: "tumpah",
+		Items:  []models.RefundItemRequest{{TransactionDetailID: first.Details[0].ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if cashRefund.CashAmount != 5000 || cashRefund.ShiftID == nil || *cashRefund.ShiftID != env.shift.ID {
+		t.Errorf("refund tunai = %+v, want cash 5000 di shift %d", cashRefund, env.shift.ID)
+	}
+
+	qrisRefund, err := env.transactions.Refund(qris.ID, models.RefundRequest{Reason: "basi"})
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if qrisRefund.CashAmount != 0 || qrisRefund.ShiftID != nil {
+		t.Errorf("refund QRIS = %+v, want tanpa uang tunai", qrisRefund)
+	}
+
+	current, err := env.shifts.GetOpenByTerminal(env.terminal.ID)
+	if err != nil {
+		t.Fatalf("GetOpenByTerminal: %v", err)
+	}
+	// 100000 + 10000 - 5000 + 50000 - 20000
+	if current.TotalTransaksi != 2 || current.CashSales != 10000 || current.CashRefunds != 5000 ||
+		current.PaidIn != 50000 || current.PaidOut != 20000 || current.ExpectedCash != 135000 || current.Variance != nil {
+		t.Errorf("shift terbuka = %+v", current)
+	}
+	if len(current.CashMovements) != 2 {
+		t.Errorf("cash movements = %+v, want 2", current.CashMovements)
+	}
+
+	counted := 130000
+	closed, err := env.shifts.Close(env.shift.ID, models.CloseShiftRequest{CountedCash: &counted, Note: " kurang 5rb ", ClosedBy: env.cashier.ID})
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if closed.Status != models.ShiftStatusClosed || closed.ExpectedCash != 135000 || closed.Variance == nil || *closed.Variance != -5000 ||
+		closed.ClosingNote != "kurang 5rb" || closed.ClosedAt == nil {
+		t.Errorf("shift ditutup = %+v", closed)
+	}
+
+	t.Run("setelah shift ditutup", func(t *testing.T) {
+		if _, _, err := env.checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}}, Payments: cash(5000)}, true); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Checkout err = %v, want conflict", err)
+		}
+		if _, err := env.shifts.AddCashMovement(env.shift.ID, models.CashMovementRequest{Type: models.CashMovementPaidIn, Amount: 1000, Reason: "x", CreatedBy: env.cashier.ID}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("AddCashMovement err = %v, want conflict", err)
+		}
+		if _, err := env.shifts.Close(env.shift.ID, models.CloseShiftRequest{CountedCash: &counted, ClosedBy: env.cashier.ID}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Close err = %v, want conflict", err)
+		}
+		if _, err := env.transactions.Void(first.ID, models.VoidRequest{Reason: "salah"}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("Void err = %v, want conflict", err)
+		}
+		if _, err := env.transactions.Refund(first.ID, models.RefundRequest{Reason: "tumpah"}); !errors.Is(err, models.ErrConflict) {
+			t.Errorf("refund tunai tanpa shift err = %v, want conflict", err)
+		}
+	})
+
+	t.Run("refund tunai masuk shift berikutnya", func(t *testing.T) {
+		next, err := env.shifts.Open(models.OpenShiftRequest{TerminalID: env.terminal.ID, OpeningFloat: 50000, CashierID: env.cashier.ID})
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		refund, err := env.transactions.Refund(first.ID, models.RefundRequest{Reason: "tumpah"})
+		if err != nil {
+			t.Fatalf("Refund: %v", err)
+		}
+		if refund.CashAmount != 5000 || refund.ShiftID == nil || *refund.ShiftID != next.ID {
+			t.Errorf("refund = %+v, want cash 5000 di shift %d", refund, next.ID)
+		}
+
+		reloaded, err := env.shifts.GetByID(env.shift.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if reloaded.ExpectedCash != 135000 || *reloaded.Variance != -5000 {
+			t.Errorf("shift lama berubah = %+v", reloaded)
+		}
+
+		shifts, err := env.shifts.GetAll(models.ShiftFilter{Status: models.ShiftStatusOpen})
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(shifts) != 1 || shifts[0].ID != next.ID || shifts[0].ExpectedCash != 45000 {
+			t.Errorf("shift terbuka = %+v", shifts)
+		}
+	})
+}
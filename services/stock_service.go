@@ -0,0 +1,120 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"strings"
+)
+
+type StockService struct {
+	repo     repositories.StockMovementRepository
+	products repositories.ProductRepository
+}
+
+func NewStockService(repo repositories.StockMovementRepository, products repositories.ProductRepository) *StockService {
+	return &StockService{repo: repo, products: products}
+}
+
+// manualStockMovements - tipe yang boleh dicatat lewat API, sisanya dicatat otomatis
+var manualStockMovements = []string{
+	models.StockMovementAdjustment,
+	models.StockMovementReceiving,
+	models.StockMovementTransfer,
+	models.StockMovementOpname,
+}
+
+// RecordMovement - catat pergerakan stok manual, stok produk ikut berubah
+func (s *StockService) RecordMovement(req models.StockMovementRequest) (*models.StockMovement, error) {
+	if req.CreatedBy <= 0 {
+		return nil, &models.UnauthorizedError{Message: "user tidak diketahui, silakan login"}
+	}
+
+	var errs []models.FieldError
+
+	req.Type = strings.ToLower(strings.TrimSpace(req.Type))
+	req.Reference = strings.TrimSpace(req.Reference)
+	req.Note = strings.TrimSpace(req.Note)
+
+	switch req.Type {
+	case models.StockMovementReceiving:
+		if req.Quantity <= 0 {
+			errs = append(errs, models.FieldError{Field: "quantity", Message: "quantity harus lebih dari 0"})
+		}
+	case models.StockMovementAdjustment, models.StockMovementTransfer:
+		if req.Quantity == 0 {
+			errs = append(errs, models.FieldError{Field: "quantity", Message: "quantity tidak boleh 0"})
+		}
+	case models.StockMovementOpname:
+		if req.Counted == nil {
+			errs = append(errs, models.FieldError{Field: "counted", Message: "stok hasil hitung wajib diisi"})
+		} else if *req.Counted < 0 {
+			errs = append(errs, models.FieldError{Field: "counted", Message: "stok hasil hitung tidak boleh minus"})
+		}
+	default:
+		errs = append(errs, models.FieldError{
+			Field:   "type",
+			Message: "type harus salah satu dari " + strings.Join(manualStockMovements, ", "),
+		})
+	}
+	if req.Type == models.StockMovementAdjustment && req.Note == "" {
+		errs = append(errs, models.FieldError{Field: "note", Message: "alasan adjustment wajib diisi"})
+	}
+	if len(errs) > 0 {
+		return nil, &models.ValidationError{Errors: errs}
+	}
+
+	product, err := s.products.GetByID(req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if len(product.Recipe) > 0 {
+		return nil, &models.ConflictError{Message: "produk komposit tidak punya stok sendiri, catat pergerakan pada komponennya"}
+	}
+	if len(product.Variants) > 0 {
+		return nil, &models.ConflictError{Message: "produk punya varian, catat pergerakan pada variannya"}
+	}
+
+	return s.repo.Create(req)
+}
+
+// Movements - riwayat pergerakan stok satu produk, terbaru dulu
+func (s *StockService) Movements(productID int, filter models.StockMovementFilter) (*models.StockMovementList, error) {
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return nil, models.NewValidationError("end_date", "end_date tidak boleh sebelum start_date")
+	}
+
+	filter.Type = strings.ToLower(strings.TrimSpace(filter.Type))
+	if filter.Type != "" && !isStockMovementType(filter.Type) {
+		return nil, models.NewValidationError("type", "type pergerakan stok tidak dikenal")
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = DefaultPageLimit
+	}
+	if filter.Limit > MaxPageLimit {
+		filter.Limit = MaxPageLimit
+	}
+
+	return s.repo.GetByProduct(productID, filter)
+}
+
+// Check - hitung ulang stok dari ledger dan laporkan produk yang tidak cocok
+func (s *StockService) Check() (*models.StockCheck, error) {
+	return s.repo.Check()
+}
+
+func isStockMovementType(t string) bool {
+	switch t {
+	case models.StockMovementInitial, models.StockMovementSale, models.StockMovementVoid, models.StockMovementRefund:
+		return true
+	}
+	for _, manual := range manualStockMovements {
+		if t == manual {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"errors"
+	"testing"
+)
+
+func TestStockMovementValidation(t *testing.T) {
+	env := newTestEnv(t, noTax)
+	negative := -1
+
+	milk := &models.Product{Name: "Susu", Stock: 1000}
+	if err := env.products.Create(milk); err != nil {
+		t.Fatalf("create component: %v", err)
+	}
+	latte := &models.Product{Name: "Es Kopi Susu", Price: 20000, Recipe: []models.RecipeItem{{ComponentID: milk.ID, Quantity: 150}}}
+	if err := env.products.Create(latte); err != nil {
+		t.Fatalf("create composite: %v", err)
+	}
+
+	record := func(req models.StockMovementRequest) func() error {
+		return func() error {
+			if req.ProductID == 0 {
+				req.ProductID = env.tea.ID
+			}
+			_, err := env.ledger.RecordMovement(req)
+			return err
+		}
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{name: "tanpa login", call: record(models.StockMovementRequest{Type: models.StockMovementReceiving, Quantity: 5}), want: models.ErrUnauthorized},
+		{name: "type otomatis", call: record(models.StockMovementRequest{Type: models.StockMovementSale, Quantity: -1, CreatedBy: env.cashier.ID}), want: models.ErrValidation},
+		{name: "receiving quantity minus", call: record(models.StockMovementRequest{Type: models.StockMovementReceiving, Quantity: -5, CreatedBy: env.cashier.ID}), want: models.ErrValidation},
+		{name: "transfer quantity 0", call: record(models.StockMovementRequest{Type: models.StockMovementTransfer, CreatedBy: env.cashier.ID}), want: models.ErrValidation},
+		{name: "adjustment tanpa alasan", call: record(models.StockMovementRequest{Type: models.StockMovementAdjustment, Quantity: -1, CreatedBy: env.cashier.ID}), want: models.ErrValidation},
+		{name: "opname tanpa hasil hitung", call: record(models.StockMovementRequest{Type: models.StockMovementOpname, CreatedBy: env.cashier.ID}), want: models.ErrValidation},
+		{name: "opname hasil hitung minus", call: record(models.StockMovementRequest{Type: models.StockMovementOpname, Counted: &negative, CreatedBy: env.cashier.ID}), want: models.ErrValidation},
+		{name: "stok jadi minus", call: record(models.StockMovementRequest{Type: models.StockMovementTransfer, Quantity: -11, CreatedBy: env.cashier.ID}), want: models.ErrInsufficientStock},
+		{name: "produk komposit", call: record(models.StockMovementRequest{ProductID: latte.ID, Type: models.StockMovementReceiving, Quantity: 5, CreatedBy: env.cashier.ID}), want: models.ErrConflict},
+		{name: "produk tidak ada", call: record(models.StockMovementRequest{ProductID: 99, Type: models.StockMovementReceiving, Quantity: 5, CreatedBy: env.cashier.ID}), want: models.ErrNotFound},
+		{name: "filter type tidak dikenal", call: func() error {
+			_, err := env.ledger.Movements(env.tea.ID, models.StockMovementFilter{Type: "hilang"})
+			return err
+		}, want: models.ErrValidation},
+		{name: "riwayat produk tidak ada", call: func() error {
+			_, err := env.ledger.Movements(99, models.StockMovementFilter{})
+			return err
+		}, want: models.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if got := env.stock(t, env.tea.ID); got != 10 {
+		t.Errorf("stok teh = %d, want 10 (tidak berubah)", got)
+	}
+}
+
+func TestStockMovements(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	milk := &models.Product{Name: "Susu", Stock: 1000}
+	if err := env.products.Create(milk); err != nil {
+		t.Fatalf("create component: %v", err)
+	}
+	latte := &models.Product{Name: "Es Kopi Susu", Price: 20000, Recipe: []models.RecipeItem{{ComponentID: milk.ID, Quantity: 150}}}
+	if err := env.products.Create(latte); err != nil {
+		t.Fatalf("create composite: %v", err)
+	}
+
+	sale, _, err := env.checkout(models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 3}, {ProductID: latte.ID, Quantity: 2}},
+		Payments: cash(100000),
+	}, true)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	refund, err := env.transactions.Refund(sale.ID, models.RefundRequest{
+		Reason:    "salah rasa",
+		Items:     []models.RefundItemRequest{{TransactionDetailID: sale.Details[0].ID, Quantity: 1}},
+		CreatedBy: env.cashier.ID,
+	})
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	voided, _, err := env.checkout(models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}},
+		Payments: cash(5000),
+	}, true)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if _, err := env.transactions.Void(voided.ID, models.VoidRequest{Reason: "salah input", VoidedBy: env.cashier.ID}); err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+
+	counted := 25
+	for _, req := range []models.StockMovementRequest{
+		{Type: models.StockMovementReceiving, Quantity: 20, Reference: " SJ-001 "},
+		{Type: models.StockMovementOpname, Counted: &counted, Reference: "BA-OPNAME-01"},
+		{Type: models.StockMovementTransfer, Quantity: -5, Note: "ke outlet 2"},
+	} {
+		req.ProductID, req.CreatedBy = env.tea.ID, env.cashier.ID
+		if _, err := env.ledger.RecordMovement(req); err != nil {
+			t.Fatalf("RecordMovement(%s): %v", req.Type, err)
+		}
+	}
+
+	// stok dari body update produk diabaikan, perubahan stok harus lewat ledger dengan alasan
+	env.tea.Stock = 30
+	if err := env.products.Update(env.tea); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if env.tea.Stock != 20 || env.stock(t, env.tea.ID) != 20 {
+		t.Fatalf("stok setelah update = %d / %d, want 20", env.tea.Stock, env.stock(t, env.tea.ID))
+	}
+	_, err = env.ledger.RecordMovement(models.StockMovementRequest{
+		ProductID: env.tea.ID, Type: models.StockMovementAdjustment, Quantity: 10, Note: "salah hitung", CreatedBy: env.cashier.ID,
+	})
+	if err != nil {
+		t.Fatalf("RecordMovement(adjustment): %v", err)
+	}
+
+	list, err := env.ledger.Movements(env.tea.ID, models.StockMovementFilter{})
+	if err != nil {
+		t.Fatalf("Movements: %v", err)
+	}
+	want := []struct {
+		typ       string
+		quantity  int
+		balance   int
+		reference string
+	}{
+		{models.StockMovementAdjustment, 10, 30, ""},
+		{models.StockMovementTransfer, -5, 20, ""},
+		{models.StockMovementOpname, -3, 25, "BA-OPNAME-01"},
+		{models.StockMovementReceiving, 20, 28, "SJ-001"},
+		{models.StockMovementVoid, 1, 8, voided.ReceiptNumber},
+		{models.StockMovementSale, -1, 7, voided.ReceiptNumber},
+		{models.StockMovementRefund, 1, 8, sale.ReceiptNumber},
+		{models.StockMovementSale, -3, 7, sale.ReceiptNumber},
+		{models.StockMovementInitial, 10, 10, ""},
+	}
+	if list.Total != len(want) || len(list.Data) != len(want) || list.Page != 1 || list.Limit != DefaultPageLimit {
+		t.Fatalf("list = total %d, %d data, page %d, limit %d", list.Total, len(list.Data), list.Page, list.Limit)
+	}
+	for i, w := range want {
+		m := list.Data[i]
+		if m.Type != w.typ || m.Quantity != w.quantity || m.Balance != w.balance || m.Reference != w.reference {
+			t.Errorf("data[%d] = %s %d -> %d %q, want %s %d -> %d %q", i, m.Type, m.Quantity, m.Balance, m.Reference, w.typ, w.quantity, w.balance, w.reference)
+		}
+		if m.CreatedBy == nil || *m.CreatedBy != env.cashier.ID {
+			if m.Type != models.StockMovementInitial {
+				t.Errorf("data[%d].created_by = %v, want %d", i, m.CreatedBy, env.cashier.ID)
+			}
+		}
+	}
+
+	sold := list.Data[7]
+	if sold.ReferenceType != models.StockReferenceTransaction || sold.ReferenceID == nil || *sold.ReferenceID != sale.ID {
+		t.Errorf("sale reference = %s %v, want transaction %d", sold.ReferenceType, sold.ReferenceID, sale.ID)
+	}
+	returned := list.Data[6]
+	if returned.ReferenceType != models.StockReferenceRefund || returned.ReferenceID == nil || *returned.ReferenceID != refund.ID {
+		t.Errorf("refund reference = %s %v, want refund %d", returned.ReferenceType, returned.ReferenceID, refund.ID)
+	}
+
+	// komponen resep tercatat atas nama komponen, bukan produk komposit
+	milkList, err := env.ledger.Movements(milk.ID, models.StockMovementFilter{Type: models.StockMovementSale})
+	if err != nil {
+		t.Fatalf("Movements(susu): %v", err)
+	}
+	if milkList.Total != 1 || milkList.Data[0].Quantity != -300 || milkList.Data[0].Balance != 700 {
+		t.Errorf("susu = %+v, want satu sale -300 -> 700", milkList.Data)
+	}
+
+	page, err := env.ledger.Movements(env.tea.ID, models.StockMovementFilter{Page: 2, Limit: 4})
+	if err != nil {
+		t.Fatalf("Movements page 2: %v", err)
+	}
+	if page.Total != len(want) || len(page.Data) != 4 || page.Data[0].Type != models.StockMovementVoid {
+		t.Errorf("page 2 = total %d, %+v", page.Total, page.Data)
+	}
+
+	yesterday := env.store.Now().AddDate(0, 0, -1)
+	old, err := env.ledger.Movements(env.tea.ID, models.StockMovementFilter{StartDate: &yesterday, EndDate: &yesterday})
+	if err != nil || old.Total != 0 {
+		t.Errorf("kemarin = %+v, %v, want kosong", old, err)
+	}
+
+	check, err := env.ledger.Check()
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if !check.Consistent || check.CheckedProducts != 4 || len(check.Discrepancies) != 0 {
+		t.Errorf("check = %+v, want konsisten untuk 4 produk", check)
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// defaultPrinterPort - port raw TCP printer thermal jaringan
+const defaultPrinterPort = "9100"
+
+type TerminalService struct {
+	repo repositories.TerminalRepository
+}
+
+func NewTerminalService(repo repositories.TerminalRepository) *TerminalService {
+	return &TerminalService{repo: repo}
+}
+
+func (s *TerminalService) GetAll() ([]models.Terminal, error) {
+	return s.repo.GetAll()
+}
+
+func (s *TerminalService) GetByID(id int) (*models.Terminal, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *TerminalService) Create(terminal *models.Terminal) error {
+	if err := validateTerminal(terminal); err != nil {
+		return err
+	}
+	return s.repo.Create(terminal)
+}
+
+func (s *TerminalService) Update(terminal *models.Terminal) error {
+	if err := validateTerminal(terminal); err != nil {
+		return err
+	}
+	return s.repo.Update(terminal)
+}
+
+func validateTerminal(terminal *models.Terminal) error {
+	var errs []models.FieldError
+
+	terminal.Code = strings.ToUpper(strings.TrimSpace(terminal.Code))
+	terminal.Name = strings.TrimSpace(terminal.Name)
+
+	if terminal.Code == "" || len(terminal.Code) > 30 {
+		errs = append(errs, models.FieldError{Field: "code", Message: "kode wajib diisi, maksimal 30 karakter"})
+	}
+	if terminal.Name == "" {
+		errs = append(errs, models.FieldError{Field: "name", Message: "nama wajib diisi"})
+	}
+
+	terminal.PrinterAddress = strings.TrimSpace(terminal.PrinterAddress)
+	if terminal.PrinterAddress != "" {
+		address, ok := normalizePrinterAddress(terminal.PrinterAddress)
+		if !ok {
+			errs = append(errs, models.FieldError{Field: "printer_address", Message: "printer_address harus host atau host:port"})
+		}
+		terminal.PrinterAddress = address
+	}
+	if terminal.PrinterWidth == 0 {
+		terminal.PrinterWidth = models.ReceiptWidth80
+	}
+	if terminal.PrinterWidth != models.ReceiptWidth58 && terminal.PrinterWidth != models.ReceiptWidth80 {
+		errs = append(errs, models.FieldError{Field: "printer_width", Message: "printer_width harus 58 atau 80"})
+	}
+
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// normalizePrinterAddress - "192.168.1.50" -> "192.168.1.50:9100"
+func normalizePrinterAddress(address string) (string, bool) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host, port = address, defaultPrinterPort
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 || host == "" || strings.ContainsAny(host, " /") {
+		return address, false
+	}
+	return net.JoinHostPort(host, port), true
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"testing"
+)
+
+func TestValidateTerminal(t *testing.T) {
+	tests := []struct {
+		name        string
+		terminal    models.Terminal
+		wantAddress string
+		wantWidth   int
+		wantErr     bool
+	}{
+		{name: "tanpa printer", terminal: models.Terminal{Code: "kasir-01", Name: "Kasir depan"}, wantWidth: 80},
+		{name: "port default", terminal: models.Terminal{Code: "K1", Name: "Kasir", PrinterAddress: " 192.168.1.50 "}, wantAddress: "192.168.1.50:9100", wantWidth: 80},
+		{name: "port diisi", terminal: models.Terminal{Code: "K1", Name: "Kasir", PrinterAddress: "printer.lokal:9101", PrinterWidth: 58}, wantAddress: "printer.lokal:9101", wantWidth: 58},
+		{name: "port salah", terminal: models.Terminal{Code: "K1", Name: "Kasir", PrinterAddress: "192.168.1.50:abc"}, wantErr: true},
+		{name: "alamat salah", terminal: models.Terminal{Code: "K1", Name: "Kasir", PrinterAddress: "http://printer"}, wantErr: true},
+		{name: "lebar tidak didukung", terminal: models.Terminal{Code: "K1", Name: "Kasir", PrinterWidth: 76}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terminal := tt.terminal
+			err := validateTerminal(&terminal)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if terminal.PrinterAddress != tt.wantAddress || terminal.PrinterWidth != tt.wantWidth {
+				t.Errorf("printer = %q %d, want %q %d", terminal.PrinterAddress, terminal.PrinterWidth, tt.wantAddress, tt.wantWidth)
+			}
+		})
+	}
+}
@@ -3,16 +3,365 @@ package services
 import (
 	"aplikasi-kasir/models"
 	"aplikasi-kasir/repositories"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxCartLines - jumlah baris item maksimal dalam satu checkout
+	MaxCartLines = 100
+	// MaxItemQuantity - quantity maksimal per produk dalam satu checkout
+	MaxItemQuantity = 1000
+
+	// MaxPayments - jumlah tender maksimal (split payment) per transaksi
+	MaxPayments = 5
+	// MaxPaymentAmount - nominal maksimal per tender dan total tender satu transaksi, sama dengan
+	// batas total belanja
+	MaxPaymentAmount = repositories.MaxTransactionAmount
+
+	// MaxIdempotencyKeyLength - panjang maksimal header Idempotency-Key
+	MaxIdempotencyKeyLength = 255
+
+	// DefaultPageLimit / MaxPageLimit - paginasi daftar transaksi
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
 )
 
 type TransactionService struct {
-	repo *repositories.TransactionRepository
+	repo     repositories.TransactionRepository
+	products repositories.ProductRepository
+}
+
+func NewTransactionService(repo repositories.TransactionRepository, products repositories.ProductRepository) *TransactionService {
+	return &TransactionService{repo: repo, products: products}
+}
+
+// Checkout - validasi lalu simpan transaksi. replayed = true berarti request dengan
+// Idempotency-Key yang sama sudah pernah berhasil dan transaksi lama yang dikembalikan.
+func (s *TransactionService) Checkout(req models.CheckoutRequest, useLock bool) (transaction *models.Transaction, replayed bool, err error) {
+	if req.CashierID <= 0 {
+		return nil, false, &models.UnauthorizedError{Message: "kasir tidak diketahui, silakan login"}
+	}
+	if req.TerminalID <= 0 {
+		return nil, false, models.NewValidationError("terminal_id", "terminal_id wajib diisi")
+	}
+
+	if err := validateCartLines(req.Items); err != nil {
+		return nil, false, err
+	}
+
+	req.Items, err = s.resolveCheckoutItems(req.Items)
+	if err != nil {
+		return nil, false, err
+	}
+
+	req.Items, err = normalizeCheckoutItems(req.Items)
+	if err != nil {
+		return nil, false, err
+	}
+
+	req.Payments, err = normalizePayments(req.Payments)
+	if err != nil {
+		return nil, false, err
+	}
+
+	req.IdempotencyKey = strings.TrimSpace(req.IdempotencyKey)
+	if len(req.IdempotencyKey) > MaxIdempotencyKeyLength {
+		return nil, false, &models.ValidationError{Errors: []models.FieldError{
+			{Field: "Idempotency-Key", Message: fmt.Sprintf("maksimal %d karakter", MaxIdempotencyKeyLength)},
+		}}
+	}
+
+	if req.IdempotencyKey != "" {
+		payload, err := json.Marshal(req)
+		if err != nil {
+			return nil, false, err
+		}
+		sum := sha256.Sum256(payload)
+		req.RequestHash = hex.EncodeToString(sum[:])
+	}
+
+	return s.repo.CreateTransaction(req, useLock)
 }
 
-func NewTransactionService(repo *repositories.TransactionRepository) *TransactionService {
-	return &TransactionService{repo: repo}
+func (s *TransactionService) GetAll(filter models.TransactionFilter) (*models.TransactionList, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = DefaultPageLimit
+	}
+	if filter.Limit > MaxPageLimit {
+		filter.Limit = MaxPageLimit
+	}
+
+	return s.repo.GetAll(filter)
+}
+
+func (s *TransactionService) GetByID(id int) (*models.Transaction, error) {
+	return s.repo.GetByID(id)
+}
+
+// Void - batalkan transaksi hari ini (salah input kasir)
+func (s *TransactionService) Void(id int, req models.VoidRequest) (*models.Transaction, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return nil, &models.ValidationError{Errors: []models.FieldError{
+			{Field: "reason", Message: "alasan void wajib diisi"},
+		}}
+	}
+
+	return s.repo.Void(id, req)
+}
+
+// Refund - refund penuh (items kosong) atau sebagian per baris detail
+func (s *TransactionService) Refund(id int, req models.RefundRequest) (*models.Refund, error) {
+	var errs []models.FieldError
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		errs = append(errs, models.FieldError{Field: "reason", Message: "alasan refund wajib diisi"})
+	}
+
+	// gabungkan baris dengan transaction_detail_id yang sama
+	items := make([]models.RefundItemRequest, 0, len(req.Items))
+	position := make(map[int]int)
+	for i, item := range req.Items {
+		if item.Quantity <= 0 {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].quantity", i),
+				Message: "quantity harus lebih dari 0",
+			})
+			continue
+		}
+
+		if idx, ok := position[item.TransactionDetailID]; ok {
+			items[idx].Quantity += item.Quantity
+			continue
+		}
+
+		position[item.TransactionDetailID] = len(items)
+		items = append(items, item)
+	}
+
+	if len(errs) > 0 {
+		return nil, &models.ValidationError{Errors: errs}
+	}
+
+	req.Items = items
+	return s.repo.Refund(id, req)
 }
 
-func (s *TransactionService) Checkout(items []models.CheckoutItem, useLock bool) (*models.Transaction, error) {
-	return s.repo.CreateTransaction(items)
+// resolveCheckoutItems - ganti barcode / sku item dengan product_id supaya item hasil scan dan
+// pilihan manual produk yang sama tergabung jadi satu baris. Seluruh barcode dan sku dicari
+// sekaligus, bukan satu query per baris.
+func (s *TransactionService) resolveCheckoutItems(items []models.CheckoutItem) ([]models.CheckoutItem, error) {
+	var errs []models.FieldError
+	var barcodes, skus []string
+
+	resolved := make([]models.CheckoutItem, len(items))
+	for i, item := range items {
+		item.Barcode, item.SKU = normalizeBarcode(item.Barcode), normalizeSKU(item.SKU)
+		resolved[i] = item
+
+		switch {
+		case item.Barcode == "" && item.SKU == "":
+		case item.ProductID != 0 || (item.Barcode != "" && item.SKU != ""):
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("items[%d]", i),
+				Message: "isi salah satu dari product_id, barcode atau sku",
+			})
+		case item.Barcode != "":
+			barcodes = append(barcodes, item.Barcode)
+		default:
+			skus = append(skus, item.SKU)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, &models.ValidationError{Errors: errs}
+	}
+
+	var byBarcode, bySKU map[string]int
+	var err error
+	if len(barcodes) > 0 {
+		if byBarcode, err = s.products.IDsByBarcode(barcodes); err != nil {
+			return nil, err
+		}
+	}
+	if len(skus) > 0 {
+		if bySKU, err = s.products.IDsBySKU(skus); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, item := range resolved {
+		field, code, ids := "barcode", item.Barcode, byBarcode
+		if item.Barcode == "" {
+			if item.SKU == "" {
+				continue
+			}
+			field, code, ids = "sku", item.SKU, bySKU
+		}
+
+		productID, ok := ids[code]
+		if !ok {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].%s", i, field),
+				Message: fmt.Sprintf("produk dengan %s %s tidak ditemukan", field, code),
+			})
+			continue
+		}
+		resolved[i] = models.CheckoutItem{ProductID: productID, Quantity: item.Quantity}
+	}
+
+	if len(errs) > 0 {
+		return nil, &models.ValidationError{Errors: errs}
+	}
+
+	return resolved, nil
+}
+
+// validateCartLines - batas keranjang dicek pada item mentah sebelum barcode / sku dicari ke
+// database, supaya request yang pasti ditolak tidak sempat membuat query
+func validateCartLines(items []models.CheckoutItem) error {
+	var errs []models.FieldError
+
+	if len(items) == 0 {
+		return &models.ValidationError{Errors: []models.FieldError{
+			{Field: "items", Message: "keranjang tidak boleh kosong"},
+		}}
+	}
+
+	if len(items) > MaxCartLines {
+		return &models.ValidationError{Errors: []models.FieldError{
+			{Field: "items", Message: fmt.Sprintf("maksimal %d baris item per transaksi", MaxCartLines)},
+		}}
+	}
+
+	for i, item := range items {
+		if item.Quantity <= 0 {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].quantity", i),
+				Message: "quantity harus lebih dari 0",
+			})
+		} else if item.Quantity > MaxItemQuantity {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].quantity", i),
+				Message: fmt.Sprintf("quantity maksimal %d", MaxItemQuantity),
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// normalizeCheckoutItems - validasi product_id dan gabungkan baris dengan product_id yang sama,
+// quantity per baris sudah dicek validateCartLines. Urutan item mengikuti kemunculan pertama
+// product_id di request.
+func normalizeCheckoutItems(items []models.CheckoutItem) ([]models.CheckoutItem, error) {
+	var errs []models.FieldError
+
+	merged := make([]models.CheckoutItem, 0, len(items))
+	position := make(map[int]int)  // product_id -> index di merged
+	firstLine := make(map[int]int) // product_id -> index baris pertama di request
+
+	for i, item := range items {
+		if item.ProductID <= 0 {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].product_id", i),
+				Message: "product_id wajib diisi",
+			})
+			continue
+		}
+
+		if idx, ok := position[item.ProductID]; ok {
+			merged[idx].Quantity += item.Quantity
+			continue
+		}
+
+		position[item.ProductID] = len(merged)
+		firstLine[item.ProductID] = i
+		merged = append(merged, item)
+	}
+
+	for _, item := range merged {
+		if item.Quantity > MaxItemQuantity {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("items[%d].quantity", firstLine[item.ProductID]),
+				Message: fmt.Sprintf("total quantity product_id %d melebihi maksimal %d", item.ProductID, MaxItemQuantity),
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, &models.ValidationError{Errors: errs}
+	}
+
+	return merged, nil
+}
+
+// normalizePayments - validasi metode dan nominal tender. Kecukupan nominal dicek di repository
+// karena total belanja baru diketahui setelah harga produk dibaca.
+func normalizePayments(payments []models.PaymentInput) ([]models.PaymentInput, error) {
+	var errs []models.FieldError
+
+	if len(payments) == 0 {
+		return nil, &models.ValidationError{Errors: []models.FieldError{
+			{Field: "payments", Message: "minimal satu pembayaran"},
+		}}
+	}
+
+	if len(payments) > MaxPayments {
+		return nil, &models.ValidationError{Errors: []models.FieldError{
+			{Field: "payments", Message: fmt.Sprintf("maksimal %d pembayaran per transaksi", MaxPayments)},
+		}}
+	}
+
+	normalized := make([]models.PaymentInput, 0, len(payments))
+	for i, p := range payments {
+		p.Method = strings.ToLower(strings.TrimSpace(p.Method))
+		p.Reference = strings.TrimSpace(p.Reference)
+
+		if !models.IsValidPaymentMethod(p.Method) {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("payments[%d].method", i),
+				Message: "metode harus salah satu dari " + strings.Join(models.PaymentMethods, ", "),
+			})
+		}
+
+		if p.Amount <= 0 {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("payments[%d].amount", i),
+				Message: "amount harus lebih dari 0",
+			})
+		} else if p.Amount > MaxPaymentAmount {
+			errs = append(errs, models.FieldError{
+				Field:   fmt.Sprintf("payments[%d].amount", i),
+				Message: fmt.Sprintf("amount maksimal %d", MaxPaymentAmount),
+			})
+		}
+
+		normalized = append(normalized, p)
+	}
+
+	if len(errs) > 0 {
+		return nil, &models.ValidationError{Errors: errs}
+	}
+
+	// total tender disimpan sebagai paid_amount
+	total := 0
+	for _, p := range normalized {
+		total += p.Amount
+	}
+	if total > MaxPaymentAmount {
+		return nil, models.NewValidationError("payments", fmt.Sprintf("total pembayaran maksimal %d", MaxPaymentAmount))
+	}
+
+	return normalized, nil
 }
@@ -0,0 +1,883 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testEnv - service di atas store in-memory dengan dua produk: Es Teh (5000, stok 10)
+// dan Roti (12000, stok 3), satu kasir dan satu terminal dengan shift terbuka (modal 100000).
+// Pajak exclusive 0% supaya total mudah dihitung.
+type testEnv struct {
+	store        *repositories.MemoryStore
+	products     *ProductService
+	promotions   *PromotionService
+	transactions *TransactionService
+	reports      *ReportService
+	shifts       *ShiftService
+	ledger       *StockService
+	tea, bread   *models.Product
+	cashier      *models.User
+	terminal     *models.Terminal
+	shift        *models.Shift
+}
+
+func newTestEnv(t *testing.T, taxConfig models.TaxConfig) *testEnv {
+	t.Helper()
+
+	store := repositories.NewMemoryStore()
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	store.Now = func() time.Time { return now }
+
+	env := &testEnv{
+		store:        store,
+		products:     NewProductService(repositories.NewMemoryProductRepository(store)),
+		promotions:   NewPromotionService(repositories.NewMemoryPromotionRepository(store)),
+		transactions: NewTransactionService(repositories.NewMemoryTransactionRepository(store, taxConfig, testReceiptConfig), repositories.NewMemoryProductRepository(store)),
+		reports:      NewReportService(repositories.NewMemoryReportRepository(store)),
+		shifts:       NewShiftService(repositories.NewMemoryShiftRepository(store)),
+		ledger:       NewStockService(repositories.NewMemoryStockMovementRepository(store), repositories.NewMemoryProductRepository(store)),
+		tea:          &models.Product{Name: "Es Teh", Price: 5000, Stock: 10},
+		bread:        &models.Product{Name: "Roti", Price: 12000, Stock: 3},
+		cashier:      &models.User{Username: "kasir1", Name: "Kasir Satu", Role: models.RoleCashier, Active: true},
+		terminal:     &models.Terminal{Code: "KASIR-01", Name: "Kasir depan", Active: true},
+	}
+
+	if err := repositories.NewMemoryUserRepository(store).Create(env.cashier); err != nil {
+		t.Fatalf("create cashier: %v", err)
+	}
+	if err := repositories.NewMemoryTerminalRepository(store).Create(env.terminal); err != nil {
+		t.Fatalf("create terminal: %v", err)
+	}
+
+	var err error
+	env.shift, err = env.shifts.Open(models.OpenShiftRequest{TerminalID: env.terminal.ID, OpeningFloat: 100000, CashierID: env.cashier.ID})
+	if err != nil {
+		t.Fatalf("open shift: %v", err)
+	}
+
+	for _, p := range []*models.Product{env.tea, env.bread} {
+		if err := env.products.Create(p); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+
+	return env
+}
+
+// checkout - checkout sebagai kasir dan terminal test kecuali diisi lain di req
+func (env *testEnv) checkout(req models.CheckoutRequest, useLock bool) (*models.Transaction, bool, error) {
+	if req.CashierID == 0 {
+		req.CashierID = env.cashier.ID
+	}
+	if req.TerminalID == 0 {
+		req.TerminalID = env.terminal.ID
+	}
+	return env.transactions.Checkout(req, useLock)
+}
+
+var noTax = models.TaxConfig{Mode: models.TaxModeExclusive}
+
+var testReceiptConfig = models.ReceiptNumberConfig{Prefix: "INV", OutletCode: "OUTLET1", Digits: 4}
+
+func cash(amount int) []models.PaymentInput {
+	return []models.PaymentInput{{Method: models.PaymentMethodCash, Amount: amount}}
+}
+
+func (env *testEnv) stock(t *testing.T, id int) int {
+	t.Helper()
+	p, err := env.products.GetByID(id)
+	if err != nil {
+		t.Fatalf("GetByID(%d): %v", id, err)
+	}
+	return p.Stock
+}
+
+func TestCheckoutValidation(t *testing.T) {
+	env := newTestEnv(t, noTax)
+	gold := &models.Product{Name: "Emas 1 kg", Price: 600_000_000, Stock: 10}
+	halfGold := &models.Product{Name: "Emas 500 g", Price: 450_000_000, Stock: 10}
+	for _, p := range []*models.Product{gold, halfGold} {
+		if err := env.products.Create(p); err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		req    models.CheckoutRequest
+		fields []string
+	}{
+		{
+			name:   "keranjang kosong",
+			req:    models.CheckoutRequest{Payments: cash(5000)},
+			fields: []string{"items"},
+		},
+		{
+			name: "quantity nol dan negatif",
+			req: models.CheckoutRequest{
+				Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 0}, {ProductID: env.bread.ID, Quantity: -2}},
+				Payments: cash(5000),
+			},
+			fields: []string{"items[0].quantity", "items[1].quantity"},
+		},
+		{
+			name: "total quantity baris duplikat melebihi batas",
+			req: models.CheckoutRequest{
+				Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: MaxItemQuantity}, {ProductID: env.tea.ID, Quantity: 1}},
+				Payments: cash(5000),
+			},
+			fields: []string{"items[0].quantity"},
+		},
+		{
+			name: "total baris melebihi batas",
+			req: models.CheckoutRequest{
+				Items:    []models.CheckoutItem{{ProductID: gold.ID, Quantity: 2}},
+				Payments: cash(MaxPaymentAmount),
+			},
+			fields: []string{"items"},
+		},
+		{
+			name: "total belanja melebihi batas",
+			req: models.CheckoutRequest{
+				Items:    []models.CheckoutItem{{ProductID: gold.ID, Quantity: 1}, {ProductID: halfGold.ID, Quantity: 1}},
+				Payments: cash(MaxPaymentAmount),
+			},
+			fields: []string{"items"},
+		},
+		{
+			name:   "tanpa pembayaran",
+			req:    models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}}},
+			fields: []string{"payments"},
+		},
+		{
+			name: "metode pembayaran tidak dikenal",
+			req: models.CheckoutRequest{
+				Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}},
+				Payments: []models.PaymentInput{{Method: "cek", Amount: 5000}},
+			},
+			fields: []string{"payments[0].method"},
+		},
+		{
+			name: "nominal tender melebihi batas",
+			req: models.CheckoutRequest{
+				Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}},
+				Payments: []models.PaymentInput{{Method: models.PaymentMethodCash, Amount: 1 << 31}, {Method: models.PaymentMethodQRIS, Amount: MaxPaymentAmount + 1}},
+			},
+			fields: []string{"payments[0].amount", "payments[1].amount"},
+		},
+		{
+			name: "total tender melebihi batas",
+			req: models.CheckoutRequest{
+				Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}},
+				Payments: append(cash(MaxPaymentAmount), cash(1)...),
+			},
+			fields: []string{"payments"},
+		},
+		{
+			name: "pembayaran kurang",
+			req: models.CheckoutRequest{
+				Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 2}},
+				Payments: cash(5000),
+			},
+			fields: []string{"payments"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := env.checkout(tt.req, true)
+
+			var ve *models.ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("err = %v, want ValidationError", err)
+			}
+			if len(ve.Errors) != len(tt.fields) {
+				t.Fatalf("errors = %+v, want fields %v", ve.Errors, tt.fields)
+			}
+			for i, field := range tt.fields {
+				if ve.Errors[i].Field != field {
+					t.Errorf("errors[%d].Field = %q, want %q", i, ve.Errors[i].Field, field)
+				}
+			}
+		})
+	}
+
+	if got := env.stock(t, env.tea.ID); got != 10 {
+		t.Errorf("stock after rejected checkouts = %d, want 10", got)
+	}
+}
+
+func TestCheckoutCashierTerminal(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	inactive := &models.Terminal{Code: "KASIR-99", Name: "Rusak", Active: false}
+	if err := repositories.NewMemoryTerminalRepository(env.store).Create(inactive); err != nil {
+		t.Fatalf("create terminal: %v", err)
+	}
+
+	items := []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 1}}
+
+	tests := []struct {
+		name string
+		req  models.CheckoutRequest
+		want error
+	}{
+		{name: "tanpa kasir", req: models.CheckoutRequest{TerminalID: env.terminal.ID, Items: items, Payments: cash(5000)}, want: models.ErrUnauthorized},
+		{name: "tanpa terminal", req: models.CheckoutRequest{CashierID: env.cashier.ID, Items: items, Payments: cash(5000)}, want: models.ErrValidation},
+		{name: "terminal tidak ada", req: models.CheckoutRequest{CashierID: env.cashier.ID, TerminalID: 99, Items: items, Payments: cash(5000)}, want: models.ErrValidation},
+		{name: "terminal tidak aktif", req: models.CheckoutRequest{CashierID: env.cashier.ID, TerminalID: inactive.ID, Items: items, Payments: cash(5000)}, want: models.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := env.transactions.Checkout(tt.req, true); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	transaction, _, err := env.checkout(models.CheckoutRequest{Items: items, Payments: cash(5000)}, true)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	got, err := env.transactions.GetByID(transaction.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.CashierID == nil || *got.CashierID != env.cashier.ID || got.TerminalID == nil || *got.TerminalID != env.terminal.ID {
+		t.Errorf("cashier = %v, terminal = %v, want %d and %d", got.CashierID, got.TerminalID, env.cashier.ID, env.terminal.ID)
+	}
+	if got := env.stock(t, env.tea.ID); got != 9 {
+		t.Errorf("stock = %d, want 9", got)
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []models.CheckoutItem
+		payments []models.PaymentInput
+		total    int
+		change   int
+		stockErr []int // product_id yang kurang stok
+	}{
+		{
+			name:     "baris duplikat digabung",
+			items:    []models.CheckoutItem{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 1}, {ProductID: 1, Quantity: 2}},
+			payments: cash(30000),
+			total:    27000,
+			change:   3000,
+		},
+		{
+			name:     "split tender",
+			items:    []models.CheckoutItem{{ProductID: 2, Quantity: 2}},
+			payments: []models.PaymentInput{{Method: models.PaymentMethodQRIS, Amount: 20000}, {Method: models.PaymentMethodCash, Amount: 10000}},
+			total:    24000,
+			change:   6000,
+		},
+		{
+			name:     "stok kurang, semua produk yang kurang dilaporkan",
+			items:    []models.CheckoutItem{{ProductID: 1, Quantity: 11}, {ProductID: 2, Quantity: 4}},
+			payments: cash(200000),
+			stockErr: []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, noTax)
+
+			transaction, replayed, err := env.checkout(models.CheckoutRequest{Items: tt.items, Payments: tt.payments}, true)
+
+			if tt.stockErr != nil {
+				var se *models.InsufficientStockError
+				if !errors.As(err, &se) {
+					t.Fatalf("err = %v, want InsufficientStockError", err)
+				}
+				for i, id := range tt.stockErr {
+					if se.Items[i].ProductID != id {
+						t.Errorf("shortage[%d].ProductID = %d, want %d", i, se.Items[i].ProductID, id)
+					}
+				}
+				if env.stock(t, 1) != 10 || env.stock(t, 2) != 3 {
+					t.Error("stock changed after rejected checkout")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Checkout: %v", err)
+			}
+			if replayed {
+				t.Error("replayed = true for a new checkout")
+			}
+			if transaction.TotalAmount != tt.total {
+				t.Errorf("TotalAmount = %d, want %d", transaction.TotalAmount, tt.total)
+			}
+			if transaction.ChangeAmount != tt.change {
+				t.Errorf("ChangeAmount = %d, want %d", transaction.ChangeAmount, tt.change)
+			}
+
+			sold := make(map[int]int)
+			for _, item := range tt.items {
+				sold[item.ProductID] += item.Quantity
+			}
+			if len(transaction.Details) != len(sold) {
+				t.Errorf("details = %d lines, want %d", len(transaction.Details), len(sold))
+			}
+			if got := env.stock(t, 1); got != 10-sold[1] {
+				t.Errorf("tea stock = %d, want %d", got, 10-sold[1])
+			}
+			if got := env.stock(t, 2); got != 3-sold[2] {
+				t.Errorf("bread stock = %d, want %d", got, 3-sold[2])
+			}
+
+			stored, err := env.transactions.GetByID(transaction.ID)
+			if err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			if stored.TotalAmount != transaction.TotalAmount || len(stored.Payments) != len(tt.payments) {
+				t.Errorf("stored transaction = %+v", stored)
+			}
+		})
+	}
+}
+
+func TestCheckoutUnknownProduct(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	_, _, err := env.checkout(models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: 99, Quantity: 1}},
+		Payments: cash(5000),
+	}, true)
+	if err == nil {
+		t.Fatal("expected error for unknown product")
+	}
+}
+
+func TestCheckoutByBarcode(t *testing.T) {
+	env := newTestEnv(t, noTax)
+	env.tea.SKU = "TEH-01"
+	env.tea.Barcodes = []string{"8991234567891"}
+	if err := env.products.Update(env.tea); err != nil {
+		t.Fatalf("update product: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		items []models.CheckoutItem
+		want  error
+	}{
+		{name: "barcode tidak dikenal", items: []models.CheckoutItem{{Barcode: "4006381333931", Quantity: 1}}, want: models.ErrValidation},
+		{name: "sku tidak dikenal", items: []models.CheckoutItem{{SKU: "KOPI-01", Quantity: 1}}, want: models.ErrValidation},
+		{name: "product_id dan barcode", items: []models.CheckoutItem{{ProductID: env.tea.ID, Barcode: "8991234567891", Quantity: 1}}, want: models.ErrValidation},
+		{name: "barcode dan sku", items: []models.CheckoutItem{{Barcode: "8991234567891", SKU: "TEH-01", Quantity: 1}}, want: models.ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := env.checkout(models.CheckoutRequest{Items: tt.items, Payments: cash(5000)}, true); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// hasil scan, ketik SKU dan pilih manual produk yang sama jadi satu baris
+	transaction, _, err := env.checkout(models.CheckoutRequest{
+		Items: []models.CheckoutItem{
+			{Barcode: "8991234567891", Quantity: 1},
+			{ProductID: env.bread.ID, Quantity: 1},
+			{SKU: "teh-01", Quantity: 1},
+			{ProductID: env.tea.ID, Quantity: 1},
+		},
+		Payments: cash(27000),
+	}, true)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if len(transaction.Details) != 2 || transaction.Details[0].ProductID != env.tea.ID || transaction.Details[0].Quantity != 3 {
+		t.Errorf("details = %+v, want Es Teh x3 dan Roti x1", transaction.Details)
+	}
+	if transaction.TotalAmount != 27000 {
+		t.Errorf("total = %d, want 27000", transaction.TotalAmount)
+	}
+}
+
+// countingProductRepo - hitung query lookup barcode / sku yang dibuat service
+type countingProductRepo struct {
+	repositories.ProductRepository
+	lookups int
+}
+
+func (r *countingProductRepo) IDsByBarcode(barcodes []string) (map[string]int, error) {
+	r.lookups++
+	return r.ProductRepository.IDsByBarcode(barcodes)
+}
+
+func (r *countingProductRepo) IDsBySKU(skus []string) (map[string]int, error) {
+	r.lookups++
+	return r.ProductRepository.IDsBySKU(skus)
+}
+
+func TestCheckoutBarcodeLookups(t *testing.T) {
+	env := newTestEnv(t, noTax)
+	env.tea.Barcodes = []string{"8991234567891"}
+	if err := env.products.Update(env.tea); err != nil {
+		t.Fatalf("update product: %v", err)
+	}
+
+	products := &countingProductRepo{ProductRepository: repositories.NewMemoryProductRepository(env.store)}
+	transactions := NewTransactionService(repositories.NewMemoryTransactionRepository(env.store, noTax, testReceiptConfig), products)
+	checkout := func(items []models.CheckoutItem) error {
+		_, _, err := transactions.Checkout(models.CheckoutRequest{
+			Items: items, Payments: cash(100000), CashierID: env.cashier.ID, TerminalID: env.terminal.ID,
+		}, true)
+		return err
+	}
+
+	tooMany := make([]models.CheckoutItem, MaxCartLines+1)
+	for i := range tooMany {
+		tooMany[i] = models.CheckoutItem{Barcode: "8991234567891", Quantity: 1}
+	}
+	tests := []struct {
+		name  string
+		items []models.CheckoutItem
+	}{
+		{name: "melebihi batas baris", items: tooMany},
+		{name: "quantity melebihi batas", items: []models.CheckoutItem{{Barcode: "8991234567891", Quantity: MaxItemQuantity + 1}}},
+		{name: "quantity 0", items: []models.CheckoutItem{{Barcode: "8991234567891"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkout(tt.items); !errors.Is(err, models.ErrValidation) {
+				t.Fatalf("err = %v, want %v", err, models.ErrValidation)
+			}
+			if products.lookups != 0 {
+				t.Errorf("lookups = %d, want 0 (ditolak sebelum query)", products.lookups)
+			}
+		})
+	}
+
+	// banyak baris hasil scan cukup satu query barcode
+	items := make([]models.CheckoutItem, 5)
+	for i := range items {
+		items[i] = models.CheckoutItem{Barcode: "8991234567891", Quantity: 1}
+	}
+	if err := checkout(items); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if products.lookups != 1 {
+		t.Errorf("lookups = %d, want 1", products.lookups)
+	}
+}
+
+func TestCheckoutConcurrentLastUnit(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	const cashiers = 10
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+
+	for range cashiers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := env.checkout(models.CheckoutRequest{
+				Items:    []models.CheckoutItem{{ProductID: env.bread.ID, Quantity: 1}},
+				Payments: cash(12000),
+			}, false)
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 3 {
+		t.Errorf("succeeded = %d, want 3", succeeded)
+	}
+	if got := env.stock(t, env.bread.ID); got != 0 {
+		t.Errorf("stock = %d, want 0", got)
+	}
+}
+
+func TestCheckoutIdempotency(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	req := models.CheckoutRequest{
+		Items:          []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 2}},
+		Payments:       cash(10000),
+		IdempotencyKey: "tablet-1-0001",
+	}
+
+	first, replayed, err := env.checkout(req, true)
+	if err != nil || replayed {
+		t.Fatalf("first checkout: replayed=%v err=%v", replayed, err)
+	}
+
+	second, replayed, err := env.checkout(req, true)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if !replayed || second.ID != first.ID {
+		t.Errorf("replay = (id %d, replayed %v), want (id %d, true)", second.ID, replayed, first.ID)
+	}
+	if got := env.stock(t, env.tea.ID); got != 8 {
+		t.Errorf("stock = %d, want 8 (deducted once)", got)
+	}
+
+	req.Items[0].Quantity = 3
+	_, _, err = env.checkout(req, true)
+	var ce *models.ConflictError
+	if !errors.As(err, &ce) {
+		t.Errorf("different payload with same key: err = %v, want ConflictError", err)
+	}
+}
+
+func TestCheckoutPromotionAndTax(t *testing.T) {
+	env := newTestEnv(t, models.TaxConfig{Mode: models.TaxModeInclusive, DefaultRate: 11})
+
+	err := env.promotions.Create(&models.Promotion{
+		Name:       "Diskon Teh 10%",
+		Type:       models.PromotionTypePercentage,
+		Value:      10,
+		ProductIDs: []int{env.tea.ID},
+		Active:     true,
+	})
+	if err != nil {
+		t.Fatalf("create promotion: %v", err)
+	}
+
+	transaction, _, err := env.checkout(models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 2}},
+		Payments: cash(9000),
+	}, true)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	// 10000 - 10% = 9000 termasuk PPN 11%: DPP 8108, PPN 892
+	if transaction.DiscountAmount != 1000 || transaction.TotalAmount != 9000 {
+		t.Errorf("discount/total = %d/%d, want 1000/9000", transaction.DiscountAmount, transaction.TotalAmount)
+	}
+	if transaction.TaxBase != 8108 || transaction.TaxAmount != 892 {
+		t.Errorf("dpp/ppn = %d/%d, want 8108/892", transaction.TaxBase, transaction.TaxAmount)
+	}
+	if len(transaction.Promotions) != 1 || len(transaction.Details[0].Promotions) != 1 {
+		t.Errorf("promotions = %+v", transaction.Promotions)
+	}
+}
+
+func TestVoidAndRefund(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	checkout := func() *models.Transaction {
+		t.Helper()
+		transaction, _, err := env.checkout(models.CheckoutRequest{
+			Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: 3}},
+			Payments: cash(15000),
+		}, true)
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		return transaction
+	}
+
+	t.Run("void mengembalikan stok", func(t *testing.T) {
+		transaction := checkout()
+
+		if _, err := env.transactions.Void(transaction.ID, models.VoidRequest{}); err == nil {
+			t.Error("void without reason: expected error")
+		}
+
+		voided, err := env.transactions.Void(transaction.ID, models.VoidRequest{Reason: "salah input"})
+		if err != nil {
+			t.Fatalf("Void: %v", err)
+		}
+		if voided.Status != models.TransactionStatusVoided || voided.VoidedAt == nil {
+			t.Errorf("status = %q, voided_at = %v", voided.Status, voided.VoidedAt)
+		}
+		if got := env.stock(t, env.tea.ID); got != 10 {
+			t.Errorf("stock = %d, want 10", got)
+		}
+
+		var ce *models.ConflictError
+		if _, err := env.transactions.Void(transaction.ID, models.VoidRequest{Reason: "lagi"}); !errors.As(err, &ce) {
+			t.Errorf("second void: err = %v, want ConflictError", err)
+		}
+		if _, err := env.transactions.Refund(transaction.ID, models.RefundRequest{Reason: "x"}); !errors.As(err, &ce) {
+			t.Errorf("refund voided: err = %v, want ConflictError", err)
+		}
+	})
+
+	t.Run("refund sebagian lalu sisanya", func(t *testing.T) {
+		transaction := checkout()
+		detailID := transaction.Details[0].ID
+
+		refund, err := env.transactions.Refund(transaction.ID, models.RefundRequest{
+			Reason: "rusak",
+			Items:  []models.RefundItemRequest{{TransactionDetailID: detailID, Quantity: 1}},
+		})
+		if err != nil {
+			t.Fatalf("partial refund: %v", err)
+		}
+		if refund.TotalAmount != 5000 {
+			t.Errorf("refund total = %d, want 5000", refund.TotalAmount)
+		}
+
+		got, _ := env.transactions.GetByID(transaction.ID)
+		if got.Status != models.TransactionStatusPartiallyRefunded || got.Details[0].RefundedQuantity != 1 {
+			t.Errorf("status = %q, refunded qty = %d", got.Status, got.Details[0].RefundedQuantity)
+		}
+
+		var ce *models.ConflictError
+		if _, err := env.transactions.Void(transaction.ID, models.VoidRequest{Reason: "x"}); !errors.As(err, &ce) {
+			t.Errorf("void refunded: err = %v, want ConflictError", err)
+		}
+
+		if _, err := env.transactions.Refund(transaction.ID, models.RefundRequest{Reason: "rusak"}); err != nil {
+			t.Fatalf("full refund: %v", err)
+		}
+
+		got, _ = env.transactions.GetByID(transaction.ID)
+		if got.Status != models.TransactionStatusRefunded || got.RefundedAmount != 15000 || len(got.Refunds) != 2 {
+			t.Errorf("status = %q, refunded = %d, refunds = %d", got.Status, got.RefundedAmount, len(got.Refunds))
+		}
+		if got := env.stock(t, env.tea.ID); got != 10 {
+			t.Errorf("stock = %d, want 10", got)
+		}
+
+		if _, err := env.transactions.Refund(transaction.ID, models.RefundRequest{Reason: "lagi"}); !errors.As(err, &ce) {
+			t.Errorf("refund fully refunded: err = %v, want ConflictError", err)
+		}
+	})
+
+	t.Run("void hanya untuk transaksi hari ini", func(t *testing.T) {
+		transaction := checkout()
+
+		tomorrow := env.store.Now().AddDate(0, 0, 1)
+		env.store.Now = func() time.Time { return tomorrow }
+
+		var ce *models.ConflictError
+		if _, err := env.transactions.Void(transaction.ID, models.VoidRequest{Reason: "x"}); !errors.As(err, &ce) {
+			t.Errorf("void yesterday: err = %v, want ConflictError", err)
+		}
+	})
+
+	t.Run("transaksi tidak ada", func(t *testing.T) {
+		var nf *models.NotFoundError
+		if _, err := env.transactions.GetByID(999); !errors.As(err, &nf) {
+			t.Errorf("err = %v, want NotFoundError", err)
+		}
+	})
+}
+
+func TestTransactionList(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	for _, qty := range []int{1, 3, 2} {
+		_, _, err := env.checkout(models.CheckoutRequest{
+			Items:    []models.CheckoutItem{{ProductID: env.tea.ID, Quantity: qty}},
+			Payments: cash(qty * 5000),
+		}, true)
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+	}
+	// roti dijual di terminal kedua
+	second := &models.Terminal{Code: "KASIR-02", Name: "Kasir belakang", Active: true}
+	if err := repositories.NewMemoryTerminalRepository(env.store).Create(second); err != nil {
+		t.Fatalf("create terminal: %v", err)
+	}
+	if _, err := env.shifts.Open(models.OpenShiftRequest{TerminalID: second.ID, CashierID: env.cashier.ID}); err != nil {
+		t.Fatalf("open shift: %v", err)
+	}
+	_, _, err := env.checkout(models.CheckoutRequest{
+		TerminalID: second.ID,
+		Items:      []models.CheckoutItem{{ProductID: env.bread.ID, Quantity: 1}},
+		Payments:   cash(12000),
+	}, true)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	minAmount := 10000
+	breadID := env.bread.ID
+	firstTerminal := env.terminal.ID
+	otherCashier := env.cashier.ID + 1
+
+	tests := []struct {
+		name   string
+		filter models.TransactionFilter
+		ids    []int
+		total  int
+	}{
+		{name: "default urut created_at lalu id", filter: models.TransactionFilter{}, ids: []int{1, 2, 3, 4}, total: 4},
+		{name: "total terbesar dulu", filter: models.TransactionFilter{SortBy: "total_amount", SortDesc: true}, ids: []int{2, 4, 3, 1}, total: 4},
+		{name: "minimal nominal", filter: models.TransactionFilter{MinAmount: &minAmount, SortBy: "id"}, ids: []int{2, 3, 4}, total: 3},
+		{name: "berdasarkan produk", filter: models.TransactionFilter{ProductID: &breadID}, ids: []int{4}, total: 1},
+		{name: "berdasarkan terminal", filter: models.TransactionFilter{TerminalID: &firstTerminal}, ids: []int{1, 2, 3}, total: 3},
+		{name: "berdasarkan terminal kedua", filter: models.TransactionFilter{TerminalID: &second.ID}, ids: []int{4}, total: 1},
+		{name: "kasir lain", filter: models.TransactionFilter{CashierID: &otherCashier}, ids: []int{}, total: 0},
+		{name: "paginasi", filter: models.TransactionFilter{SortBy: "id", Page: 2, Limit: 3}, ids: []int{4}, total: 4},
+		{name: "nomor struk lengkap", filter: models.TransactionFilter{ReceiptNumber: "INV/OUTLET1/20261018/0002"}, ids: []int{2}, total: 1},
+		{name: "nomor struk sebagian", filter: models.TransactionFilter{ReceiptNumber: "outlet1/20261018/000", SortBy: "id"}, ids: []int{1, 2, 3, 4}, total: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := env.transactions.GetAll(tt.filter)
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			if list.Total != tt.total {
+				t.Errorf("Total = %d, want %d", list.Total, tt.total)
+			}
+			if len(list.Data) != len(tt.ids) {
+				t.Fatalf("got %d rows, want %d", len(list.Data), len(tt.ids))
+			}
+			for i, id := range tt.ids {
+				if list.Data[i].ID != id {
+					t.Errorf("Data[%d].ID = %d, want %d", i, list.Data[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestReceiptNumbers(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	checkout := func(qty int) (*models.Transaction, error) {
+		transaction, _, err := env.checkout(models.CheckoutRequest{
+			Items:    []models.CheckoutItem{{ProductID: env.bread.ID, Quantity: qty}},
+			Payments: cash(qty * 12000),
+		}, true)
+		return transaction, err
+	}
+
+	first, err := checkout(1)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	// stok roti tinggal 2, checkout gagal tidak boleh memakai nomor
+	if _, err := checkout(5); !errors.Is(err, models.ErrInsufficientStock) {
+		t.Fatalf("Checkout err = %v, want insufficient stock", err)
+	}
+	second, err := checkout(1)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	// hari berikutnya mulai dari 0001 lagi
+	tomorrow := env.store.Now().AddDate(0, 0, 1)
+	env.store.Now = func() time.Time { return tomorrow }
+	next, err := checkout(1)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	for _, tc := range []struct {
+		transaction *models.Transaction
+		want        string
+	}{
+		{first, "INV/OUTLET1/20261018/0001"},
+		{second, "INV/OUTLET1/20261018/0002"},
+		{next, "INV/OUTLET1/20261019/0001"},
+	} {
+		if tc.transaction.ReceiptNumber != tc.want {
+			t.Errorf("transaksi %d: receipt_number = %q, want %q", tc.transaction.ID, tc.transaction.ReceiptNumber, tc.want)
+		}
+		stored, err := env.transactions.GetByID(tc.transaction.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if stored.ReceiptNumber != tc.want {
+			t.Errorf("GetByID(%d).ReceiptNumber = %q, want %q", tc.transaction.ID, stored.ReceiptNumber, tc.want)
+		}
+	}
+}
+
+func TestCheckoutRecipe(t *testing.T) {
+	env := newTestEnv(t, noTax)
+
+	// stok komponen dalam satuan gram / ml / pcs
+	coffee := &models.Product{Name: "Biji Kopi", Stock: 200}
+	milk := &models.Product{Name: "Susu", Stock: 1000}
+	cup := &models.Product{Name: "Cup", Price: 1000, Stock: 10}
+	for _, p := range []*models.Product{coffee, milk, cup} {
+		if err := env.products.Create(p); err != nil {
+			t.Fatalf("create component: %v", err)
+		}
+	}
+	latte := &models.Product{Name: "Es Kopi Susu", Price: 20000, Stock: 50, Recipe: []models.RecipeItem{
+		{ComponentID: coffee.ID, Quantity: 18},
+		{ComponentID: milk.ID, Quantity: 150},
+		{ComponentID: cup.ID, Quantity: 1},
+	}}
+	if err := env.products.Create(latte); err != nil {
+		t.Fatalf("create composite: %v", err)
+	}
+
+	// 2 latte + 1 cup dijual terpisah: kopi 36, susu 300, cup 3
+	transaction, _, err := env.checkout(models.CheckoutRequest{
+		Items:    []models.CheckoutItem{{ProductID: latte.ID, Quantity: 2}, {ProductID: cup.ID, Quantity: 1}},
+		Payments: cash(41000),
+	}, true)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	want := map[int]int{coffee.ID: 164, milk.ID: 700, cup.ID: 7, latte.ID: 0}
+	for id, stock := range want {
+		if got := env.stock(t, id); got != stock {
+			t.Errorf("stock product %d = %d, want %d", id, got, stock)
+		}
+	}
+	if len(transaction.Details[0].Components) != 3 || transaction.Details[0].Components[1].ComponentName != "Susu" {
+		t.Errorf("components = %+v, want snapshot resep", transaction.Details[0].Components)
+	}
+
+	// susu tersisa 700 ml, 5 latte butuh 750 ml
+	_, _, err = env.checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: latte.ID, Quantity: 5}}, Payments: cash(100000)}, true)
+	var se *models.InsufficientStockError
+	if !errors.As(err, &se) || len(se.Items) != 1 || se.Items[0].ProductID != milk.ID || se.Items[0].Requested != 750 {
+		t.Fatalf("err = %v, want susu kurang 750", err)
+	}
+
+	// resep diubah setelah transaksi, refund tetap mengembalikan komponen sesuai snapshot
+	latte.Recipe = []models.RecipeItem{{ComponentID: coffee.ID, Quantity: 20}, {ComponentID: cup.ID, Quantity: 1}}
+	if err := env.products.Update(latte); err != nil {
+		t.Fatalf("update recipe: %v", err)
+	}
+	_, err = env.transactions.Refund(transaction.ID, models.RefundRequest{
+		Reason: "salah pesanan",
+		Items:  []models.RefundItemRequest{{TransactionDetailID: transaction.Details[0].ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if env.stock(t, coffee.ID) != 182 || env.stock(t, milk.ID) != 850 || env.stock(t, cup.ID) != 8 {
+		t.Errorf("stock after refund = %d, %d, %d, want 182, 850, 8", env.stock(t, coffee.ID), env.stock(t, milk.ID), env.stock(t, cup.ID))
+	}
+
+	other, _, err := env.checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: latte.ID, Quantity: 1}}, Payments: cash(20000)}, true)
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if env.stock(t, coffee.ID) != 162 || env.stock(t, milk.ID) != 850 {
+		t.Errorf("stock with new recipe = %d, %d, want 162, 850", env.stock(t, coffee.ID), env.stock(t, milk.ID))
+	}
+	if _, err := env.transactions.Void(other.ID, models.VoidRequest{Reason: "batal"}); err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+	if env.stock(t, coffee.ID) != 182 || env.stock(t, cup.ID) != 8 {
+		t.Errorf("stock after void = %d, %d, want 182, 8", env.stock(t, coffee.ID), env.stock(t, cup.ID))
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"aplikasi-kasir/models"
+	"aplikasi-kasir/repositories"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72 // batas input bcrypt
+)
+
+// bcryptCost - diturunkan di test supaya cepat
+var bcryptCost = bcrypt.DefaultCost
+
+var (
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{3,50}$`)
+	pinPattern      = regexp.MustCompile(`^[0-9]{4,6}$`)
+)
+
+type UserService struct {
+	repo     repositories.UserRepository
+	sessions repositories.SessionRepository
+}
+
+func NewUserService(repo repositories.UserRepository, sessions repositories.SessionRepository) *UserService {
+	return &UserService{repo: repo, sessions: sessions}
+}
+
+func (s *UserService) GetAll() ([]models.User, error) {
+	return s.repo.GetAll()
+}
+
+func (s *UserService) GetByID(id int) (*models.User, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *UserService) Create(input models.UserInput) (*models.User, error) {
+	user := &models.User{Active: true, Permissions: []string{}}
+	if err := applyUserInput(user, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Update - password / PIN kosong tidak diubah. Menonaktifkan user, mengganti role atau
+// mengganti password/PIN mencabut semua sesi login user tersebut.
+func (s *UserService) Update(id int, input models.UserInput) (*models.User, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	wasActive, oldRole := user.Active, user.Role
+	if err := applyUserInput(user, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(user); err != nil {
+		return nil, err
+	}
+
+	// sesi lama dicabut supaya perubahan akses langsung berlaku
+	if (wasActive && !user.Active) || user.Role != oldRole || input.Password != "" || input.PIN != "" {
+		if err := s.sessions.RevokeAllForUser(user.ID, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// EnsureOwner - buat akun owner pertama jika tabel users masih kosong.
+// Mengembalikan true jika akun baru dibuat.
+func (s *UserService) EnsureOwner(username, password string) (bool, error) {
+	count, err := s.repo.Count()
+	if err != nil || count > 0 {
+		return false, err
+	}
+
+	_, err = s.Create(models.UserInput{Username: username, Name: username, Role: models.RoleOwner, Password: password})
+	return err == nil, err
+}
+
+// applyUserInput - validasi input lalu salin ke user (hash password/PIN jika diisi)
+func applyUserInput(user *models.User, input models.UserInput) error {
+	var errs []models.FieldError
+
+	input.Username = strings.TrimSpace(input.Username)
+	input.Name = strings.TrimSpace(input.Name)
+	input.Role = strings.ToLower(strings.TrimSpace(input.Role))
+
+	if !usernamePattern.MatchString(input.Username) {
+		errs = append(errs, models.FieldError{Field: "username", Message: "3-50 karakter huruf, angka, titik, garis bawah atau strip"})
+	}
+	if input.Name == "" {
+		errs = append(errs, models.FieldError{Field: "name", Message: "nama wajib diisi"})
+	}
+	// role yang tidak ada ditolak repository (foreign key ke tabel roles)
+	if input.Role == "" {
+		errs = append(errs, models.FieldError{Field: "role", Message: "role wajib diisi"})
+	}
+	for _, p := range input.Permissions {
+		if !models.IsValidPermission(p) {
+			errs = append(errs, models.FieldError{Field: "permissions", Message: "izin tidak dikenal: " + p})
+		}
+	}
+	if input.Password != "" && (len(input.Password) < MinPasswordLength || len(input.Password) > MaxPasswordLength) {
+		errs = append(errs, models.FieldError{Field: "password", Message: "password 8-72 karakter"})
+	}
+	if input.PIN != "" && !pinPattern.MatchString(input.PIN) {
+		errs = append(errs, models.FieldError{Field: "pin", Message: "PIN harus 4-6 digit angka"})
+	}
+
+	// kasir boleh login dengan PIN saja, role lain wajib punya password
+	hasPassword := input.Password != "" || user.PasswordHash != ""
+	hasPIN := input.PIN != "" || user.PINHash != ""
+	if input.Role != models.RoleCashier && !hasPassword {
+		errs = append(errs, models.FieldError{Field: "password", Message: "password wajib untuk role " + input.Role})
+	} else if !hasPassword && !hasPIN {
+		errs = append(errs, models.FieldError{Field: "password", Message: "isi password atau PIN"})
+	}
+
+	if len(errs) > 0 {
+		return &models.ValidationError{Errors: errs}
+	}
+
+	user.Username = input.Username
+	user.Name = input.Name
+	user.Role = input.Role
+	if input.Active != nil {
+		user.Active = *input.Active
+	}
+	if input.Permissions != nil {
+		user.Permissions = normalizePermissions(input.Permissions)
+	}
+
+	if input.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcryptCost)
+		if err != nil {
+			return err
+		}
+		user.PasswordHash = string(hash)
+	}
+	if input.PIN != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(input.PIN), bcryptCost)
+		if err != nil {
+			return err
+		}
+		user.PINHash = string(hash)
+	}
+
+	return nil
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, models.ErrNotFound)
+}